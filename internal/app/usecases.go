@@ -0,0 +1,60 @@
+// Package app assembles the use cases from the configuration, so the daemon
+// and CLI commands running without one decorate them the same way
+package app
+
+import (
+	"fmt"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/infrastructure/audit"
+	"github.com/gqadonis/colima-manager/internal/infrastructure/colima"
+	"github.com/gqadonis/colima-manager/internal/infrastructure/kubeconfig"
+	"github.com/gqadonis/colima-manager/internal/pkg/metrics"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+)
+
+// UseCases are the use cases built from one configuration
+type UseCases struct {
+	Repo *colima.ColimaRepository
+	// Colima is the decorated use case: kubeconfig pruning when
+	// kubeconfig.prune_on_clean is set, then auditing when audit.enabled is
+	// set, then metrics
+	Colima     usecase.ColimaUseCaseInterface
+	KubeConfig *usecase.KubeConfigUseCase
+	// KubeConfigFile is the shared kubeconfig merged into and pruned from
+	KubeConfigFile *kubeconfig.File
+	// Audit is nil when auditing is disabled
+	Audit      *usecase.AuditedUseCase
+	AuditStore *audit.FileStore
+}
+
+// NewUseCases builds the use cases of cfg. Lifecycle events go to events,
+// which may be nil, and metrics to registry.
+func NewUseCases(cfg *config.Config, events domain.EventPublisher, registry *metrics.Registry) (*UseCases, error) {
+	repo, err := colima.NewColimaRepository(colima.TimeoutsFromConfig(cfg.Timeouts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	u := &UseCases{Repo: repo}
+
+	u.KubeConfigFile, err = kubeconfig.NewFileFromConfig(cfg.KubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the kubeconfig to merge into: %w", err)
+	}
+	u.KubeConfig = usecase.NewKubeConfigUseCase(repo, u.KubeConfigFile)
+
+	var colimaUseCase usecase.ColimaUseCaseInterface = usecase.NewColimaUseCase(repo, cfg, events)
+	if cfg.KubeConfig.PruneOnClean {
+		colimaUseCase = usecase.NewKubeConfigPruningUseCase(colimaUseCase, u.KubeConfig)
+	}
+	if cfg.Audit.Enabled {
+		if u.AuditStore, err = audit.NewFileStore(cfg.Audit.Path); err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		u.Audit = usecase.NewAuditedUseCase(colimaUseCase, u.AuditStore)
+		colimaUseCase = u.Audit
+	}
+	u.Colima = usecase.NewInstrumentedUseCase(colimaUseCase, cfg, registry)
+	return u, nil
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/pkg/metrics"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+)
+
+func TestNewUseCases(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Audit:      config.AuditConfig{Enabled: true, Path: filepath.Join(dir, "audit.jsonl")},
+		KubeConfig: config.KubeConfigConfig{MergePath: filepath.Join(dir, "kubeconfig"), PruneOnClean: true},
+	}
+
+	useCases, err := NewUseCases(cfg, nil, metrics.NewRegistry())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := useCases.Colima.(*usecase.InstrumentedUseCase); !ok {
+		t.Errorf("Expected the metrics decorator outermost, got %T", useCases.Colima)
+	}
+	if useCases.Audit == nil || useCases.AuditStore.Path() != cfg.Audit.Path {
+		t.Errorf("Expected auditing to %s, got %+v", cfg.Audit.Path, useCases.AuditStore)
+	}
+	if useCases.KubeConfigFile.Path() != cfg.KubeConfig.MergePath {
+		t.Errorf("Expected kubeconfig %s, got %s", cfg.KubeConfig.MergePath, useCases.KubeConfigFile.Path())
+	}
+
+	cfg.Audit.Enabled = false
+	if useCases, err = NewUseCases(cfg, nil, metrics.NewRegistry()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if useCases.Audit != nil || useCases.AuditStore != nil {
+		t.Error("Expected no audit log when auditing is disabled")
+	}
+}
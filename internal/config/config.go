@@ -2,63 +2,213 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/pkg/api"
 	"gopkg.in/yaml.v2"
 )
 
-type ProfileConfig struct {
-	CPUs           int    `yaml:"cpus"`
-	Memory         int    `yaml:"memory"`
-	DiskSize       int    `yaml:"disk_size"`
-	VMType         string `yaml:"vm_type"`
-	Runtime        string `yaml:"runtime"`
-	NetworkAddress bool   `yaml:"network_address"`
-	Kubernetes     bool   `yaml:"kubernetes"`
-}
+const (
+	// DefaultPIDFile is used when server.pid_file is not configured
+	DefaultPIDFile = "/tmp/colima-manager.pid"
+	// DefaultInterruptedFile is used when server.interrupted_file is not configured
+	DefaultInterruptedFile = "/tmp/colima-manager-interrupted.json"
+	// DefaultDaemonLogFile receives the logs of a detached daemon whose log
+	// output is a standard stream, since those point to /dev/null
+	DefaultDaemonLogFile = "/tmp/colima-manager.log"
+	// DefaultAuditFile is used when audit.path is not configured
+	DefaultAuditFile = "/tmp/colima-manager-audit.jsonl"
+)
+
+// ProfileConfig declares a profile
+type ProfileConfig = api.ProfileConfig
 
 type AutoConfig struct {
 	Enabled bool   `yaml:"enabled"`
 	Default string `yaml:"default"`
 }
 
+// TimeoutConfig holds per-operation command timeouts; zero values fall back
+// to the repository defaults
+type TimeoutConfig struct {
+	Start        time.Duration `yaml:"start"`
+	Stop         time.Duration `yaml:"stop"`
+	Status       time.Duration `yaml:"status"`
+	Clean        time.Duration `yaml:"clean"`
+	Dependencies time.Duration `yaml:"dependencies"`
+	Command      time.Duration `yaml:"command"`
+	// KubernetesHealth bounds one readiness check of a profile's API server
+	KubernetesHealth time.Duration `yaml:"kubernetes_health"`
+}
+
+// ReconcileConfig controls the declarative profile reconciler
+type ReconcileConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Interval   time.Duration `yaml:"interval"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+}
+
+// AuthToken grants a named caller a set of scopes. Either Token (plain text)
+// or SHA256 (hex digest of the token) must be set.
+type AuthToken struct {
+	Name   string   `yaml:"name"`
+	Token  string   `yaml:"token"`
+	SHA256 string   `yaml:"sha256"`
+	Scopes []string `yaml:"scopes"`
+}
+
+// AuthConfig configures bearer token authentication for the HTTP API
+type AuthConfig struct {
+	Enabled   bool        `yaml:"enabled"`
+	Tokens    []AuthToken `yaml:"tokens"`
+	TokenFile string      `yaml:"token_file"`
+	// TrustUnixSocket skips token checks for requests on the unix socket,
+	// leaving access control to the socket's file permissions
+	TrustUnixSocket bool `yaml:"trust_unix_socket"`
+}
+
+// AuditConfig controls the audit log of mutating operations
+type AuditConfig struct {
+	Enabled bool   `yaml:"enabled"` // default true
+	Path    string `yaml:"path"`
+}
+
+// DockerConfig controls the Docker contexts kept for profiles
+type DockerConfig struct {
+	// ManageContexts creates or refreshes the colima-<profile> context when
+	// a profile starts (default true)
+	ManageContexts bool `yaml:"manage_contexts"`
+	// UseContext also makes that context the docker CLI's default
+	UseContext bool `yaml:"use_context"`
+}
+
+// KubeConfigConfig controls merging profile kubeconfigs into a shared
+// kubeconfig file
+type KubeConfigConfig struct {
+	// MergePath is the file merged into (default ~/.kube/config)
+	MergePath string `yaml:"merge_path"`
+	// PruneOnClean removes a profile's merged entries when it is cleaned
+	PruneOnClean bool `yaml:"prune_on_clean"`
+}
+
+// LogConfig controls the level, format and destination of the manager's log
+// output. The rotation settings apply when Output is a file path.
+type LogConfig struct {
+	Level  string `yaml:"level"`  // debug, info (default), warn or error
+	Format string `yaml:"format"` // text (default) or json
+	// Output is stdout (default), stderr, journald, syslog or a file path
+	Output         string        `yaml:"output"`
+	MaxSizeMB      int           `yaml:"max_size_mb"`
+	RotateInterval time.Duration `yaml:"rotate_interval"`
+	MaxBackups     int           `yaml:"max_backups"`
+	MaxAge         time.Duration `yaml:"max_age"`
+}
+
+// ListenAddresses accepts either a single address or a list in YAML
+type ListenAddresses []string
+
+func (l *ListenAddresses) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*l = ListenAddresses{single}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
 type Config struct {
+	// path is the config file LoadConfig read, or would have read
+	path string
+	// host is the capacity profiles are validated against; zero when the
+	// config was not loaded from a file
+	host domain.HostCapacity
+	// profilesMu guards Profiles once the config is shared; use Profile,
+	// DeclaredProfiles and EditProfiles after loading
+	profilesMu sync.RWMutex
+
 	Server struct {
 		Port   int        `yaml:"port"`
 		Host   string     `yaml:"host"`
 		Daemon bool       `yaml:"daemon"`
 		Auto   AutoConfig `yaml:"auto"`
+		// Listen holds unix:///path or tcp://host:port addresses; when empty
+		// the server listens on host:port only
+		Listen      ListenAddresses `yaml:"listen"`
+		SocketMode  string          `yaml:"socket_mode"` // octal, e.g. "0660"
+		SocketGroup string          `yaml:"socket_group"`
+		// PIDFile is locked by the running daemon; it keeps a second one
+		// from starting and tells `stop --daemon` whom to signal
+		PIDFile string `yaml:"pid_file"`
+		// ShutdownPolicy is leave-running (default), stop-managed or
+		// stop-all and decides which profiles are stopped on shutdown
+		ShutdownPolicy string `yaml:"shutdown_policy"`
+		// ShutdownGracePeriod is how long a shutdown waits for running
+		// operations before canceling them
+		ShutdownGracePeriod time.Duration `yaml:"shutdown_grace_period"`
+		// InterruptedFile records the operations a shutdown canceled so the
+		// next start can report them
+		InterruptedFile string `yaml:"interrupted_file"`
 	} `yaml:"server"`
 	Profiles map[string]ProfileConfig `yaml:"profiles"`
+	// Defaults fill the settings a profile and the start request leave
+	// empty; desired_state is ignored here
+	Defaults   ProfileConfig    `yaml:"defaults"`
+	Timeouts   TimeoutConfig    `yaml:"timeouts"`
+	Reconcile  ReconcileConfig  `yaml:"reconcile"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Audit      AuditConfig      `yaml:"audit"`
+	Log        LogConfig        `yaml:"log"`
+	Docker     DockerConfig     `yaml:"docker"`
+	KubeConfig KubeConfigConfig `yaml:"kubeconfig"`
+}
+
+// Flags holds command line overrides for the config file
+type Flags struct {
+	ConfigPath string
+	Daemon     bool
+	Host       string
+	Auto       bool
+	PIDFile    string
 }
 
-func LoadConfig() (*Config, error) {
+// Register defines the config flags with both short and long forms on fs
+func (f *Flags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&f.ConfigPath, "c", "", "Path to config file")
+	fs.StringVar(&f.ConfigPath, "config", "", "Path to config file")
+	fs.BoolVar(&f.Daemon, "d", false, "Run in daemon mode")
+	fs.BoolVar(&f.Daemon, "daemon", false, "Run in daemon mode")
+	fs.StringVar(&f.Host, "h", "", "Server host address")
+	fs.StringVar(&f.Host, "host", "", "Server host address")
+	fs.BoolVar(&f.Auto, "a", false, "Automatically create and start default profile")
+	fs.BoolVar(&f.Auto, "auto", false, "Automatically create and start default profile")
+	fs.StringVar(&f.PIDFile, "pid-file", "", "Path to the daemon PID file")
+}
+
+// LoadConfig reads the config file and applies the overrides in flags
+func LoadConfig(flags Flags) (*Config, error) {
 	config := &Config{}
 	config.Server.Port = 8080        // Default port
 	config.Server.Host = "localhost" // Default host
+	config.Server.PIDFile = DefaultPIDFile
+	config.Server.InterruptedFile = DefaultInterruptedFile
+	config.Audit.Enabled = true
+	config.Audit.Path = DefaultAuditFile
+	config.Log.Level = "info"
+	config.Log.Format = "text"
+	config.Log.Output = "stdout"
+	config.Docker.ManageContexts = true
 
-	// Define command line flags
-	var (
-		configPath string
-		daemon     bool
-		host       string
-		auto       bool
-	)
-
-	// Define flags with both short and long forms
-	flag.StringVar(&configPath, "c", "", "Path to config file")
-	flag.StringVar(&configPath, "config", "", "Path to config file")
-	flag.BoolVar(&daemon, "d", false, "Run in daemon mode")
-	flag.BoolVar(&daemon, "daemon", false, "Run in daemon mode")
-	flag.StringVar(&host, "h", "", "Server host address")
-	flag.StringVar(&host, "host", "", "Server host address")
-	flag.BoolVar(&auto, "a", false, "Automatically create and start default profile")
-	flag.BoolVar(&auto, "auto", false, "Automatically create and start default profile")
-
-	// Parse flags if they haven't been parsed yet
-	if !flag.Parsed() {
-		flag.Parse()
-	}
+	configPath, daemon, host, auto := flags.ConfigPath, flags.Daemon, flags.Host, flags.Auto
 
 	// Determine config file path with following precedence:
 	// 1. Command line flags (-c or --config)
@@ -72,6 +222,12 @@ func LoadConfig() (*Config, error) {
 	} else {
 		configFile = "config.yaml"
 	}
+	// Keep an absolute path so profile edits land in the same file even if
+	// the working directory changes
+	if abs, err := filepath.Abs(configFile); err == nil {
+		configFile = abs
+	}
+	config.path = configFile
 
 	// Try to load from config file
 	data, err := os.ReadFile(configFile)
@@ -90,27 +246,24 @@ func LoadConfig() (*Config, error) {
 		config.Server.Host = host
 	}
 
+	if flags.PIDFile != "" {
+		config.Server.PIDFile = flags.PIDFile
+	}
+	if config.Server.PIDFile == "" {
+		config.Server.PIDFile = DefaultPIDFile
+	}
+
 	if auto {
 		config.Server.Auto.Enabled = true
-		// If no default profile is set, create one with sensible defaults
 		if config.Server.Auto.Default == "" {
 			config.Server.Auto.Default = "default"
-			if config.Profiles == nil {
-				config.Profiles = make(map[string]ProfileConfig)
-			}
-			if _, exists := config.Profiles[config.Server.Auto.Default]; !exists {
-				config.Profiles[config.Server.Auto.Default] = ProfileConfig{
-					CPUs:           4,
-					Memory:         8,
-					DiskSize:       60,
-					VMType:         "vz",
-					Runtime:        "containerd",
-					NetworkAddress: true,
-					Kubernetes:     true,
-				}
-			}
 		}
 	}
 
+	config.host = DetectHost()
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configFile, err)
+	}
+
 	return config, nil
 }
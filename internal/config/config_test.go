@@ -3,15 +3,34 @@ package config
 import (
 	"flag"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"gopkg.in/yaml.v2"
 )
 
+// testHost is the capacity profiles are validated against in these tests
+var testHost = domain.HostCapacity{CPUs: 16, Memory: 64}
+
 func TestMain(m *testing.M) {
-	// Parse flags before running tests to handle test flags
-	flag.Parse()
+	DetectHost = func() domain.HostCapacity { return testHost }
 	os.Exit(m.Run())
 }
 
+// loadConfigArgs parses args the way the CLI does and loads the config
+func loadConfigArgs(args ...string) (*Config, *flag.FlagSet, error) {
+	var flags Flags
+	fs := flag.NewFlagSet("cmd", flag.ContinueOnError)
+	flags.Register(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	config, err := LoadConfig(flags)
+	return config, fs, err
+}
+
 func createTestConfig(t *testing.T) (string, func()) {
 	content := []byte(`
 server:
@@ -75,8 +94,8 @@ func verifyConfig(t *testing.T, config *Config) {
 		{"DiskSize", profile.DiskSize, 60},
 		{"VMType", profile.VMType, "vz"},
 		{"Runtime", profile.Runtime, "containerd"},
-		{"NetworkAddress", profile.NetworkAddress, true},
-		{"Kubernetes", profile.Kubernetes, true},
+		{"NetworkAddress", domain.Enabled(profile.NetworkAddress), true},
+		{"Kubernetes", domain.Enabled(profile.Kubernetes), true},
 	}
 
 	for _, tt := range tests {
@@ -93,76 +112,74 @@ func TestFlagPatterns(t *testing.T) {
 		name     string
 		args     []string
 		envVar   string
-		checkFn  func(*Config) bool
+		checkFn  func(*flag.FlagSet, *Config) bool
 		expected bool
 	}{
 		{
 			name: "Short config flag",
 			args: []string{"-c", "custom.yaml"},
-			checkFn: func(c *Config) bool {
-				return flag.Lookup("c") != nil && flag.Lookup("config") != nil
+			checkFn: func(fs *flag.FlagSet, c *Config) bool {
+				return fs.Lookup("c") != nil && fs.Lookup("config") != nil
 			},
 			expected: true,
 		},
 		{
 			name: "Long config flag",
 			args: []string{"--config", "custom.yaml"},
-			checkFn: func(c *Config) bool {
-				return flag.Lookup("c") != nil && flag.Lookup("config") != nil
+			checkFn: func(fs *flag.FlagSet, c *Config) bool {
+				return fs.Lookup("c") != nil && fs.Lookup("config") != nil
 			},
 			expected: true,
 		},
 		{
 			name: "Short daemon flag",
 			args: []string{"-d"},
-			checkFn: func(c *Config) bool {
-				return flag.Lookup("d") != nil && flag.Lookup("daemon") != nil && c.Server.Daemon
+			checkFn: func(fs *flag.FlagSet, c *Config) bool {
+				return fs.Lookup("d") != nil && fs.Lookup("daemon") != nil && c.Server.Daemon
 			},
 			expected: true,
 		},
 		{
 			name: "Long daemon flag",
 			args: []string{"--daemon"},
-			checkFn: func(c *Config) bool {
-				return flag.Lookup("d") != nil && flag.Lookup("daemon") != nil && c.Server.Daemon
+			checkFn: func(fs *flag.FlagSet, c *Config) bool {
+				return fs.Lookup("d") != nil && fs.Lookup("daemon") != nil && c.Server.Daemon
 			},
 			expected: true,
 		},
 		{
 			name: "Short host flag",
 			args: []string{"-h", "localhost"},
-			checkFn: func(c *Config) bool {
-				return flag.Lookup("h") != nil && flag.Lookup("host") != nil && c.Server.Host == "localhost"
+			checkFn: func(fs *flag.FlagSet, c *Config) bool {
+				return fs.Lookup("h") != nil && fs.Lookup("host") != nil && c.Server.Host == "localhost"
 			},
 			expected: true,
 		},
 		{
 			name: "Long host flag",
 			args: []string{"--host", "localhost"},
-			checkFn: func(c *Config) bool {
-				return flag.Lookup("h") != nil && flag.Lookup("host") != nil && c.Server.Host == "localhost"
+			checkFn: func(fs *flag.FlagSet, c *Config) bool {
+				return fs.Lookup("h") != nil && fs.Lookup("host") != nil && c.Server.Host == "localhost"
 			},
 			expected: true,
 		},
 		{
 			name: "Short auto flag",
 			args: []string{"-a"},
-			checkFn: func(c *Config) bool {
-				return flag.Lookup("a") != nil && flag.Lookup("auto") != nil &&
+			checkFn: func(fs *flag.FlagSet, c *Config) bool {
+				return fs.Lookup("a") != nil && fs.Lookup("auto") != nil &&
 					c.Server.Auto.Enabled &&
-					c.Server.Auto.Default == "default" &&
-					c.Profiles["default"].CPUs == 4
+					c.Server.Auto.Default == "default"
 			},
 			expected: true,
 		},
 		{
 			name: "Long auto flag",
 			args: []string{"--auto"},
-			checkFn: func(c *Config) bool {
-				return flag.Lookup("a") != nil && flag.Lookup("auto") != nil &&
+			checkFn: func(fs *flag.FlagSet, c *Config) bool {
+				return fs.Lookup("a") != nil && fs.Lookup("auto") != nil &&
 					c.Server.Auto.Enabled &&
-					c.Server.Auto.Default == "default" &&
-					c.Profiles["default"].CPUs == 4
+					c.Server.Auto.Default == "default"
 			},
 			expected: true,
 		},
@@ -170,18 +187,6 @@ func TestFlagPatterns(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Save original state
-			oldArgs := os.Args
-			oldFlagCommandLine := flag.CommandLine
-			defer func() {
-				os.Args = oldArgs
-				flag.CommandLine = oldFlagCommandLine
-			}()
-
-			// Reset flags for this test
-			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-			os.Args = append([]string{"cmd"}, tt.args...)
-
 			if tt.envVar != "" {
 				oldEnv, exists := os.LookupEnv("COLIMA_MANAGER_CONFIG")
 				os.Setenv("COLIMA_MANAGER_CONFIG", tt.envVar)
@@ -194,12 +199,12 @@ func TestFlagPatterns(t *testing.T) {
 				}()
 			}
 
-			config, err := LoadConfig()
+			config, fs, err := loadConfigArgs(tt.args...)
 			if err != nil {
 				t.Fatalf("Failed to load config: %v", err)
 			}
 
-			if got := tt.checkFn(config); got != tt.expected {
+			if got := tt.checkFn(fs, config); got != tt.expected {
 				t.Errorf("Test %s failed: expected %v, got %v", tt.name, tt.expected, got)
 			}
 		})
@@ -207,14 +212,6 @@ func TestFlagPatterns(t *testing.T) {
 }
 
 func TestAutoFlagWithExistingProfile(t *testing.T) {
-	// Save original state
-	oldArgs := os.Args
-	oldFlagCommandLine := flag.CommandLine
-	defer func() {
-		os.Args = oldArgs
-		flag.CommandLine = oldFlagCommandLine
-	}()
-
 	// Create a config file with an existing default profile
 	content := []byte(`
 server:
@@ -239,11 +236,7 @@ profiles:
 		t.Fatal(err)
 	}
 
-	// Reset flags for this test
-	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-	os.Args = []string{"cmd", "-a", "-c", tmpfile.Name()}
-
-	config, err := LoadConfig()
+	config, _, err := loadConfigArgs("-a", "-c", tmpfile.Name())
 	if err != nil {
 		t.Fatalf("Failed to load config: %v", err)
 	}
@@ -262,12 +255,8 @@ func TestLoadConfigWithEnvVar(t *testing.T) {
 	defer cleanup()
 
 	// Save original state
-	oldArgs := os.Args
-	oldFlagCommandLine := flag.CommandLine
 	oldEnv, envExists := os.LookupEnv("COLIMA_MANAGER_CONFIG")
 	defer func() {
-		os.Args = oldArgs
-		flag.CommandLine = oldFlagCommandLine
 		if envExists {
 			os.Setenv("COLIMA_MANAGER_CONFIG", oldEnv)
 		} else {
@@ -275,12 +264,9 @@ func TestLoadConfigWithEnvVar(t *testing.T) {
 		}
 	}()
 
-	// Reset flags and set environment variable
-	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-	os.Args = []string{"cmd"}
 	os.Setenv("COLIMA_MANAGER_CONFIG", configPath)
 
-	config, err := LoadConfig()
+	config, _, err := loadConfigArgs()
 	if err != nil {
 		t.Fatalf("Failed to load config: %v", err)
 	}
@@ -289,18 +275,6 @@ func TestLoadConfigWithEnvVar(t *testing.T) {
 }
 
 func TestLoadConfigDefaults(t *testing.T) {
-	// Save original state
-	oldArgs := os.Args
-	oldFlagCommandLine := flag.CommandLine
-	defer func() {
-		os.Args = oldArgs
-		flag.CommandLine = oldFlagCommandLine
-	}()
-
-	// Reset flags for this test
-	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-	os.Args = []string{"cmd"}
-
 	// Temporarily move any existing config file
 	if err := os.Rename("config.yaml", "config.yaml.bak"); err != nil && !os.IsNotExist(err) {
 		t.Fatal(err)
@@ -311,7 +285,7 @@ func TestLoadConfigDefaults(t *testing.T) {
 		}
 	}()
 
-	config, err := LoadConfig()
+	config, _, err := loadConfigArgs()
 	if err != nil {
 		t.Fatalf("Failed to load config: %v", err)
 	}
@@ -320,3 +294,167 @@ func TestLoadConfigDefaults(t *testing.T) {
 		t.Errorf("Expected default port 8080, got %d", config.Server.Port)
 	}
 }
+
+func TestLoadConfigTimeouts(t *testing.T) {
+	content := []byte(`
+timeouts:
+  start: 10m
+  status: 45s
+  kubernetes_health: 5s
+`)
+	tmpfile, err := os.CreateTemp("", "config.*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	config, _, err := loadConfigArgs("-c", tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config.Timeouts.Start != 10*time.Minute {
+		t.Errorf("Expected start timeout 10m, got %v", config.Timeouts.Start)
+	}
+	if config.Timeouts.Status != 45*time.Second {
+		t.Errorf("Expected status timeout 45s, got %v", config.Timeouts.Status)
+	}
+	if config.Timeouts.Stop != 0 {
+		t.Errorf("Expected unset stop timeout, got %v", config.Timeouts.Stop)
+	}
+	if config.Timeouts.KubernetesHealth != 5*time.Second {
+		t.Errorf("Expected Kubernetes health timeout 5s, got %v", config.Timeouts.KubernetesHealth)
+	}
+}
+
+func TestListenAddressesYAML(t *testing.T) {
+	var single struct {
+		Listen ListenAddresses `yaml:"listen"`
+	}
+	if err := yaml.Unmarshal([]byte(`listen: unix:///tmp/manager.sock`), &single); err != nil {
+		t.Fatalf("Failed to parse single address: %v", err)
+	}
+	if len(single.Listen) != 1 || single.Listen[0] != "unix:///tmp/manager.sock" {
+		t.Errorf("Unexpected addresses: %v", single.Listen)
+	}
+
+	var list struct {
+		Listen ListenAddresses `yaml:"listen"`
+	}
+	if err := yaml.Unmarshal([]byte("listen:\n  - tcp://localhost:8080\n  - unix:///tmp/manager.sock\n"), &list); err != nil {
+		t.Fatalf("Failed to parse address list: %v", err)
+	}
+	if len(list.Listen) != 2 || list.Listen[0] != "tcp://localhost:8080" {
+		t.Errorf("Unexpected addresses: %v", list.Listen)
+	}
+}
+
+func TestPIDFile(t *testing.T) {
+	config, _, err := loadConfigArgs("-c", "does-not-exist.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Server.PIDFile != DefaultPIDFile {
+		t.Errorf("Expected default PID file %s, got %s", DefaultPIDFile, config.Server.PIDFile)
+	}
+
+	config, _, err = loadConfigArgs("-c", "does-not-exist.yaml", "--pid-file", "/run/manager.pid")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Server.PIDFile != "/run/manager.pid" {
+		t.Errorf("Expected PID file from flag, got %s", config.Server.PIDFile)
+	}
+}
+
+func TestAuditDefaults(t *testing.T) {
+	config, _, err := loadConfigArgs("-c", "does-not-exist.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !config.Audit.Enabled || config.Audit.Path != DefaultAuditFile {
+		t.Errorf("Expected the audit log to be on at %s, got %+v", DefaultAuditFile, config.Audit)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("audit:\n  enabled: false\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, _, err = loadConfigArgs("-c", path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Audit.Enabled {
+		t.Error("Expected the audit log to be disabled by the config file")
+	}
+}
+
+func TestDockerDefaults(t *testing.T) {
+	config, _, err := loadConfigArgs("-c", "does-not-exist.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !config.Docker.ManageContexts || config.Docker.UseContext {
+		t.Errorf("Expected managed contexts that are not switched to, got %+v", config.Docker)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("docker:\n  manage_contexts: false\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, _, err = loadConfigArgs("-c", path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Docker.ManageContexts {
+		t.Error("Expected context management to be disabled by the config file")
+	}
+}
+
+func TestKubeConfigSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "kubeconfig:\n  merge_path: /tmp/kube/config\n  prune_on_clean: true\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, _, err := loadConfigArgs("-c", path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.KubeConfig.MergePath != "/tmp/kube/config" || !config.KubeConfig.PruneOnClean {
+		t.Errorf("Expected the kubeconfig settings of the config file, got %+v", config.KubeConfig)
+	}
+}
+
+func TestLogDefaults(t *testing.T) {
+	config, _, err := loadConfigArgs("-c", "does-not-exist.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Log.Level != "info" || config.Log.Format != "text" || config.Log.Output != "stdout" {
+		t.Errorf("Expected info text logs on stdout, got %+v", config.Log)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "log:\n  output: /var/log/colima-manager/manager.log\n  max_size_mb: 10\n  max_age: 72h\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, _, err = loadConfigArgs("-c", path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Log.Output != "/var/log/colima-manager/manager.log" || config.Log.MaxSizeMB != 10 || config.Log.MaxAge != 72*time.Hour {
+		t.Errorf("Unexpected log config: %+v", config.Log)
+	}
+	if config.Log.Level != "info" {
+		t.Errorf("Expected the default level to survive, got %q", config.Log.Level)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package config
+
+import "errors"
+
+func lockConfig(path string) (func(), error) {
+	return nil, errors.New("config file locking is not supported on this platform")
+}
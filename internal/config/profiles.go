@@ -0,0 +1,216 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gqadonis/colima-manager/internal/pkg/fsutil"
+	"gopkg.in/yaml.v3"
+)
+
+// Path returns the config file the config was loaded from. Profile edits are
+// written to it, creating it if needed.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Profile returns the declared profile called name
+func (c *Config) Profile(name string) (ProfileConfig, bool) {
+	c.profilesMu.RLock()
+	defer c.profilesMu.RUnlock()
+	profile, ok := c.Profiles[name]
+	return profile, ok
+}
+
+// DeclaredProfiles returns a copy of the declared profiles
+func (c *Config) DeclaredProfiles() map[string]ProfileConfig {
+	c.profilesMu.RLock()
+	defer c.profilesMu.RUnlock()
+	profiles := make(map[string]ProfileConfig, len(c.Profiles))
+	for name, profile := range c.Profiles {
+		profiles[name] = profile
+	}
+	return profiles
+}
+
+// EditProfiles lets edit change a copy of the declared profiles and, unless
+// it returns an error, writes the profiles it added, changed or removed back
+// to the config file before applying them. The file is rewritten atomically
+// under an exclusive lock; other settings and comments are kept where the
+// YAML allows.
+func (c *Config) EditProfiles(edit func(profiles map[string]ProfileConfig) error) error {
+	c.profilesMu.Lock()
+	defer c.profilesMu.Unlock()
+
+	profiles := make(map[string]ProfileConfig, len(c.Profiles))
+	for name, profile := range c.Profiles {
+		profiles[name] = profile
+	}
+	if err := edit(profiles); err != nil {
+		return err
+	}
+
+	if c.path != "" {
+		unlock, err := lockConfig(c.path + ".lock")
+		if err != nil {
+			return fmt.Errorf("failed to lock config file: %w", err)
+		}
+		defer unlock()
+		if err := c.writeProfiles(profiles); err != nil {
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+	}
+	c.Profiles = profiles
+	return nil
+}
+
+// writeProfiles updates the profiles section of the config file to match
+// profiles, touching only the entries that differ from c.Profiles
+func (c *Config) writeProfiles(profiles map[string]ProfileConfig) error {
+	doc, mode, err := readDocument(c.path)
+	if err != nil {
+		return err
+	}
+	section := mappingValue(doc.Content[0], "profiles")
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if old, ok := c.Profiles[name]; ok && old == profiles[name] {
+			continue
+		}
+		var value yaml.Node
+		if err := value.Encode(profiles[name]); err != nil {
+			return err
+		}
+		setMappingValue(section, name, &value)
+	}
+	for name := range c.Profiles {
+		if _, ok := profiles[name]; !ok {
+			deleteMappingKey(section, name)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(c.path, buf.Bytes(), mode)
+}
+
+// readDocument parses the YAML file at path, or returns an empty document
+// when it does not exist yet
+func readDocument(path string) (*yaml.Node, os.FileMode, error) {
+	mode := os.FileMode(0o644)
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		data = nil
+	case err != nil:
+		return nil, 0, err
+	default:
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, 0, fmt.Errorf("%s does not hold a YAML mapping", path)
+	}
+	return &doc, mode, nil
+}
+
+// mappingValue returns the mapping stored under key in m, adding an empty
+// one when the key is missing or null
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != key {
+			continue
+		}
+		value := m.Content[i+1]
+		if value.Kind != yaml.MappingNode {
+			value.Kind, value.Tag, value.Value, value.Style = yaml.MappingNode, "!!map", "", 0
+			value.Content = nil
+		}
+		return value
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// setMappingValue stores value under key in m. When key exists, fields of
+// the old value keep their comments and order.
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			old := m.Content[i+1]
+			if old.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				mergeMapping(old, value)
+				return
+			}
+			value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// mergeMapping makes old hold exactly the pairs of value, reusing old's key
+// nodes so their comments survive
+func mergeMapping(old, value *yaml.Node) {
+	wanted := make(map[string]*yaml.Node)
+	var order []string
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		wanted[value.Content[i].Value] = value.Content[i+1]
+		order = append(order, value.Content[i].Value)
+	}
+
+	var content []*yaml.Node
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(old.Content); i += 2 {
+		key := old.Content[i].Value
+		next, ok := wanted[key]
+		if !ok {
+			continue
+		}
+		prev := old.Content[i+1]
+		next.LineComment, next.HeadComment, next.FootComment = prev.LineComment, prev.HeadComment, prev.FootComment
+		content = append(content, old.Content[i], next)
+		seen[key] = true
+	}
+	for _, key := range order {
+		if !seen[key] {
+			content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, wanted[key])
+		}
+	}
+	old.Content = content
+}
+
+// deleteMappingKey removes key and its value from m
+func deleteMappingKey(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+const commentedConfig = `# Colima Manager configuration
+server:
+  port: 9090 # API port
+
+profiles:
+  # Day-to-day work
+  work:
+    cpus: 4 # keep in sync with the team
+    memory: 8
+  scratch:
+    cpus: 2
+`
+
+func loadCommentedConfig(t *testing.T) (*Config, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(commentedConfig), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	config, _, err := loadConfigArgs("-c", path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	return config, path
+}
+
+func TestEditProfilesPersists(t *testing.T) {
+	config, path := loadCommentedConfig(t)
+
+	err := config.EditProfiles(func(profiles map[string]ProfileConfig) error {
+		work := profiles["work"]
+		work.CPUs = 6
+		profiles["work"] = work
+		delete(profiles, "scratch")
+		profiles["k8s"] = ProfileConfig{CPUs: 4, NetworkAddress: domain.Bool(false), Kubernetes: domain.Bool(true), DesiredState: "running"}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EditProfiles failed: %v", err)
+	}
+
+	if work, _ := config.Profile("work"); work.CPUs != 6 || work.Memory != 8 {
+		t.Errorf("Expected work to be updated in memory, got %+v", work)
+	}
+	if _, ok := config.Profile("scratch"); ok {
+		t.Error("Expected scratch to be removed in memory")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		"# Colima Manager configuration",
+		"port: 9090 # API port",
+		"# Day-to-day work",
+		"cpus: 6 # keep in sync with the team",
+		"k8s:",
+		"network_address: false",
+		"desired_state: running",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in the rewritten config:\n%s", want, content)
+		}
+	}
+	if strings.Contains(content, "scratch") {
+		t.Errorf("Expected scratch to be removed from the file:\n%s", content)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the file mode to be kept, got %v (%v)", info.Mode(), err)
+	}
+
+	reloaded, _, err := loadConfigArgs("-c", path)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if got, want := reloaded.DeclaredProfiles(), config.DeclaredProfiles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Reloaded profiles %+v, want %+v", got, want)
+	}
+}
+
+func TestEditProfilesAbortsOnError(t *testing.T) {
+	config, path := loadCommentedConfig(t)
+	errAbort := errors.New("abort")
+
+	err := config.EditProfiles(func(profiles map[string]ProfileConfig) error {
+		delete(profiles, "work")
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("Expected the edit error, got %v", err)
+	}
+	if _, ok := config.Profile("work"); !ok {
+		t.Error("Expected work to survive an aborted edit")
+	}
+	if data, _ := os.ReadFile(path); string(data) != commentedConfig {
+		t.Errorf("Expected the file to be untouched, got:\n%s", data)
+	}
+}
+
+func TestEditProfilesCreatesConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config, _, err := loadConfigArgs("-c", path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	err = config.EditProfiles(func(profiles map[string]ProfileConfig) error {
+		profiles["dev"] = ProfileConfig{CPUs: 2}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EditProfiles failed: %v", err)
+	}
+
+	reloaded, _, err := loadConfigArgs("-c", path)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if dev, ok := reloaded.Profile("dev"); !ok || dev.CPUs != 2 {
+		t.Errorf("Expected dev to be persisted, got %+v (found: %v)", dev, ok)
+	}
+}
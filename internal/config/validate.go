@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/hostinfo"
+)
+
+// DetectHost reports the capacity LoadConfig validates profiles against.
+// Tests replace it so validation does not depend on the machine running them.
+var DetectHost = hostinfo.Capacity
+
+// Host returns the capacity profiles are validated against
+func (c *Config) Host() domain.HostCapacity {
+	return c.host
+}
+
+// ValidateProfile checks a definition of name before it is stored
+func (c *Config) ValidateProfile(name string, profile ProfileConfig) error {
+	return domain.ValidateDefinition(name, colimaConfig(name, profile), profile.DesiredState, c.host)
+}
+
+// validate checks the shutdown policy, the defaults section and every
+// declared profile, reporting all invalid ones
+func (c *Config) validate() error {
+	var errs []error
+	if policy := domain.ShutdownPolicy(c.Server.ShutdownPolicy); policy != "" && !policy.Valid() {
+		errs = append(errs, fmt.Errorf("server.shutdown_policy: %w", &domain.InvalidShutdownPolicyError{Policy: string(policy)}))
+	}
+	if err := domain.ValidateColimaConfig(colimaConfig("", c.Defaults), c.host); err != nil {
+		errs = append(errs, fmt.Errorf("defaults: %w", err))
+	}
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := c.ValidateProfile(name, c.Profiles[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// colimaConfig returns the start settings of definition p of name
+func colimaConfig(name string, p ProfileConfig) domain.ColimaConfig {
+	return domain.ColimaConfig{
+		CPUs:           p.CPUs,
+		Memory:         p.Memory,
+		DiskSize:       p.DiskSize,
+		VMType:         p.VMType,
+		Runtime:        p.Runtime,
+		NetworkAddress: p.NetworkAddress,
+		Kubernetes:     p.Kubernetes,
+		Profile:        name,
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+func TestLoadConfigValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		fields []string // expected "profile:field" pairs
+	}{
+		{
+			name:   "valid",
+			data:   "defaults:\n  cpus: 4\nprofiles:\n  work:\n    cpus: 16\n    memory: 64\n    vm_type: qemu\n    runtime: docker\n",
+			fields: nil,
+		},
+		{
+			name:   "enums",
+			data:   "profiles:\n  work:\n    vm_type: banana\n    runtime: podman\n    desired_state: paused\n",
+			fields: []string{"work:vm_type", "work:runtime", "work:desired_state"},
+		},
+		{
+			name:   "host capacity",
+			data:   "profiles:\n  big:\n    cpus: 17\n    memory: 65\n    disk_size: -1\n",
+			fields: []string{"big:cpus", "big:memory", "big:disk_size"},
+		},
+		{
+			name:   "names",
+			data:   "profiles:\n  ../etc:\n    cpus: 2\n  ok:\n    cpus: -2\n",
+			fields: []string{"../etc:profile", "ok:cpus"},
+		},
+		{
+			name:   "defaults",
+			data:   "defaults:\n  runtime: podman\n",
+			fields: []string{":runtime"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+			_, err := LoadConfig(Flags{ConfigPath: path})
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("Expected the config to load, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), path) {
+				t.Fatalf("Expected an error naming %s, got %v", path, err)
+			}
+
+			var got []string
+			for _, invalid := range validationErrors(err) {
+				for _, field := range invalid.Fields {
+					got = append(got, invalid.Profile+":"+field.Field)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("Expected invalid fields %v, got %v", tt.fields, got)
+			}
+		})
+	}
+}
+
+func TestLoadConfigShutdownPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  shutdown_policy: stop-everything\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := LoadConfig(Flags{ConfigPath: path})
+	var invalid *domain.InvalidShutdownPolicyError
+	if !errors.As(err, &invalid) || invalid.Policy != "stop-everything" {
+		t.Fatalf("Expected an invalid shutdown policy error, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("server:\n  shutdown_policy: stop-managed\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(Flags{ConfigPath: path}); err != nil {
+		t.Errorf("Expected a valid shutdown policy to load, got %v", err)
+	}
+}
+
+// validationErrors collects the ValidationErrors wrapped or joined into err
+func validationErrors(err error) []*domain.ValidationError {
+	switch e := err.(type) {
+	case *domain.ValidationError:
+		return []*domain.ValidationError{e}
+	case interface{ Unwrap() []error }:
+		var found []*domain.ValidationError
+		for _, inner := range e.Unwrap() {
+			found = append(found, validationErrors(inner)...)
+		}
+		return found
+	case interface{ Unwrap() error }:
+		return validationErrors(e.Unwrap())
+	}
+	return nil
+}
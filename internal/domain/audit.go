@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/gqadonis/colima-manager/pkg/api"
+)
+
+type (
+	Caller      = api.Caller
+	AuditRecord = api.AuditRecord
+	AuditFilter = api.AuditFilter
+)
+
+type callerKey struct{}
+
+// WithCaller returns a context carrying caller
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom returns the caller registered with WithCaller, if any
+func CallerFrom(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+// AuditStore persists audit records in append-only fashion
+type AuditStore interface {
+	Append(record AuditRecord) error
+	// Query returns matching records, oldest first
+	Query(filter AuditFilter) ([]AuditRecord, error)
+}
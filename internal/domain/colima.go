@@ -2,109 +2,75 @@ package domain
 
 import (
 	"context"
-	"fmt"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/gqadonis/colima-manager/pkg/api"
 )
 
-// DependencyStatus represents the status of required dependencies
-type DependencyStatus struct {
-	Homebrew      bool   `json:"homebrew"`
-	HomebrewPath  string `json:"homebrew_path,omitempty"`
-	Colima        bool   `json:"colima"`
-	ColimaVersion string `json:"colima_version,omitempty"`
-	ColimaPath    string `json:"colima_path,omitempty"`
-	Lima          bool   `json:"lima"`
-	LimaVersion   string `json:"lima_version,omitempty"`
-}
+// Types of the HTTP API, defined in pkg/api so that clients outside this
+// module can use them
+type (
+	DependencyStatus = api.DependencyStatus
+	ColimaStatus     = api.ColimaStatus
+	ProfileState     = api.ProfileState
+	ProfileInfo      = api.ProfileInfo
+	CleanRequest     = api.CleanRequest
+	CleanPath        = api.CleanPath
+	CleanPlan        = api.CleanPlan
+	DockerContext    = api.DockerContext
+	ColimaConfig     = api.ColimaConfig
+	ConfigSource     = api.ConfigSource
+	EffectiveConfig  = api.EffectiveConfig
+)
 
-// ColimaStatus represents the status of Colima
-type ColimaStatus struct {
-	Status     string `json:"status"`
-	CPUs       int    `json:"cpus"`
-	Memory     int    `json:"memory"`
-	DiskSize   int    `json:"disk_size"`
-	Kubernetes bool   `json:"kubernetes"`
-	Profile    string `json:"profile"`
-}
+// Profile states as reported by colima
+const (
+	ProfileRunning = api.ProfileRunning
+	ProfileStopped = api.ProfileStopped
+)
 
-// CleanRequest represents the clean operation parameters
-type CleanRequest struct {
-	Profile string `json:"profile"` // empty string means clean all
-}
+const (
+	ProfileStateRunning = api.ProfileStateRunning
+	ProfileStateStopped = api.ProfileStateStopped
+	ProfileStateBroken  = api.ProfileStateBroken
+	ProfileStateAbsent  = api.ProfileStateAbsent
+)
 
-// DockerContext represents a Docker context configuration
-type DockerContext struct {
-	Name    string `json:"name"`
-	Profile string `json:"profile"`
-	Socket  string `json:"socket"`
-}
+const (
+	SourceRequest  = api.SourceRequest
+	SourceProfile  = api.SourceProfile
+	SourceDefaults = api.SourceDefaults
+	SourceBuiltIn  = api.SourceBuiltIn
+)
 
 // Custom error types
-type ProfileNotFoundError struct {
-	Profile string
-}
-
-func (e *ProfileNotFoundError) Error() string {
-	return fmt.Sprintf("profile '%s' does not exist", e.Profile)
-}
-
-type ProfileNotStartedError struct {
-	Profile string
-}
-
-func (e *ProfileNotStartedError) Error() string {
-	return fmt.Sprintf("profile '%s' is not started", e.Profile)
-}
-
-type ProfileUnreachableError struct {
-	Profile string
-	Reason  string
-}
-
-func (e *ProfileUnreachableError) Error() string {
-	return fmt.Sprintf("profile '%s' is unreachable: %s", e.Profile, e.Reason)
-}
-
-type ProfileMalfunctionError struct {
-	Profile string
-	Reason  string
-}
-
-func (e *ProfileMalfunctionError) Error() string {
-	return fmt.Sprintf("profile '%s' is malfunctioning: %s", e.Profile, e.Reason)
-}
-
-type ProfileBusyError struct {
-	Profile string
-}
-
-func (e *ProfileBusyError) Error() string {
-	return fmt.Sprintf("profile '%s' is currently busy with another operation", e.Profile)
-}
-
-type DependencyError struct {
-	Dependency string
-	Reason     string
-}
-
-func (e *DependencyError) Error() string {
-	return fmt.Sprintf("%s dependency error: %s", e.Dependency, e.Reason)
-}
-
-type DockerContextError struct {
-	Operation string
-	Profile   string
-	Reason    string
-}
-
-func (e *DockerContextError) Error() string {
-	return fmt.Sprintf("docker context %s failed for profile '%s': %s", e.Operation, e.Profile, e.Reason)
-}
+type (
+	ProfileNotFoundError    = api.ProfileNotFoundError
+	ProfileNotStartedError  = api.ProfileNotStartedError
+	ProfileUnreachableError = api.ProfileUnreachableError
+	ProfileMalfunctionError = api.ProfileMalfunctionError
+	ProfileBusyError        = api.ProfileBusyError
+	DependencyError         = api.DependencyError
+	DockerContextError      = api.DockerContextError
+	OperationTimeoutError   = api.OperationTimeoutError
+	OperationCanceledError  = api.OperationCanceledError
+	ProfileExistsError      = api.ProfileExistsError
+	CleanNotConfirmedError  = api.CleanNotConfirmedError
+)
 
 // ProfileLock provides thread-safe locking for profiles
 type ProfileLock struct {
 	mu    sync.Mutex
-	locks map[string]bool
+	locks map[string]HeldLock
+}
+
+// HeldLock describes the operation holding a profile lock
+type HeldLock struct {
+	Profile   string    `json:"profile"`
+	Operation string    `json:"operation,omitempty"`
+	Since     time.Time `json:"since"`
 }
 
 var (
@@ -115,7 +81,7 @@ var (
 func GetProfileLock() *ProfileLock {
 	lockOnce.Do(func() {
 		globalProfileLock = &ProfileLock{
-			locks: make(map[string]bool),
+			locks: make(map[string]HeldLock),
 		}
 	})
 	return globalProfileLock
@@ -124,18 +90,23 @@ func GetProfileLock() *ProfileLock {
 // For testing purposes only
 func ResetProfileLock() {
 	globalProfileLock = &ProfileLock{
-		locks: make(map[string]bool),
+		locks: make(map[string]HeldLock),
 	}
 }
 
 func (pl *ProfileLock) Lock(profile string) bool {
+	return pl.LockOperation(profile, "")
+}
+
+// LockOperation locks profile on behalf of operation
+func (pl *ProfileLock) LockOperation(profile, operation string) bool {
 	pl.mu.Lock()
 	defer pl.mu.Unlock()
 
-	if pl.locks[profile] {
+	if _, locked := pl.locks[profile]; locked {
 		return false
 	}
-	pl.locks[profile] = true
+	pl.locks[profile] = HeldLock{Profile: profile, Operation: operation, Since: time.Now()}
 	return true
 }
 
@@ -148,37 +119,52 @@ func (pl *ProfileLock) Unlock(profile string) {
 func (pl *ProfileLock) IsLocked(profile string) bool {
 	pl.mu.Lock()
 	defer pl.mu.Unlock()
-	return pl.locks[profile]
+	_, locked := pl.locks[profile]
+	return locked
+}
+
+// Held returns the locks currently held, ordered by profile
+func (pl *ProfileLock) Held() []HeldLock {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	held := make([]HeldLock, 0, len(pl.locks))
+	for _, lock := range pl.locks {
+		held = append(held, lock)
+	}
+	sort.Slice(held, func(i, j int) bool { return held[i].Profile < held[j].Profile })
+	return held
 }
 
 // ColimaRepository defines the interface for Colima operations
 type ColimaRepository interface {
 	Start(ctx context.Context, config ColimaConfig) error
 	Stop(ctx context.Context, profile string) error
-	StopDaemon(ctx context.Context) error
 	Status(ctx context.Context, profile string) (*ColimaStatus, error)
+	ListProfiles(ctx context.Context, declared []string) ([]ProfileInfo, error)
 	GetKubeConfig(ctx context.Context, profile string) (string, error)
-	Clean(ctx context.Context, req CleanRequest) error
+	PlanClean(ctx context.Context, req CleanRequest) (*CleanPlan, error)
+	Clean(ctx context.Context, plan *CleanPlan) error
 	CheckDependencies(ctx context.Context) (*DependencyStatus, error)
 	UpdateDependencies(ctx context.Context) error
-	CreateDockerContext(ctx context.Context, profile string) error
+	CreateDockerContext(ctx context.Context, profile, socket string) error
+	UseDockerContext(ctx context.Context, profile string) error
 	RemoveDockerContext(ctx context.Context, profile string) error
 	ListDockerContexts(ctx context.Context) ([]DockerContext, error)
 }
 
-// ColimaConfig represents the configuration for starting Colima
-type ColimaConfig struct {
-	CPUs           int    `json:"cpus,omitempty"`
-	Memory         int    `json:"memory,omitempty"`
-	DiskSize       int    `json:"disk_size,omitempty"`
-	VMType         string `json:"vm_type,omitempty"`
-	Runtime        string `json:"runtime,omitempty"`
-	NetworkAddress bool   `json:"network_address"`
-	Kubernetes     bool   `json:"kubernetes"`
-	Profile        string `json:"profile,omitempty"`
+// Bool returns a pointer to v, to set a switch of ColimaConfig
+func Bool(v bool) *bool {
+	return api.Bool(v)
+}
+
+// Enabled reports whether switch v is set and true
+func Enabled(v *bool) bool {
+	return api.Enabled(v)
 }
 
-// DefaultColimaConfig returns a configuration with default values
+// DefaultColimaConfig returns the built-in defaults, the last layer of start
+// config resolution
 func DefaultColimaConfig() ColimaConfig {
 	return ColimaConfig{
 		CPUs:           12,
@@ -186,8 +172,8 @@ func DefaultColimaConfig() ColimaConfig {
 		DiskSize:       100,
 		VMType:         "vz",
 		Runtime:        "containerd",
-		NetworkAddress: true,
-		Kubernetes:     true,
+		NetworkAddress: Bool(true),
+		Kubernetes:     Bool(true),
 		Profile:        "default",
 	}
 }
@@ -0,0 +1,30 @@
+package domain
+
+import "github.com/gqadonis/colima-manager/pkg/api"
+
+// Error codes reported by the API alongside the error message
+const (
+	CodeProfileNotFound    = api.CodeProfileNotFound
+	CodeProfileNotStarted  = api.CodeProfileNotStarted
+	CodeProfileUnreachable = api.CodeProfileUnreachable
+	CodeProfileMalfunction = api.CodeProfileMalfunction
+	CodeProfileBusy        = api.CodeProfileBusy
+	CodeDependencyError    = api.CodeDependencyError
+	CodeDockerContextError = api.CodeDockerContextError
+	CodeOperationTimeout   = api.CodeOperationTimeout
+	CodeOperationCanceled  = api.CodeOperationCanceled
+	CodeJobNotFound        = api.CodeJobNotFound
+	CodeShuttingDown       = api.CodeShuttingDown
+	CodeProfileExists      = api.CodeProfileExists
+	CodeValidationFailed   = api.CodeValidationFailed
+	CodeCleanNotConfirmed  = api.CodeCleanNotConfirmed
+	CodeKubeConfigError    = api.CodeKubeConfigError
+)
+
+// ErrorDetail is the wire form of a domain error
+type ErrorDetail = api.ErrorDetail
+
+// NewErrorDetail describes err; errors that are not domain errors get no code
+func NewErrorDetail(err error) *ErrorDetail {
+	return api.NewErrorDetail(err)
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+)
+
+// EventType identifies a profile lifecycle event
+type EventType string
+
+const (
+	EventStartRequested      EventType = "start_requested"
+	EventStarted             EventType = "started"
+	EventStopped             EventType = "stopped"
+	EventCleaned             EventType = "cleaned"
+	EventFailed              EventType = "failed"
+	EventDependenciesUpdated EventType = "dependencies_updated"
+	// EventShutdown is the last event of every stream when the daemon exits
+	EventShutdown EventType = "shutdown"
+)
+
+// Event describes something that happened to a profile
+type Event struct {
+	ID        uint64    `json:"id"`
+	Type      EventType `json:"type"`
+	Profile   string    `json:"profile,omitempty"`
+	Operation string    `json:"operation,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// EventPublisher receives lifecycle events from the use case layer
+type EventPublisher interface {
+	Publish(event Event)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"io"
+
+	"github.com/gqadonis/colima-manager/pkg/api"
+)
+
+type (
+	JobState         = api.JobState
+	Job              = api.Job
+	JobNotFoundError = api.JobNotFoundError
+)
+
+const (
+	JobQueued      = api.JobQueued
+	JobRunning     = api.JobRunning
+	JobSucceeded   = api.JobSucceeded
+	JobFailed      = api.JobFailed
+	JobInterrupted = api.JobInterrupted
+)
+
+type commandOutputKey struct{}
+
+// WithCommandOutput returns a context whose command output is copied to w
+func WithCommandOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, commandOutputKey{}, w)
+}
+
+// CommandOutput returns the writer registered with WithCommandOutput, or
+// io.Discard when none is set
+func CommandOutput(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(commandOutputKey{}).(io.Writer); ok {
+		return w
+	}
+	return io.Discard
+}
+
+type jobIDKey struct{}
+
+// WithJobID returns a context for work done on behalf of job id
+func WithJobID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, jobIDKey{}, id)
+}
+
+// JobID returns the ID registered with WithJobID, or an empty string
+func JobID(ctx context.Context) string {
+	id, _ := ctx.Value(jobIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "github.com/gqadonis/colima-manager/pkg/api"
+
+// KubeContextName is the name a profile's cluster, user and context get in a
+// shared kubeconfig file
+func KubeContextName(profile string) string {
+	return "colima-" + profile
+}
+
+type (
+	KubeConfigMerge = api.KubeConfigMerge
+	KubeConfigError = api.KubeConfigError
+)
+
+// KubeConfigStore merges kubeconfigs into a shared kubeconfig file and
+// prunes them from it
+type KubeConfigStore interface {
+	// Merge adds the current context of kubeconfig, with its cluster and
+	// user, under name, replacing entries of the same name
+	Merge(name string, kubeconfig []byte) (*KubeConfigMerge, error)
+	// Prune removes the cluster, user and context called name
+	Prune(name string) (*KubeConfigMerge, error)
+}
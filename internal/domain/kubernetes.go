@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/gqadonis/colima-manager/pkg/api"
+)
+
+type (
+	KubernetesHealth   = api.KubernetesHealth
+	KubernetesAPICheck = api.KubernetesAPICheck
+	KubernetesNode     = api.KubernetesNode
+)
+
+// KubernetesHealthChecker probes the cluster a kubeconfig points at. An
+// unreachable or unready cluster is reported in the result; errors are kept
+// for kubeconfigs that cannot be used at all.
+type KubernetesHealthChecker interface {
+	Check(ctx context.Context, kubeconfig []byte) (*KubernetesHealth, error)
+}
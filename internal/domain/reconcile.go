@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+)
+
+// Desired states a profile can declare for the reconciler
+const (
+	DesiredRunning = "running"
+	DesiredStopped = "stopped"
+	DesiredAbsent  = "absent"
+)
+
+// ProfileReconcileStatus reports the reconciler's view of a single profile
+type ProfileReconcileStatus struct {
+	Name           string       `json:"name"`
+	DesiredState   string       `json:"desired_state"`
+	ActualState    ProfileState `json:"actual_state,omitempty"`
+	Converged      bool         `json:"converged"`
+	LastAction     string       `json:"last_action,omitempty"`
+	LastError      string       `json:"last_error,omitempty"`
+	Failures       int          `json:"failures"`
+	LastReconciled *time.Time   `json:"last_reconciled,omitempty"`
+	NextAttempt    *time.Time   `json:"next_attempt,omitempty"`
+}
+
+// ReconcileReport summarizes the most recent reconciliation pass
+type ReconcileReport struct {
+	Enabled  bool                     `json:"enabled"`
+	Interval string                   `json:"interval"`
+	LastRun  *time.Time               `json:"last_run,omitempty"`
+	Profiles []ProfileReconcileStatus `json:"profiles"`
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gqadonis/colima-manager/pkg/api"
+)
+
+// ShutdownPolicy decides what happens to running profiles when the manager
+// shuts down
+type ShutdownPolicy = api.ShutdownPolicy
+
+const (
+	ShutdownLeaveRunning = api.ShutdownLeaveRunning
+	ShutdownStopManaged  = api.ShutdownStopManaged
+	ShutdownStopAll      = api.ShutdownStopAll
+)
+
+// ShutdownReport summarizes what a shutdown did to profiles
+type ShutdownReport struct {
+	Policy      ShutdownPolicy         `json:"policy"`
+	Stopped     []string               `json:"stopped"`
+	Failed      map[string]string      `json:"failed,omitempty"`
+	Interrupted []InterruptedOperation `json:"interrupted,omitempty"`
+}
+
+// InterruptedOperation is an operation that was still holding its profile
+// lock when the shutdown grace period ran out and had to be canceled
+type InterruptedOperation struct {
+	Operation     string    `json:"operation,omitempty"`
+	Profile       string    `json:"profile"`
+	JobID         string    `json:"job_id,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+	InterruptedAt time.Time `json:"interrupted_at"`
+}
+
+type ShuttingDownError = api.ShuttingDownError
+
+type InvalidShutdownPolicyError struct {
+	Policy string
+}
+
+func (e *InvalidShutdownPolicyError) Error() string {
+	return fmt.Sprintf("unknown shutdown policy '%s' (want leave-running, stop-managed or stop-all)", e.Policy)
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gqadonis/colima-manager/pkg/api"
+)
+
+// Values colima accepts for the enumerated settings
+var (
+	VMTypes  = []string{"vz", "qemu"}
+	Runtimes = []string{"docker", "containerd"}
+)
+
+// MaxProfileNameLength bounds profile names, which end up in paths and VM names
+const MaxProfileNameLength = 64
+
+// profileNamePattern keeps profile names safe to use as a path element
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// HostCapacity is what the host can give a single VM. Zero fields are unknown
+// and not checked.
+type HostCapacity struct {
+	CPUs   int `json:"cpus"`
+	Memory int `json:"memory"` // GiB
+}
+
+type (
+	FieldError      = api.FieldError
+	ValidationError = api.ValidationError
+)
+
+// fieldErrors collects the problems found while validating one profile
+type fieldErrors []FieldError
+
+func (f *fieldErrors) add(field, format string, args ...interface{}) {
+	*f = append(*f, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns a *ValidationError for profile, or nil when nothing was found
+func (f fieldErrors) err(profile string) error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &ValidationError{Profile: profile, Fields: f}
+}
+
+// ValidateProfileName checks that name is safe to pass to colima and to use
+// as a path element
+func ValidateProfileName(name string) error {
+	var errs fieldErrors
+	errs.checkName(name)
+	return errs.err(name)
+}
+
+// ValidateColimaConfig checks a start configuration against the values colima
+// accepts and against host. Zero fields are unset and pass, since they are
+// filled in when the configuration is resolved; an empty profile is default.
+func ValidateColimaConfig(config ColimaConfig, host HostCapacity) error {
+	var errs fieldErrors
+	if config.Profile != "" {
+		errs.checkName(config.Profile)
+	}
+	errs.checkConfig(config, host)
+	return errs.err(config.Profile)
+}
+
+// ValidateDefinition checks a profile definition: its name, its settings and
+// the state the reconciler should keep it in
+func ValidateDefinition(name string, config ColimaConfig, desiredState string, host HostCapacity) error {
+	var errs fieldErrors
+	errs.checkName(name)
+	errs.checkConfig(config, host)
+	switch desiredState {
+	case "", DesiredRunning, DesiredStopped, DesiredAbsent:
+	default:
+		errs.add("desired_state", "must be one of %s, %s or %s, got %q",
+			DesiredRunning, DesiredStopped, DesiredAbsent, desiredState)
+	}
+	return errs.err(name)
+}
+
+// ValidateCleanRequest checks the profile names of a clean. Preserve only
+// applies to a clean of all profiles.
+func ValidateCleanRequest(req CleanRequest) error {
+	var errs fieldErrors
+	if req.Profile != "" {
+		errs.checkName(req.Profile)
+		if len(req.Preserve) > 0 {
+			errs.add("preserve", "only applies when cleaning all profiles")
+		}
+	}
+	for _, name := range req.Preserve {
+		var preserve fieldErrors
+		if preserve.checkName(name); len(preserve) > 0 {
+			errs.add("preserve", "%q %s", name, preserve[0].Message)
+		}
+	}
+	return errs.err(req.Profile)
+}
+
+func (f *fieldErrors) checkName(name string) {
+	switch {
+	case name == "":
+		f.add("profile", "must not be empty")
+	case len(name) > MaxProfileNameLength:
+		f.add("profile", "must be at most %d characters long", MaxProfileNameLength)
+	case !profileNamePattern.MatchString(name):
+		f.add("profile", "must start with a letter or digit and contain only letters, digits, '.', '_' and '-'")
+	}
+}
+
+func (f *fieldErrors) checkConfig(config ColimaConfig, host HostCapacity) {
+	f.checkRange("cpus", config.CPUs, host.CPUs, "the host's %d CPUs")
+	f.checkRange("memory", config.Memory, host.Memory, "the host's %d GiB of memory")
+	if config.DiskSize < 0 {
+		f.add("disk_size", "must be positive, got %d", config.DiskSize)
+	}
+	f.checkEnum("vm_type", config.VMType, VMTypes)
+	f.checkEnum("runtime", config.Runtime, Runtimes)
+}
+
+// checkRange rejects negative values and values above a known limit
+func (f *fieldErrors) checkRange(field string, value, limit int, limitFormat string) {
+	switch {
+	case value < 0:
+		f.add(field, "must be positive, got %d", value)
+	case limit > 0 && value > limit:
+		f.add(field, "must not exceed "+limitFormat+", got %d", limit, value)
+	}
+}
+
+func (f *fieldErrors) checkEnum(field, value string, allowed []string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	f.add(field, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+func TestFileStoreAppendAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	records, err := store.Query(domain.AuditFilter{})
+	if err != nil || len(records) != 0 {
+		t.Fatalf("Expected no records before the first append, got %v (%v)", records, err)
+	}
+
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, r := range []domain.AuditRecord{
+		{Operation: "start", Profile: "web", Outcome: "success"},
+		{Operation: "stop", Profile: "web", Outcome: "success"},
+		{Operation: "start", Profile: "batch", Outcome: "busy", ErrorCode: domain.CodeProfileBusy},
+	} {
+		r.Time = base.Add(time.Duration(i) * time.Hour)
+		if err := store.Append(r); err != nil {
+			t.Fatalf("Failed to append: %v", err)
+		}
+	}
+
+	// A torn or foreign line must not hide the records around it
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	file.WriteString("not json\n")
+	file.Close()
+
+	tests := []struct {
+		name   string
+		filter domain.AuditFilter
+		want   []string
+	}{
+		{"all", domain.AuditFilter{}, []string{"start/web", "stop/web", "start/batch"}},
+		{"profile", domain.AuditFilter{Profile: "web"}, []string{"start/web", "stop/web"}},
+		{"operation", domain.AuditFilter{Operation: "start"}, []string{"start/web", "start/batch"}},
+		{"since", domain.AuditFilter{Since: base.Add(time.Hour)}, []string{"stop/web", "start/batch"}},
+		{"limit", domain.AuditFilter{Limit: 1}, []string{"start/batch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := store.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			var got []string
+			for _, r := range records {
+				got = append(got, r.Operation+"/"+r.Profile)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the audit log to be private, got %v (%v)", info.Mode(), err)
+	}
+}
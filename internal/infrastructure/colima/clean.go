@@ -0,0 +1,202 @@
+package colima
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+// PlanClean lists what Clean removes for req without changing anything.
+// Only paths that exist and contexts Docker knows about are listed.
+func (r *ColimaRepository) PlanClean(ctx context.Context, req domain.CleanRequest) (*domain.CleanPlan, error) {
+	log := r.log.WithContext(ctx)
+	log.Info("Planning cleanup - Profile: %s", req.Profile)
+
+	profiles := []string{}
+	if req.Profile != "" {
+		if !r.checkProfileExists(req.Profile) {
+			return nil, log.LogError(&domain.ProfileNotFoundError{Profile: req.Profile},
+				"profile not found during cleanup")
+		}
+		profiles = append(profiles, req.Profile)
+	} else {
+		onDisk, err := r.profilesOnDisk()
+		if err != nil {
+			return nil, log.LogError(err, "failed to read colima directory")
+		}
+		preserved := make(map[string]bool, len(req.Preserve))
+		for _, name := range req.Preserve {
+			preserved[name] = true
+		}
+		for _, name := range onDisk {
+			if !preserved[name] {
+				profiles = append(profiles, name)
+			}
+		}
+	}
+
+	contexts := make(map[string]bool)
+	if len(profiles) > 0 {
+		listed, err := r.ListDockerContexts(ctx)
+		if err != nil {
+			log.Warn("Docker contexts are left out of the clean plan: %v", err)
+		}
+		for _, c := range listed {
+			contexts[c.Name] = true
+		}
+	}
+
+	plan := &domain.CleanPlan{
+		Profiles:       profiles,
+		Directories:    []domain.CleanPath{},
+		DockerContexts: []string{},
+		KubeConfigs:    []domain.CleanPath{},
+	}
+	for _, profile := range profiles {
+		for _, dir := range r.profileDirs(profile) {
+			if size, ok := pathSize(dir); ok {
+				plan.Directories = append(plan.Directories, domain.CleanPath{Path: dir, Size: size})
+				plan.TotalSize += size
+			}
+		}
+		if size, ok := pathSize(r.kubeConfigPath(profile)); ok {
+			plan.KubeConfigs = append(plan.KubeConfigs, domain.CleanPath{Path: r.kubeConfigPath(profile), Size: size})
+			plan.TotalSize += size
+		}
+		if name := dockerContextName(profile); contexts[name] {
+			plan.DockerContexts = append(plan.DockerContexts, name)
+		}
+		if req.PreserveConfig {
+			if size, ok := pathSize(r.profileConfigPath(profile)); ok {
+				plan.Preserved = append(plan.Preserved, domain.CleanPath{Path: r.profileConfigPath(profile), Size: size})
+				plan.TotalSize -= size
+			}
+		}
+	}
+
+	log.Info("Cleanup plan - Profiles: %v, Directories: %d, Docker contexts: %v, Kubeconfigs: %d, Size: %d bytes",
+		plan.Profiles, len(plan.Directories), plan.DockerContexts, len(plan.KubeConfigs), plan.TotalSize)
+	return plan, nil
+}
+
+// Clean deletes the profiles of plan and removes everything else it lists.
+// Preserved files are put back even when the clean fails halfway.
+func (r *ColimaRepository) Clean(ctx context.Context, plan *domain.CleanPlan) (err error) {
+	log := r.log.WithContext(ctx)
+	log.Info("Starting cleanup - Profiles: %v", plan.Profiles)
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Clean)
+	defer cancel()
+
+	preserved := make(map[string][]byte, len(plan.Preserved))
+	for _, file := range plan.Preserved {
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return log.LogError(err, "failed to read preserved file %s", file.Path)
+		}
+		preserved[file.Path] = data
+	}
+	defer func() {
+		for path, data := range preserved {
+			if restoreErr := restoreFile(path, data); restoreErr != nil && err == nil {
+				err = log.LogError(restoreErr, "failed to restore preserved file %s", path)
+			}
+		}
+	}()
+
+	for _, profile := range plan.Profiles {
+		// Profile names end up in the paths removed below
+		if err := domain.ValidateProfileName(profile); err != nil {
+			return log.LogError(err, "refusing to clean profile")
+		}
+
+		cmd := r.exec.Command(ctx, "colima", "stop", "-p", profile)
+		output, err := cmd.CombinedOutput()
+		r.recordOutput(ctx, output)
+		if err != nil {
+			log.Debug("Error stopping profile %s (non-fatal): %s", profile, string(output))
+		}
+
+		cmd = r.exec.Command(ctx, "colima", "delete", "-p", profile, "-f")
+		output, err = cmd.CombinedOutput()
+		r.recordOutput(ctx, output)
+		if err != nil {
+			return log.LogError(r.commandError(ctx, "delete", err), "failed to delete profile %s: %s", profile, string(output))
+		}
+	}
+
+	for _, dir := range plan.Directories {
+		log.Debug("Removing directory: %s", dir.Path)
+		if err := os.RemoveAll(dir.Path); err != nil {
+			return log.LogError(err, "failed to remove directory: %s", dir.Path)
+		}
+	}
+	for _, file := range plan.KubeConfigs {
+		log.Debug("Removing kubeconfig: %s", file.Path)
+		if err := os.Remove(file.Path); err != nil && !os.IsNotExist(err) {
+			return log.LogError(err, "failed to remove kubeconfig: %s", file.Path)
+		}
+	}
+	contexts := make(map[string]bool, len(plan.DockerContexts))
+	for _, name := range plan.DockerContexts {
+		contexts[name] = true
+	}
+	for _, profile := range plan.Profiles {
+		if contexts[dockerContextName(profile)] {
+			if err := r.RemoveDockerContext(ctx, profile); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Info("Cleanup finished - Profiles: %v", plan.Profiles)
+	return nil
+}
+
+// profileDirs returns the directories colima and lima keep for profile
+func (r *ColimaRepository) profileDirs(profile string) []string {
+	instance := "colima-" + profile
+	if profile == "default" {
+		instance = "colima"
+	}
+	return []string{
+		filepath.Join(r.homeDir, ".colima", profile),
+		filepath.Join(r.homeDir, ".colima", "_lima", instance),
+		filepath.Join(r.homeDir, ".lima", instance),
+	}
+}
+
+// pathSize returns the disk space used by path and everything below it, and
+// false when path does not exist
+func pathSize(path string) (int64, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, false
+	}
+	if !info.IsDir() {
+		return diskUsage(info), true
+	}
+
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // count what can be read
+		}
+		if info, err := entry.Info(); err == nil && !info.IsDir() {
+			size += diskUsage(info)
+		}
+		return nil
+	})
+	return size, true
+}
+
+// restoreFile writes a preserved file back, recreating its directory
+func restoreFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,79 @@
+package colima
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCleanRepository creates the files colima and lima keep for profiles
+func newCleanRepository(t *testing.T, profiles ...string) *ColimaRepository {
+	homeDir := t.TempDir()
+	repo := &ColimaRepository{
+		homeDir: homeDir,
+		log:     logger.GetLogger(),
+		exec: &mockExecutor{commands: map[string]mockOutput{
+			"docker context ls --format {{json .}}": {output: readTestdata(t, "docker.contexts.jsonl")},
+		}},
+	}
+	for _, profile := range profiles {
+		for _, dir := range repo.profileDirs(profile) {
+			require.NoError(t, os.MkdirAll(dir, 0755))
+		}
+		require.NoError(t, os.WriteFile(repo.profileConfigPath(profile), []byte("cpu: 2\n"), 0644))
+		require.NoError(t, os.WriteFile(repo.kubeConfigPath(profile), []byte("apiVersion: v1\n"), 0644))
+	}
+	return repo
+}
+
+func TestPlanClean(t *testing.T) {
+	repo := newCleanRepository(t, "default", "work", "dev")
+	ctx := context.Background()
+
+	plan, err := repo.PlanClean(ctx, domain.CleanRequest{Profile: "work", PreserveConfig: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, plan.Profiles)
+	assert.Len(t, plan.Directories, 3)
+	assert.Equal(t, []string{"colima-work"}, plan.DockerContexts)
+	require.Len(t, plan.KubeConfigs, 1)
+	assert.Equal(t, repo.kubeConfigPath("work"), plan.KubeConfigs[0].Path)
+	require.Len(t, plan.Preserved, 1)
+	assert.Equal(t, repo.profileConfigPath("work"), plan.Preserved[0].Path)
+
+	plan, err = repo.PlanClean(ctx, domain.CleanRequest{Preserve: []string{"dev"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "work"}, plan.Profiles)
+	assert.Equal(t, []string{"colima", "colima-work"}, plan.DockerContexts)
+	assert.Empty(t, plan.Preserved)
+
+	_, err = repo.PlanClean(ctx, domain.CleanRequest{Profile: "ghost"})
+	assert.IsType(t, &domain.ProfileNotFoundError{}, err)
+}
+
+func TestCleanPreservesConfig(t *testing.T) {
+	repo := newCleanRepository(t, "work", "dev")
+	ctx := context.Background()
+
+	plan, err := repo.PlanClean(ctx, domain.CleanRequest{Profile: "work", PreserveConfig: true})
+	require.NoError(t, err)
+	require.NoError(t, repo.Clean(ctx, plan))
+
+	data, err := os.ReadFile(repo.profileConfigPath("work"))
+	require.NoError(t, err)
+	assert.Equal(t, "cpu: 2\n", string(data))
+	for _, dir := range repo.profileDirs("work")[1:] {
+		assert.NoDirExists(t, dir)
+	}
+	assert.NoFileExists(t, repo.kubeConfigPath("work"))
+
+	// Other profiles are left alone
+	for _, dir := range repo.profileDirs("dev") {
+		assert.DirExists(t, dir)
+	}
+	assert.FileExists(t, repo.kubeConfigPath("dev"))
+}
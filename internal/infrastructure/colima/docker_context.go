@@ -0,0 +1,188 @@
+package colima
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+// dockerContextDescription marks the Docker contexts colima-manager created
+const dockerContextDescription = "colima-manager"
+
+// dockerContextName is the Docker context colima-manager keeps for profile
+func dockerContextName(profile string) string {
+	if profile == "default" {
+		return "colima"
+	}
+	return "colima-" + profile
+}
+
+// dockerContextEntry is one line of `docker context ls --format '{{json .}}'`
+type dockerContextEntry struct {
+	Name           string `json:"Name"`
+	Description    string `json:"Description"`
+	Current        bool   `json:"Current"`
+	DockerEndpoint string `json:"DockerEndpoint"`
+}
+
+// managedProfile returns the profile of a context colima-manager manages:
+// colima's own default context, or a colima-<profile> context marked with
+// dockerContextDescription or with the description colima gives the contexts
+// it creates. Other contexts, such as a user's colima-staging pointing at a
+// remote host, are left alone however they are named.
+func (e dockerContextEntry) managedProfile() (string, bool) {
+	if e.Name == "colima" {
+		return "default", true
+	}
+	profile := strings.TrimPrefix(e.Name, "colima-")
+	if profile == e.Name || profile == "" {
+		return "", false
+	}
+	switch e.Description {
+	case dockerContextDescription, "colima [profile=" + profile + "]":
+		return profile, true
+	}
+	return "", false
+}
+
+// CreateDockerContext creates the context of profile pointing at socket, or
+// points an existing one at socket, marking it as managed
+func (r *ColimaRepository) CreateDockerContext(ctx context.Context, profile, socket string) error {
+	log := r.log.WithContext(ctx)
+	log.Info("Creating Docker context for profile: %s, Socket: %s", profile, socket)
+
+	contextName := dockerContextName(profile)
+	host := "host=unix://" + strings.TrimPrefix(socket, "unix://")
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Command)
+	defer cancel()
+
+	operation := "create"
+	if err := r.exec.Command(ctx, "docker", "context", "inspect", contextName).Run(); err == nil {
+		operation = "update"
+	}
+	cmd := r.exec.Command(ctx, "docker", "context", operation, contextName,
+		"--description", dockerContextDescription, "--docker", host)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return log.LogError(&domain.DockerContextError{
+			Operation: operation,
+			Profile:   profile,
+			Reason:    fmt.Sprintf("failed to %s context: %v - %s", operation, err, string(output)),
+		}, "docker context %s failed", operation)
+	}
+
+	log.Info("Docker context %sd successfully - Profile: %s, Context: %s", operation, profile, contextName)
+	return nil
+}
+
+// UseDockerContext makes the context of profile the docker CLI's default
+func (r *ColimaRepository) UseDockerContext(ctx context.Context, profile string) error {
+	log := r.log.WithContext(ctx)
+	contextName := dockerContextName(profile)
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Command)
+	defer cancel()
+
+	cmd := r.exec.Command(ctx, "docker", "context", "use", contextName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return log.LogError(&domain.DockerContextError{
+			Operation: "use",
+			Profile:   profile,
+			Reason:    fmt.Sprintf("failed to use context: %v - %s", err, string(output)),
+		}, "docker context use failed")
+	}
+
+	log.Info("Docker context %s is now current", contextName)
+	return nil
+}
+
+func (r *ColimaRepository) RemoveDockerContext(ctx context.Context, profile string) error {
+	log := r.log.WithContext(ctx)
+	log.Info("Removing Docker context for profile: %s", profile)
+
+	contextName := dockerContextName(profile)
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Command)
+	defer cancel()
+
+	cmd := r.exec.Command(ctx, "docker", "context", "rm", "-f", contextName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// If the context doesn't exist, we don't treat it as an error
+		if strings.Contains(string(output), "not found") {
+			log.Debug("Docker context %s not found, skipping removal", contextName)
+			return nil
+		}
+		return log.LogError(&domain.DockerContextError{
+			Operation: "remove",
+			Profile:   profile,
+			Reason:    fmt.Sprintf("failed to remove context: %v - %s", err, string(output)),
+		}, "docker context removal failed")
+	}
+
+	log.Info("Docker context removed successfully - Profile: %s, Context: %s", profile, contextName)
+	return nil
+}
+
+// ListDockerContexts returns the contexts colima-manager manages with the
+// socket each points at. Contexts whose profile directory is gone are
+// marked orphaned, and those whose socket does not exist broken.
+func (r *ColimaRepository) ListDockerContexts(ctx context.Context) ([]domain.DockerContext, error) {
+	log := r.log.WithContext(ctx)
+	log.Info("Listing Docker contexts")
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Command)
+	defer cancel()
+
+	cmd := r.exec.Command(ctx, "docker", "context", "ls", "--format", "{{json .}}")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, log.LogError(r.commandError(ctx, "list docker contexts", err), "failed to list Docker contexts")
+	}
+
+	contexts := []domain.DockerContext{}
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var entry dockerContextEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			log.Warn("Skipping unreadable docker context entry %q: %v", line, err)
+			continue
+		}
+		profile, ok := entry.managedProfile()
+		if !ok {
+			continue
+		}
+		contexts = append(contexts, domain.DockerContext{
+			Name:     entry.Name,
+			Profile:  profile,
+			Socket:   strings.TrimPrefix(entry.DockerEndpoint, "unix://"),
+			Current:  entry.Current,
+			Orphaned: !r.checkProfileExists(profile),
+			Broken:   !socketExists(entry.DockerEndpoint),
+		})
+	}
+
+	log.Info("Found %d Colima Docker contexts", len(contexts))
+	return contexts, nil
+}
+
+// socketExists reports whether the unix socket of a docker endpoint exists.
+// Other endpoints, such as tcp:// or ssh://, cannot be checked and count as
+// existing.
+func socketExists(endpoint string) bool {
+	path, ok := strings.CutPrefix(endpoint, "unix://")
+	if !ok {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
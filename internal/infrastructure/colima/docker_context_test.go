@@ -0,0 +1,113 @@
+package colima
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListDockerContexts(t *testing.T) {
+	homeDir := t.TempDir()
+	for _, dir := range []string{"default", "work"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".colima", dir), 0755))
+	}
+	socket := filepath.Join(homeDir, ".colima", "default", "docker.sock")
+	ln, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	var output strings.Builder
+	for _, c := range []struct {
+		name, description, endpoint string
+		current                     bool
+	}{
+		{"default", "Current DOCKER_HOST based configuration", "unix:///var/run/docker.sock", false},
+		{"colima", "colima", "unix://" + socket, true},
+		{"colima-work", "colima-manager", "unix://" + filepath.Join(homeDir, ".colima", "work", "docker.sock"), false},
+		{"colima-gone", "colima [profile=gone]", "unix://" + filepath.Join(homeDir, ".colima", "gone", "docker.sock"), false},
+		{"colima-remote", "colima-manager", "ssh://dev@build-host", false},
+		{"colima-staging", "", "tcp://staging.internal:2376", false},
+		{"colimax", "colima-manager", "unix:///tmp/other.sock", false},
+	} {
+		fmt.Fprintf(&output, `{"Current":%v,"Description":%q,"DockerEndpoint":%q,"Name":%q}`+"\n",
+			c.current, c.description, c.endpoint, c.name)
+	}
+	repo := &ColimaRepository{
+		homeDir: homeDir,
+		log:     logger.GetLogger(),
+		exec: &mockExecutor{commands: map[string]mockOutput{
+			"docker context ls --format {{json .}}": {output: []byte(output.String())},
+		}},
+	}
+
+	contexts, err := repo.ListDockerContexts(context.Background())
+	require.NoError(t, err)
+
+	expected := []domain.DockerContext{
+		{Name: "colima", Profile: "default", Socket: socket, Current: true},
+		{Name: "colima-work", Profile: "work", Socket: filepath.Join(homeDir, ".colima", "work", "docker.sock"), Broken: true},
+		{Name: "colima-gone", Profile: "gone", Socket: filepath.Join(homeDir, ".colima", "gone", "docker.sock"),
+			Orphaned: true, Broken: true},
+		{Name: "colima-remote", Profile: "remote", Socket: "ssh://dev@build-host", Orphaned: true},
+	}
+	assert.Equal(t, expected, contexts)
+}
+
+func TestResolveSockets(t *testing.T) {
+	profileDir := filepath.Join("/Users", "dev", ".colima", "work")
+	tests := []struct {
+		name     string
+		status   domain.ColimaStatus
+		expected domain.ColimaStatus
+	}{
+		{"reported", domain.ColimaStatus{Runtime: "docker", DockerSocket: "/run/docker.sock"},
+			domain.ColimaStatus{Runtime: "docker", DockerSocket: "/run/docker.sock"}},
+		{"docker", domain.ColimaStatus{Runtime: "docker"},
+			domain.ColimaStatus{Runtime: "docker", DockerSocket: filepath.Join(profileDir, "docker.sock")}},
+		{"containerd", domain.ColimaStatus{Runtime: "containerd", DockerSocket: "/stale/docker.sock"},
+			domain.ColimaStatus{Runtime: "containerd", ContainerdSocket: filepath.Join(profileDir, "containerd.sock")}},
+		{"unknown runtime", domain.ColimaStatus{}, domain.ColimaStatus{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveSockets(&tt.status, profileDir)
+			assert.Equal(t, tt.expected, tt.status)
+		})
+	}
+}
+
+func TestCreateDockerContext(t *testing.T) {
+	ctx := context.Background()
+
+	exec := &mockExecutor{commands: map[string]mockOutput{
+		"docker context inspect colima-work": {err: errors.New("exit status 1")},
+	}}
+	repo := &ColimaRepository{log: logger.GetLogger(), exec: exec}
+	require.NoError(t, repo.CreateDockerContext(ctx, "work", "/Users/dev/.colima/work/docker.sock"))
+	assert.Contains(t, exec.calls, "docker context create colima-work --description colima-manager --docker host=unix:///Users/dev/.colima/work/docker.sock")
+
+	// An existing context is pointed at the new socket
+	exec = &mockExecutor{}
+	repo.exec = exec
+	require.NoError(t, repo.CreateDockerContext(ctx, "default", "unix:///Users/dev/.colima/default/docker.sock"))
+	assert.Contains(t, exec.calls, "docker context update colima --description colima-manager --docker host=unix:///Users/dev/.colima/default/docker.sock")
+
+	exec = &mockExecutor{commands: map[string]mockOutput{
+		"docker context update colima --description colima-manager --docker host=unix:///tmp/docker.sock": {err: errors.New("exit status 1")},
+	}}
+	repo.exec = exec
+	err := repo.CreateDockerContext(ctx, "default", "/tmp/docker.sock")
+	var contextErr *domain.DockerContextError
+	require.True(t, errors.As(err, &contextErr), "got %v", err)
+	assert.Equal(t, "update", contextErr.Operation)
+}
@@ -1,9 +1,15 @@
 package colima
 
 import (
+	"context"
 	"os/exec"
+	"time"
 )
 
+// killWaitDelay bounds how long Wait blocks on I/O after the process group
+// has been killed because its context was done
+const killWaitDelay = 5 * time.Second
+
 // Command defines the interface for command execution
 type Command interface {
 	Output() ([]byte, error)
@@ -11,9 +17,10 @@ type Command interface {
 	Run() error
 }
 
-// Executor defines the interface for executing commands
+// Executor defines the interface for executing commands. Commands are bound
+// to ctx and must be terminated when ctx is cancelled or times out.
 type Executor interface {
-	Command(name string, args ...string) Command
+	Command(ctx context.Context, name string, args ...string) Command
 }
 
 // RealExecutor implements Executor using real system commands
@@ -36,8 +43,11 @@ func (c *RealCommand) Run() error {
 	return c.Cmd.Run()
 }
 
-func (e *RealExecutor) Command(name string, args ...string) Command {
-	return &RealCommand{Cmd: exec.Command(name, args...)}
+func (e *RealExecutor) Command(ctx context.Context, name string, args ...string) Command {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = killWaitDelay
+	setProcessGroup(cmd)
+	return &RealCommand{Cmd: cmd}
 }
 
 // NewRealExecutor creates a new RealExecutor
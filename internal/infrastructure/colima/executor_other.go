@@ -0,0 +1,11 @@
+//go:build !unix
+
+package colima
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on platforms without process groups; the
+// default exec.CommandContext behaviour of killing the process applies
+func setProcessGroup(cmd *exec.Cmd) {}
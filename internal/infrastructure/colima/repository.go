@@ -2,22 +2,94 @@ package colima
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/gqadonis/colima-manager/internal/config"
 	"github.com/gqadonis/colima-manager/internal/domain"
 	"github.com/gqadonis/colima-manager/internal/pkg/logger"
 )
 
+// Timeouts bounds how long each kind of external command may run
+type Timeouts struct {
+	Start        time.Duration
+	Stop         time.Duration
+	Status       time.Duration
+	Clean        time.Duration
+	Dependencies time.Duration
+	Command      time.Duration // short auxiliary commands (which, version, docker context)
+}
+
+// DefaultTimeouts returns the timeouts used when none are configured
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Start:        15 * time.Minute,
+		Stop:         5 * time.Minute,
+		Status:       time.Minute,
+		Clean:        5 * time.Minute,
+		Dependencies: 30 * time.Minute,
+		Command:      time.Minute,
+	}
+}
+
+// TimeoutsFromConfig converts the configured timeouts; zero values still
+// fall back to the defaults
+func TimeoutsFromConfig(cfg config.TimeoutConfig) Timeouts {
+	return Timeouts{
+		Start:        cfg.Start,
+		Stop:         cfg.Stop,
+		Status:       cfg.Status,
+		Clean:        cfg.Clean,
+		Dependencies: cfg.Dependencies,
+		Command:      cfg.Command,
+	}
+}
+
+// withDefaults fills zero values from DefaultTimeouts
+func (t Timeouts) withDefaults() Timeouts {
+	defaults := DefaultTimeouts()
+	if t.Start <= 0 {
+		t.Start = defaults.Start
+	}
+	if t.Stop <= 0 {
+		t.Stop = defaults.Stop
+	}
+	if t.Status <= 0 {
+		t.Status = defaults.Status
+	}
+	if t.Clean <= 0 {
+		t.Clean = defaults.Clean
+	}
+	if t.Dependencies <= 0 {
+		t.Dependencies = defaults.Dependencies
+	}
+	if t.Command <= 0 {
+		t.Command = defaults.Command
+	}
+	return t
+}
+
+// withTimeout bounds ctx by d; a non-positive d leaves ctx unbounded
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
 type ColimaRepository struct {
-	homeDir string
-	log     *logger.Logger
-	exec    Executor
+	homeDir  string
+	log      *logger.Logger
+	exec     Executor
+	timeouts Timeouts
 }
 
-func NewColimaRepository() (*ColimaRepository, error) {
+func NewColimaRepository(timeouts Timeouts) (*ColimaRepository, error) {
 	log := logger.GetLogger()
 	log.Info("Initializing Colima repository")
 
@@ -27,124 +99,117 @@ func NewColimaRepository() (*ColimaRepository, error) {
 	}
 
 	repo := &ColimaRepository{
-		homeDir: homeDir,
-		log:     log,
-		exec:    NewRealExecutor(),
+		homeDir:  homeDir,
+		log:      log,
+		exec:     NewRealExecutor(),
+		timeouts: timeouts.withDefaults(),
 	}
 
 	log.Info("Colima repository initialized with home directory: %s", homeDir)
 	return repo, nil
 }
 
-func (r *ColimaRepository) StopDaemon(ctx context.Context) error {
-	r.log.Info("Stopping colima-manager daemon")
-
-	pidFile := "/tmp/colima-manager.pid"
-	pidBytes, err := os.ReadFile(pidFile)
-	if err != nil {
-		return r.log.LogError(err, "failed to read PID file")
-	}
-
-	pid := strings.TrimSpace(string(pidBytes))
-	cmd := r.exec.Command("kill", pid)
-	if err := cmd.Run(); err != nil {
-		return r.log.LogError(err, "failed to kill daemon process")
-	}
-
-	if err := os.Remove(pidFile); err != nil {
-		r.log.Error("failed to remove PID file: %v", err)
-	}
-
-	r.log.Info("Daemon stopped successfully")
-	return nil
-}
-
 func (r *ColimaRepository) CheckDependencies(ctx context.Context) (*domain.DependencyStatus, error) {
-	r.log.Info("Checking dependencies")
+	log := r.log.WithContext(ctx)
+	log.Info("Checking dependencies")
 	status := &domain.DependencyStatus{}
 
+	ctx, cancel := withTimeout(ctx, r.timeouts.Command)
+	defer cancel()
+
 	// Check Homebrew
-	brewPath, err := r.exec.Command("brew", "--prefix").Output()
+	brewPath, err := r.exec.Command(ctx, "brew", "--prefix").Output()
 	if err == nil {
 		status.Homebrew = true
 		status.HomebrewPath = strings.TrimSpace(string(brewPath))
-		r.log.Debug("Homebrew found at: %s", status.HomebrewPath)
+		log.Debug("Homebrew found at: %s", status.HomebrewPath)
 	} else {
-		r.log.Error("Homebrew not found: %v", err)
+		log.Error("Homebrew not found: %v", err)
 	}
 
 	if !status.Homebrew {
-		return status, r.log.LogError(&domain.DependencyError{
+		return status, log.LogError(&domain.DependencyError{
 			Dependency: "homebrew",
 			Reason:     "not installed or not in PATH",
 		}, "homebrew dependency check failed")
 	}
 
 	// Check Colima
-	colimaPath, err := r.exec.Command("which", "colima").Output()
+	colimaPath, err := r.exec.Command(ctx, "which", "colima").Output()
 	if err == nil {
 		status.Colima = true
 		status.ColimaPath = strings.TrimSpace(string(colimaPath))
-		r.log.Debug("Colima found at: %s", status.ColimaPath)
+		log.Debug("Colima found at: %s", status.ColimaPath)
 
 		// Get Colima version
-		if out, err := r.exec.Command("colima", "version").Output(); err == nil {
+		if out, err := r.exec.Command(ctx, "colima", "version").Output(); err == nil {
 			status.ColimaVersion = strings.TrimSpace(string(out))
-			r.log.Debug("Colima version: %s", status.ColimaVersion)
+			log.Debug("Colima version: %s", status.ColimaVersion)
 		} else {
-			r.log.Error("Failed to get Colima version: %v", err)
+			log.Error("Failed to get Colima version: %v", err)
 		}
 	} else {
-		r.log.Error("Colima not found: %v", err)
+		log.Error("Colima not found: %v", err)
 	}
 
 	// Check Lima version using brew
-	cmd := r.exec.Command("brew", "list", "--versions", "lima")
+	cmd := r.exec.Command(ctx, "brew", "list", "--versions", "lima")
 	if out, err := cmd.Output(); err == nil {
 		parts := strings.Fields(string(out))
 		if len(parts) >= 2 {
 			status.Lima = true
 			status.LimaVersion = parts[1]
-			r.log.Debug("Lima version: %s", status.LimaVersion)
+			log.Debug("Lima version: %s", status.LimaVersion)
 		}
 	} else {
-		r.log.Error("Failed to get Lima version: %v", err)
+		log.Error("Failed to get Lima version: %v", err)
 	}
 
-	r.log.Info("Dependency check completed - Homebrew: %v, Colima: %v, Lima: %v",
+	log.Info("Dependency check completed - Homebrew: %v, Colima: %v, Lima: %v",
 		status.Homebrew, status.Colima, status.Lima)
 	return status, nil
 }
 
 func (r *ColimaRepository) UpdateDependencies(ctx context.Context) error {
-	r.log.Info("Updating dependencies")
+	log := r.log.WithContext(ctx)
+	log.Info("Updating dependencies")
+
+	ctx, cancel := withTimeout(ctx, r.timeouts.Dependencies)
+	defer cancel()
 
 	// Update Homebrew first
-	r.log.Debug("Updating Homebrew")
-	cmd := r.exec.Command("brew", "update")
+	log.Debug("Updating Homebrew")
+	cmd := r.exec.Command(ctx, "brew", "update")
 	if err := cmd.Run(); err != nil {
-		return r.log.LogError(&domain.DependencyError{
+		if ctxErr := r.commandError(ctx, "brew update", err); ctxErr != err {
+			return log.LogError(ctxErr, "homebrew update interrupted")
+		}
+		return log.LogError(&domain.DependencyError{
 			Dependency: "homebrew",
 			Reason:     fmt.Sprintf("failed to update: %v", err),
 		}, "homebrew update failed")
 	}
 
 	// Upgrade Colima and Lima
-	r.log.Debug("Upgrading Colima and Lima")
-	cmd = r.exec.Command("brew", "upgrade", "colima", "lima")
+	log.Debug("Upgrading Colima and Lima")
+	cmd = r.exec.Command(ctx, "brew", "upgrade", "colima", "lima")
 	if err := cmd.Run(); err != nil {
-		return r.log.LogError(&domain.DependencyError{
+		if ctxErr := r.commandError(ctx, "brew upgrade", err); ctxErr != err {
+			return log.LogError(ctxErr, "colima/lima upgrade interrupted")
+		}
+		return log.LogError(&domain.DependencyError{
 			Dependency: "colima/lima",
 			Reason:     fmt.Sprintf("failed to upgrade: %v", err),
 		}, "colima/lima upgrade failed")
 	}
 
-	r.log.Info("Dependencies updated successfully")
+	log.Info("Dependencies updated successfully")
 	return nil
 }
 
 func (r *ColimaRepository) Start(ctx context.Context, config domain.ColimaConfig) error {
-	r.log.Info("Starting Colima with config: %+v", config)
+	log := r.log.WithContext(ctx)
+	log.Info("Starting Colima with config: %+v", config)
 
 	args := []string{
 		"start",
@@ -155,11 +220,11 @@ func (r *ColimaRepository) Start(ctx context.Context, config domain.ColimaConfig
 		"--runtime", config.Runtime,
 	}
 
-	if config.NetworkAddress {
+	if domain.Enabled(config.NetworkAddress) {
 		args = append(args, "--network-address")
 	}
 
-	if config.Kubernetes {
+	if domain.Enabled(config.Kubernetes) {
 		args = append(args, "--kubernetes")
 	}
 
@@ -167,22 +232,28 @@ func (r *ColimaRepository) Start(ctx context.Context, config domain.ColimaConfig
 		args = append(args, "-p", config.Profile)
 	}
 
-	r.log.Debug("Executing colima command with args: %v", args)
-	cmd := r.exec.Command("colima", args...)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Start)
+	defer cancel()
+
+	log.Debug("Executing colima command with args: %v", args)
+	cmd := r.exec.Command(ctx, "colima", args...)
 
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return r.log.LogError(err, "failed to start colima: %s", string(output))
+	output, err := cmd.CombinedOutput()
+	r.recordOutput(ctx, output)
+	if err != nil {
+		return log.LogError(r.commandError(ctx, "start", err), "failed to start colima: %s", string(output))
 	}
 
-	r.log.Info("Colima started successfully - Profile: %s", config.Profile)
+	log.Info("Colima started successfully - Profile: %s", config.Profile)
 	return nil
 }
 
 func (r *ColimaRepository) Stop(ctx context.Context, profile string) error {
-	r.log.Info("Stopping Colima profile: %s", profile)
+	log := r.log.WithContext(ctx)
+	log.Info("Stopping Colima profile: %s", profile)
 
 	if !r.checkProfileExists(profile) {
-		return r.log.LogError(&domain.ProfileNotFoundError{Profile: profile},
+		return log.LogError(&domain.ProfileNotFoundError{Profile: profile},
 			"profile not found during stop")
 	}
 
@@ -191,283 +262,279 @@ func (r *ColimaRepository) Stop(ctx context.Context, profile string) error {
 		args = append(args, "-p", profile)
 	}
 
-	r.log.Debug("Executing colima stop command with args: %v", args)
-	cmd := r.exec.Command("colima", args...)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Stop)
+	defer cancel()
+
+	log.Debug("Executing colima stop command with args: %v", args)
+	cmd := r.exec.Command(ctx, "colima", args...)
 
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return r.log.LogError(err, "failed to stop colima: %s", string(output))
+	output, err := cmd.CombinedOutput()
+	r.recordOutput(ctx, output)
+	if err != nil {
+		return log.LogError(r.commandError(ctx, "stop", err), "failed to stop colima: %s", string(output))
 	}
 
-	r.log.Info("Colima stopped successfully - Profile: %s", profile)
+	log.Info("Colima stopped successfully - Profile: %s", profile)
 	return nil
 }
 
 func (r *ColimaRepository) Status(ctx context.Context, profile string) (*domain.ColimaStatus, error) {
-	r.log.Info("Checking status for profile: %s", profile)
+	log := r.log.WithContext(ctx)
+	log.Info("Checking status for profile: %s", profile)
 
 	if !r.checkProfileExists(profile) {
-		return nil, r.log.LogError(&domain.ProfileNotFoundError{Profile: profile},
+		return nil, log.LogError(&domain.ProfileNotFoundError{Profile: profile},
 			"profile not found during status check")
 	}
 
-	args := []string{"status", "-e"}
+	ctx, cancel := withTimeout(ctx, r.timeouts.Status)
+	defer cancel()
+
+	// colima list reports stopped profiles without failing, unlike colima status
+	log.Debug("Executing colima list command")
+	listOutput, err := r.exec.Command(ctx, "colima", "list", "--json").CombinedOutput()
+	if err != nil {
+		return nil, log.LogError(r.commandError(ctx, "list", err), "failed to list colima profiles: %s", string(listOutput))
+	}
+	entries, err := parseListJSON(listOutput)
+	if err != nil {
+		return nil, log.LogError(&domain.ProfileMalfunctionError{
+			Profile: profile,
+			Reason:  err.Error(),
+		}, "failed to parse colima list output")
+	}
+	entry, listed := findListEntry(entries, profile)
+	if listed && entry.Status != domain.ProfileRunning {
+		return nil, log.LogError(&domain.ProfileNotStartedError{Profile: profile},
+			"profile is not running")
+	}
+
+	args := []string{"status", "--json"}
 	if profile != "" && profile != "default" {
 		args = append(args, "-p", profile)
 	}
 
-	r.log.Debug("Executing colima status command with args: %v", args)
-	cmd := r.exec.Command("colima", args...)
+	log.Debug("Executing colima status command with args: %v", args)
+	cmd := r.exec.Command(ctx, "colima", args...)
 	output, err := cmd.CombinedOutput()
 
 	outputStr := string(output)
-	r.log.Debug("Colima status output: %s", outputStr)
+	log.Debug("Colima status output: %s", outputStr)
 
 	if err != nil {
+		if ctxErr := r.commandError(ctx, "status", err); ctxErr != err {
+			return nil, log.LogError(ctxErr, "status check interrupted")
+		}
+
 		if strings.Contains(outputStr, "is not running") {
-			return nil, r.log.LogError(&domain.ProfileNotStartedError{Profile: profile},
+			return nil, log.LogError(&domain.ProfileNotStartedError{Profile: profile},
 				"profile is not running")
 		}
 
 		if strings.Contains(outputStr, "connection refused") ||
 			strings.Contains(outputStr, "cannot connect") {
-			return nil, r.log.LogError(&domain.ProfileUnreachableError{
+			return nil, log.LogError(&domain.ProfileUnreachableError{
 				Profile: profile,
 				Reason:  "connection to VM failed",
 			}, "profile is unreachable")
 		}
 
-		return nil, r.log.LogError(&domain.ProfileMalfunctionError{
+		return nil, log.LogError(&domain.ProfileMalfunctionError{
 			Profile: profile,
 			Reason:  outputStr,
 		}, "profile malfunction")
 	}
 
-	// Parse the output to create ColimaStatus
-	status := &domain.ColimaStatus{
-		Profile: profile,
+	parsed, err := parseStatusJSON(output)
+	if err != nil {
+		return nil, log.LogError(&domain.ProfileMalfunctionError{
+			Profile: profile,
+			Reason:  err.Error(),
+		}, "failed to parse colima status output")
 	}
 
-	// Basic parsing of the output
-	lines := strings.Split(outputStr, "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "running") {
-			status.Status = "running"
-		}
-		if strings.Contains(line, "CPU:") {
-			fmt.Sscanf(line, "CPU: %d", &status.CPUs)
-		}
-		if strings.Contains(line, "Memory:") {
-			fmt.Sscanf(line, "Memory: %d", &status.Memory)
-		}
-		if strings.Contains(line, "Disk:") {
-			fmt.Sscanf(line, "Disk: %d", &status.DiskSize)
-		}
-		if strings.Contains(line, "kubernetes") {
-			status.Kubernetes = true
+	// The profile config is optional; it only adds the VM type and Kubernetes version
+	var profileCfg *profileYAML
+	if data, err := os.ReadFile(r.profileConfigPath(profile)); err == nil {
+		if profileCfg, err = parseProfileYAML(data); err != nil {
+			log.Debug("Ignoring unreadable profile config: %v", err)
 		}
 	}
 
-	r.log.Info("Status check completed successfully - Profile: %s, Status: %+v", profile, status)
+	var listEntry *listEntryJSON
+	if listed {
+		listEntry = &entry
+	}
+	status := buildStatus(profile, parsed, listEntry, profileCfg)
+	resolveSockets(status, filepath.Join(r.homeDir, ".colima", profile))
+
+	log.Info("Status check completed successfully - Profile: %s, Status: %+v", profile, status)
 	return status, nil
 }
 
-func (r *ColimaRepository) GetKubeConfig(ctx context.Context, profile string) (string, error) {
-	r.log.Info("Getting kubeconfig for profile: %s", profile)
+// profileConfigPath returns the path of colima's own config for profile
+func (r *ColimaRepository) profileConfigPath(profile string) string {
+	return filepath.Join(r.homeDir, ".colima", profile, "colima.yaml")
+}
 
-	if !r.checkProfileExists(profile) {
-		return "", r.log.LogError(&domain.ProfileNotFoundError{Profile: profile},
-			"profile not found during kubeconfig retrieval")
+// kubeConfigPath is where colima writes the kubeconfig of profile
+func (r *ColimaRepository) kubeConfigPath(profile string) string {
+	if profile == "" || profile == "default" {
+		return filepath.Join(r.homeDir, ".colima", "colima.kubeconfig")
 	}
+	return filepath.Join(r.homeDir, ".colima", fmt.Sprintf("colima-%s.kubeconfig", profile))
+}
 
-	configName := "colima.kubeconfig"
-	if profile != "" && profile != "default" {
-		configName = fmt.Sprintf("colima-%s.kubeconfig", profile)
-	}
+func (r *ColimaRepository) ListProfiles(ctx context.Context, declared []string) ([]domain.ProfileInfo, error) {
+	log := r.log.WithContext(ctx)
+	log.Info("Listing profiles")
 
-	colimaKubeConfig := filepath.Join(r.homeDir, ".colima", configName)
-	r.log.Debug("Reading kubeconfig from: %s", colimaKubeConfig)
+	ctx, cancel := withTimeout(ctx, r.timeouts.Status)
+	defer cancel()
 
-	data, err := os.ReadFile(colimaKubeConfig)
+	output, err := r.exec.Command(ctx, "colima", "list", "--json").CombinedOutput()
 	if err != nil {
-		return "", r.log.LogError(err, "failed to read kubeconfig")
+		return nil, log.LogError(r.commandError(ctx, "list", err), "failed to list colima profiles: %s", string(output))
+	}
+	entries, err := parseListJSON(output)
+	if err != nil {
+		return nil, log.LogError(err, "failed to parse colima list output")
 	}
 
-	r.log.Info("Kubeconfig retrieved successfully - Profile: %s", profile)
-	return string(data), nil
-}
-
-func (r *ColimaRepository) Clean(ctx context.Context, req domain.CleanRequest) error {
-	r.log.Info("Starting cleanup - Profile: %s", req.Profile)
-
-	// If cleaning specific profile
-	if req.Profile != "" {
-		if !r.checkProfileExists(req.Profile) {
-			return r.log.LogError(&domain.ProfileNotFoundError{Profile: req.Profile},
-				"profile not found during cleanup")
-		}
-
-		// Stop the specific profile
-		cmd := r.exec.Command("colima", "stop", "-p", req.Profile)
-		if output, err := cmd.CombinedOutput(); err != nil {
-			r.log.Debug("Error stopping profile (non-fatal): %s", string(output))
-		}
-
-		// Delete the specific profile
-		cmd = r.exec.Command("colima", "delete", "-p", req.Profile, "-f")
-		if output, err := cmd.CombinedOutput(); err != nil {
-			return r.log.LogError(err, "failed to delete profile %s: %s", req.Profile, string(output))
-		}
+	onDisk, err := r.profilesOnDisk()
+	if err != nil {
+		return nil, log.LogError(err, "failed to read colima directory")
+	}
 
-		// Clean up profile-specific directories
-		profileDirs := []string{
-			filepath.Join(r.homeDir, ".colima", req.Profile),
-			filepath.Join(r.homeDir, ".lima", req.Profile),
+	profiles := make(map[string]*domain.ProfileInfo)
+	get := func(name string) *domain.ProfileInfo {
+		if info, ok := profiles[name]; ok {
+			return info
 		}
-
-		for _, dir := range profileDirs {
-			r.log.Debug("Removing directory: %s", dir)
-			if err := os.RemoveAll(dir); err != nil {
-				return r.log.LogError(err, "failed to remove directory: %s", dir)
+		info := &domain.ProfileInfo{Name: name}
+		profiles[name] = info
+		return info
+	}
+
+	for _, name := range declared {
+		get(name).Declared = true
+	}
+	for _, name := range onDisk {
+		get(name).OnDisk = true
+	}
+	for _, entry := range entries {
+		info := get(entry.Name)
+		info.Listed = true
+		info.Arch = entry.Arch
+		info.Runtime = entry.Runtime
+		info.CPUs = entry.CPUs
+		info.Memory = int(entry.Memory / gib)
+		info.DiskSize = int(entry.Disk / gib)
+		info.State = profileState(entry.Status)
+	}
+
+	result := make([]domain.ProfileInfo, 0, len(profiles))
+	for _, info := range profiles {
+		if !info.Listed {
+			// Leftover directories colima no longer knows about are broken
+			if info.OnDisk {
+				info.State = domain.ProfileStateBroken
+			} else {
+				info.State = domain.ProfileStateAbsent
 			}
 		}
-
-		r.log.Info("Profile cleaned successfully: %s", req.Profile)
-		return nil
-	}
-
-	// Cleaning all profiles
-	r.log.Debug("Cleaning all profiles")
-
-	// Stop all running instances
-	cmd := r.exec.Command("colima", "stop")
-	if output, err := cmd.CombinedOutput(); err != nil {
-		r.log.Debug("Error stopping instances (non-fatal): %s", string(output))
+		result = append(result, *info)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
 
-	// Delete all instances
-	cmd = r.exec.Command("colima", "delete", "-f")
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return r.log.LogError(err, "failed to delete all instances: %s", string(output))
-	}
+	log.Info("Found %d profiles", len(result))
+	return result, nil
+}
 
-	// Clean up all colima-related directories
-	dirsToDelete := []string{
-		filepath.Join(r.homeDir, ".colima"),
-		filepath.Join(r.homeDir, ".lima"),
+// profilesOnDisk returns the profile directories under ~/.colima, skipping
+// colima's internal directories such as _lima and _templates
+func (r *ColimaRepository) profilesOnDisk() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(r.homeDir, ".colima"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	for _, dir := range dirsToDelete {
-		r.log.Debug("Removing directory: %s", dir)
-		if err := os.RemoveAll(dir); err != nil {
-			return r.log.LogError(err, "failed to remove directory: %s", dir)
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), "_") || strings.HasPrefix(entry.Name(), ".") {
+			continue
 		}
+		names = append(names, entry.Name())
 	}
-
-	r.log.Info("All profiles cleaned successfully")
-	return nil
+	return names, nil
 }
 
-func (r *ColimaRepository) checkProfileExists(profile string) bool {
-	profilePath := filepath.Join(r.homeDir, ".colima", profile)
-	_, err := os.Stat(profilePath)
-	exists := err == nil
-	r.log.Debug("Checking profile existence - Profile: %s, Path: %s, Exists: %v",
-		profile, profilePath, exists)
-	return exists
+// profileState maps a status reported by colima list to a ProfileState
+func profileState(status string) domain.ProfileState {
+	switch status {
+	case domain.ProfileRunning:
+		return domain.ProfileStateRunning
+	case domain.ProfileStopped:
+		return domain.ProfileStateStopped
+	default:
+		return domain.ProfileStateBroken
+	}
 }
 
-func (r *ColimaRepository) CreateDockerContext(ctx context.Context, profile string) error {
-	r.log.Info("Creating Docker context for profile: %s", profile)
-
-	// Determine socket path based on profile
-	var socketPath string
-	if profile == "default" {
-		socketPath = "/var/run/docker.sock"
-	} else {
-		socketPath = fmt.Sprintf("/tmp/colima-%s.sock", profile)
-	}
+func (r *ColimaRepository) GetKubeConfig(ctx context.Context, profile string) (string, error) {
+	log := r.log.WithContext(ctx)
+	log.Info("Getting kubeconfig for profile: %s", profile)
 
-	// Create context name
-	contextName := "colima"
-	if profile != "default" {
-		contextName = fmt.Sprintf("colima-%s", profile)
+	if !r.checkProfileExists(profile) {
+		return "", log.LogError(&domain.ProfileNotFoundError{Profile: profile},
+			"profile not found during kubeconfig retrieval")
 	}
 
-	// Create new context
-	cmd := r.exec.Command("docker", "context", "create",
-		contextName,
-		"--docker", fmt.Sprintf("host=unix://%s", socketPath))
+	colimaKubeConfig := r.kubeConfigPath(profile)
+	log.Debug("Reading kubeconfig from: %s", colimaKubeConfig)
 
-	output, err := cmd.CombinedOutput()
+	data, err := os.ReadFile(colimaKubeConfig)
 	if err != nil {
-		return r.log.LogError(&domain.DockerContextError{
-			Operation: "create",
-			Profile:   profile,
-			Reason:    fmt.Sprintf("failed to create context: %v - %s", err, string(output)),
-		}, "docker context creation failed")
+		return "", log.LogError(err, "failed to read kubeconfig")
 	}
 
-	r.log.Info("Docker context created successfully - Profile: %s, Context: %s", profile, contextName)
-	return nil
+	log.Info("Kubeconfig retrieved successfully - Profile: %s", profile)
+	return string(data), nil
 }
 
-func (r *ColimaRepository) RemoveDockerContext(ctx context.Context, profile string) error {
-	r.log.Info("Removing Docker context for profile: %s", profile)
-
-	contextName := "colima"
-	if profile != "default" {
-		contextName = fmt.Sprintf("colima-%s", profile)
+// commandError translates a command failure caused by ctx into a timeout or
+// cancellation error; other failures are returned unchanged
+func (r *ColimaRepository) commandError(ctx context.Context, operation string, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &domain.OperationTimeoutError{Operation: operation}
+	case errors.Is(ctx.Err(), context.Canceled):
+		return &domain.OperationCanceledError{Operation: operation}
 	}
-
-	cmd := r.exec.Command("docker", "context", "rm", "-f", contextName)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// If the context doesn't exist, we don't treat it as an error
-		if strings.Contains(string(output), "not found") {
-			r.log.Debug("Docker context %s not found, skipping removal", contextName)
-			return nil
-		}
-		return r.log.LogError(&domain.DockerContextError{
-			Operation: "remove",
-			Profile:   profile,
-			Reason:    fmt.Sprintf("failed to remove context: %v - %s", err, string(output)),
-		}, "docker context removal failed")
-	}
-
-	r.log.Info("Docker context removed successfully - Profile: %s, Context: %s", profile, contextName)
-	return nil
+	return err
 }
 
-func (r *ColimaRepository) ListDockerContexts(ctx context.Context) ([]domain.DockerContext, error) {
-	r.log.Info("Listing Docker contexts")
-
-	cmd := r.exec.Command("docker", "context", "ls", "--format", "{{.Name}}")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, r.log.LogError(err, "failed to list Docker contexts")
+// recordOutput copies command output to the writer attached to ctx, if any
+func (r *ColimaRepository) recordOutput(ctx context.Context, output []byte) {
+	log := r.log.WithContext(ctx)
+	if len(output) == 0 {
+		return
 	}
-
-	contexts := []domain.DockerContext{}
-	for _, line := range strings.Split(string(output), "\n") {
-		if strings.HasPrefix(line, "colima") {
-			profile := "default"
-			if line != "colima" {
-				profile = strings.TrimPrefix(line, "colima-")
-			}
-
-			socketPath := "/var/run/docker.sock"
-			if profile != "default" {
-				socketPath = fmt.Sprintf("/tmp/colima-%s.sock", profile)
-			}
-
-			contexts = append(contexts, domain.DockerContext{
-				Name:    line,
-				Profile: profile,
-				Socket:  socketPath,
-			})
-		}
+	if _, err := domain.CommandOutput(ctx).Write(output); err != nil {
+		log.Debug("Failed to record command output: %v", err)
 	}
+}
 
-	r.log.Info("Found %d Colima Docker contexts", len(contexts))
-	return contexts, nil
+func (r *ColimaRepository) checkProfileExists(profile string) bool {
+	profilePath := filepath.Join(r.homeDir, ".colima", profile)
+	_, err := os.Stat(profilePath)
+	exists := err == nil
+	r.log.Debug("Checking profile existence - Profile: %s, Path: %s, Exists: %v",
+		profile, profilePath, exists)
+	return exists
 }
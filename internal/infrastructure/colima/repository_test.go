@@ -3,8 +3,11 @@ package colima
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gqadonis/colima-manager/internal/domain"
 	"github.com/gqadonis/colima-manager/internal/pkg/logger"
@@ -15,38 +18,71 @@ import (
 // mockExecutor implements the Executor interface for testing
 type mockExecutor struct {
 	commands map[string]mockOutput
+	mu       sync.Mutex
+	calls    []string // commands run, in order
 }
 
 type mockOutput struct {
 	output []byte
 	err    error
+	delay  time.Duration // simulated run time, interrupted when ctx is done
 }
 
 // mockCommand implements the Command interface
 type mockCommand struct {
+	ctx        context.Context
 	mockOutput mockOutput
 }
 
+// wait simulates the command running, honouring context cancellation the
+// same way exec.CommandContext does
+func (c *mockCommand) wait() error {
+	if c.mockOutput.delay == 0 {
+		return c.ctx.Err()
+	}
+	timer := time.NewTimer(c.mockOutput.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	}
+}
+
 func (c *mockCommand) Output() ([]byte, error) {
+	if err := c.wait(); err != nil {
+		return nil, err
+	}
 	return c.mockOutput.output, c.mockOutput.err
 }
 
 func (c *mockCommand) CombinedOutput() ([]byte, error) {
+	if err := c.wait(); err != nil {
+		return nil, err
+	}
 	return c.mockOutput.output, c.mockOutput.err
 }
 
 func (c *mockCommand) Run() error {
+	if err := c.wait(); err != nil {
+		return err
+	}
 	return c.mockOutput.err
 }
 
 // Command returns a new mockCommand that implements the Command interface
-func (m *mockExecutor) Command(name string, args ...string) Command {
+func (m *mockExecutor) Command(ctx context.Context, name string, args ...string) Command {
 	// Build the command string to match exactly what's being requested
 	cmdStr := name
 	if len(args) > 0 {
 		cmdStr = name + " " + strings.Join(args, " ")
 	}
 
+	m.mu.Lock()
+	m.calls = append(m.calls, cmdStr)
+	m.mu.Unlock()
+
 	// Get mock output if it exists, otherwise use empty output
 	output, ok := m.commands[cmdStr]
 	if !ok {
@@ -57,6 +93,7 @@ func (m *mockExecutor) Command(name string, args ...string) Command {
 	}
 
 	return &mockCommand{
+		ctx:        ctx,
 		mockOutput: output,
 	}
 }
@@ -141,3 +178,76 @@ func TestCheckDependencies(t *testing.T) {
 		})
 	}
 }
+
+func TestStartCancellation(t *testing.T) {
+	config := domain.ColimaConfig{
+		CPUs:     4,
+		Memory:   8,
+		DiskSize: 60,
+		VMType:   "vz",
+		Runtime:  "containerd",
+		Profile:  "default",
+	}
+	slowStart := map[string]mockOutput{
+		"colima start --cpu 4 --memory 8 --disk 60 --vm-type vz --runtime containerd": {
+			output: []byte("started"),
+			delay:  time.Minute,
+		},
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		repo := &ColimaRepository{
+			homeDir:  t.TempDir(),
+			log:      logger.GetLogger(),
+			exec:     &mockExecutor{commands: slowStart},
+			timeouts: Timeouts{Start: 10 * time.Millisecond},
+		}
+
+		err := repo.Start(context.Background(), config)
+		require.Error(t, err)
+		assert.IsType(t, &domain.OperationTimeoutError{}, err)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		repo := &ColimaRepository{
+			homeDir: t.TempDir(),
+			log:     logger.GetLogger(),
+			exec:    &mockExecutor{commands: slowStart},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		err := repo.Start(ctx, config)
+		require.Error(t, err)
+		assert.IsType(t, &domain.OperationCanceledError{}, err)
+	})
+}
+
+func TestListProfiles(t *testing.T) {
+	homeDir := t.TempDir()
+	for _, dir := range []string{"default", "work", "orphan", "_lima", "_templates"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".colima", dir), 0755))
+	}
+
+	repo := &ColimaRepository{
+		homeDir: homeDir,
+		log:     logger.GetLogger(),
+		exec: &mockExecutor{commands: map[string]mockOutput{
+			"colima list --json": {output: readTestdata(t, "docker.list.json")},
+		}},
+	}
+
+	profiles, err := repo.ListProfiles(context.Background(), []string{"default", "planned"})
+	require.NoError(t, err)
+
+	expected := []domain.ProfileInfo{
+		{Name: "default", State: domain.ProfileStateRunning, Declared: true, OnDisk: true, Listed: true,
+			Arch: "aarch64", Runtime: "docker", CPUs: 4, Memory: 8, DiskSize: 60},
+		{Name: "orphan", State: domain.ProfileStateBroken, OnDisk: true},
+		{Name: "planned", State: domain.ProfileStateAbsent, Declared: true},
+		{Name: "work", State: domain.ProfileStateStopped, OnDisk: true, Listed: true,
+			Arch: "aarch64", Runtime: "containerd+k3s", CPUs: 2, Memory: 2, DiskSize: 60},
+	}
+	assert.Equal(t, expected, profiles)
+}
@@ -0,0 +1,191 @@
+package colima
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"gopkg.in/yaml.v2"
+)
+
+const gib = 1 << 30
+
+// statusJSON mirrors the output of `colima status --json`
+type statusJSON struct {
+	DisplayName      string `json:"display_name"`
+	Driver           string `json:"driver"`
+	Arch             string `json:"arch"`
+	Runtime          string `json:"runtime"`
+	MountType        string `json:"mount_type"`
+	IPAddress        string `json:"ip_address"`
+	DockerSocket     string `json:"docker_socket"`
+	ContainerdSocket string `json:"containerd_socket"`
+	Kubernetes       bool   `json:"kubernetes"`
+	CPU              int    `json:"cpu"`
+	Memory           int64  `json:"memory"`
+	Disk             int64  `json:"disk"`
+}
+
+// listEntryJSON mirrors one line of `colima list --json`
+type listEntryJSON struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Arch    string `json:"arch"`
+	CPUs    int    `json:"cpus"`
+	Memory  int64  `json:"memory"`
+	Disk    int64  `json:"disk"`
+	Runtime string `json:"runtime"`
+	Address string `json:"address"`
+}
+
+// profileYAML holds the fields read from ~/.colima/<profile>/colima.yaml
+type profileYAML struct {
+	Runtime    string `yaml:"runtime"`
+	VMType     string `yaml:"vmType"`
+	MountType  string `yaml:"mountType"`
+	Kubernetes struct {
+		Enabled bool   `yaml:"enabled"`
+		Version string `yaml:"version"`
+	} `yaml:"kubernetes"`
+}
+
+// parseStatusJSON decodes the output of `colima status --json`. Log lines
+// colima writes to stderr around the JSON object are ignored.
+func parseStatusJSON(data []byte) (*statusJSON, error) {
+	for _, line := range jsonLines(data) {
+		var status statusJSON
+		if err := json.Unmarshal(line, &status); err != nil {
+			return nil, fmt.Errorf("invalid colima status output: %w", err)
+		}
+		return &status, nil
+	}
+	return nil, fmt.Errorf("invalid colima status output: no JSON object found")
+}
+
+// parseListJSON decodes the output of `colima list --json`, which prints one
+// JSON object per profile and line
+func parseListJSON(data []byte) ([]listEntryJSON, error) {
+	entries := []listEntryJSON{}
+	for _, line := range jsonLines(data) {
+		var entry listEntryJSON
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("invalid colima list output: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// jsonLines returns the lines of data that hold a JSON object
+func jsonLines(data []byte) [][]byte {
+	var lines [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if bytes.HasPrefix(line, []byte("{")) {
+			lines = append(lines, append([]byte(nil), line...))
+		}
+	}
+	return lines
+}
+
+// parseProfileYAML decodes a profile's colima.yaml
+func parseProfileYAML(data []byte) (*profileYAML, error) {
+	var cfg profileYAML
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid colima profile config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// findListEntry returns the entry for profile, if colima knows about it
+func findListEntry(entries []listEntryJSON, profile string) (listEntryJSON, bool) {
+	for _, entry := range entries {
+		if entry.Name == profile {
+			return entry, true
+		}
+	}
+	return listEntryJSON{}, false
+}
+
+// buildStatus combines the status output with the optional list entry and
+// profile config into a domain.ColimaStatus
+func buildStatus(profile string, status *statusJSON, entry *listEntryJSON, cfg *profileYAML) *domain.ColimaStatus {
+	result := &domain.ColimaStatus{
+		Status:           domain.ProfileRunning,
+		Profile:          profile,
+		CPUs:             status.CPU,
+		Memory:           int(status.Memory / gib),
+		DiskSize:         int(status.Disk / gib),
+		Kubernetes:       status.Kubernetes,
+		Arch:             status.Arch,
+		Runtime:          status.Runtime,
+		VMType:           vmTypeFromDriver(status.Driver),
+		MountType:        status.MountType,
+		IPAddress:        status.IPAddress,
+		DockerSocket:     strings.TrimPrefix(status.DockerSocket, "unix://"),
+		ContainerdSocket: strings.TrimPrefix(status.ContainerdSocket, "unix://"),
+	}
+
+	if entry != nil {
+		if entry.Status != "" {
+			result.Status = entry.Status
+		}
+		if result.IPAddress == "" {
+			result.IPAddress = entry.Address
+		}
+	}
+
+	if cfg != nil {
+		if cfg.VMType != "" {
+			result.VMType = cfg.VMType
+		}
+		if result.MountType == "" {
+			result.MountType = cfg.MountType
+		}
+		if result.Runtime == "" {
+			result.Runtime = cfg.Runtime
+		}
+		if result.Kubernetes {
+			result.KubernetesVersion = cfg.Kubernetes.Version
+		}
+	}
+
+	return result
+}
+
+// resolveSockets fills in the sockets colima did not report from the layout
+// it uses for the runtime: <profile dir>/docker.sock for docker and
+// <profile dir>/containerd.sock for containerd. A containerd profile never
+// gets a docker socket.
+func resolveSockets(status *domain.ColimaStatus, profileDir string) {
+	switch status.Runtime {
+	case "docker":
+		if status.DockerSocket == "" {
+			status.DockerSocket = filepath.Join(profileDir, "docker.sock")
+		}
+	case "containerd":
+		status.DockerSocket = ""
+		if status.ContainerdSocket == "" {
+			status.ContainerdSocket = filepath.Join(profileDir, "containerd.sock")
+		}
+	}
+}
+
+// vmTypeFromDriver maps the driver name printed by colima to its vm-type flag
+func vmTypeFromDriver(driver string) string {
+	switch {
+	case strings.Contains(driver, "Virtualization.Framework"):
+		return "vz"
+	case strings.EqualFold(driver, "qemu"):
+		return "qemu"
+	case driver == "":
+		return ""
+	default:
+		return strings.ToLower(driver)
+	}
+}
@@ -0,0 +1,95 @@
+package colima
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// readTestdata returns the contents of testdata/status/<name>
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "status", name))
+	require.NoError(t, err)
+	return data
+}
+
+func TestStatusGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		args    string
+	}{
+		{name: "docker", profile: "default", args: "colima status --json"},
+		{name: "k8s", profile: "k8s", args: "colima status --json -p k8s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			homeDir := t.TempDir()
+			profileDir := filepath.Join(homeDir, ".colima", tt.profile)
+			require.NoError(t, os.MkdirAll(profileDir, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(profileDir, "colima.yaml"),
+				readTestdata(t, tt.name+".colima.yaml"), 0644))
+
+			repo := &ColimaRepository{
+				homeDir: homeDir,
+				log:     logger.GetLogger(),
+				exec: &mockExecutor{commands: map[string]mockOutput{
+					"colima list --json": {output: readTestdata(t, tt.name+".list.json")},
+					tt.args:              {output: readTestdata(t, tt.name+".status.json")},
+				}},
+			}
+
+			status, err := repo.Status(context.Background(), tt.profile)
+			require.NoError(t, err)
+
+			got, err := json.MarshalIndent(status, "", "  ")
+			require.NoError(t, err)
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "status", tt.name+".golden.json")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, got, 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestStatusNotRunning(t *testing.T) {
+	homeDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".colima", "work"), 0755))
+
+	repo := &ColimaRepository{
+		homeDir: homeDir,
+		log:     logger.GetLogger(),
+		exec: &mockExecutor{commands: map[string]mockOutput{
+			"colima list --json": {output: readTestdata(t, "docker.list.json")},
+		}},
+	}
+
+	_, err := repo.Status(context.Background(), "work")
+	require.Error(t, err)
+	assert.IsType(t, &domain.ProfileNotStartedError{}, err)
+}
+
+func TestParseStatusJSONInvalid(t *testing.T) {
+	_, err := parseStatusJSON([]byte("colima is not running"))
+	assert.Error(t, err)
+
+	_, err = parseStatusJSON([]byte("{not json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package colima
+
+import "os"
+
+// diskUsage returns the size of a file
+func diskUsage(info os.FileInfo) int64 {
+	return info.Size()
+}
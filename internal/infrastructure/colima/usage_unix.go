@@ -0,0 +1,17 @@
+//go:build unix
+
+package colima
+
+import (
+	"os"
+	"syscall"
+)
+
+// diskUsage returns the space allocated to a file, which for sparse VM disk
+// images is far less than their size
+func diskUsage(info os.FileInfo) int64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int64(stat.Blocks) * 512
+	}
+	return info.Size()
+}
@@ -0,0 +1,385 @@
+// Package kubeconfig merges profile kubeconfigs into a shared kubeconfig file
+// such as ~/.kube/config and prunes them from it
+package kubeconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/fsutil"
+	"gopkg.in/yaml.v3"
+)
+
+// backupTimeFormat names backups, e.g. config.20240501T100000.bak
+const backupTimeFormat = "20060102T150405"
+
+// maxBackups is how many backups are kept; older ones are removed after
+// each change
+const maxBackups = 5
+
+// sections are the lists of named entries a merge touches, with the key
+// holding each entry's body
+var sections = []struct{ list, body string }{
+	{"clusters", "cluster"},
+	{"users", "user"},
+	{"contexts", "context"},
+}
+
+// DefaultPath returns ~/.kube/config
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// File edits one kubeconfig file. Every change is written through a rename
+// after copying the previous content to a timestamped backup, of which the
+// newest maxBackups are kept, while holding the <path>.lock file kubectl uses
+// for the same purpose. Entries other than
+// the merged ones are kept as they are, though the first write re-indents
+// the file.
+type File struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+func NewFile(path string) *File {
+	return &File{path: path, now: time.Now}
+}
+
+// NewFileFromConfig returns the File of kubeconfig.merge_path, or of
+// ~/.kube/config when that is not set
+func NewFileFromConfig(cfg config.KubeConfigConfig) (*File, error) {
+	path := cfg.MergePath
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return NewFile(path), nil
+}
+
+// Path returns the file merged into
+func (f *File) Path() string {
+	return f.path
+}
+
+// Merge adds the current context of kubeconfig, together with its cluster
+// and user, under name. Entries already called name are replaced, and the
+// context becomes current when the file has no current context.
+func (f *File) Merge(name string, kubeconfig []byte) (*domain.KubeConfigMerge, error) {
+	entries, err := extractCurrent(name, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return f.edit(name, func(root *yaml.Node) {
+		for i, section := range sections {
+			list := sequenceValue(root, section.list)
+			removeNamed(list, name)
+			list.Content = append(list.Content, entries[i])
+		}
+		if current := mappingValue(root, "current-context"); current == nil || current.Value == "" {
+			setMappingValue(root, "current-context", scalar(name))
+		}
+	})
+}
+
+// Prune removes the cluster, user and context called name, and clears the
+// current context if it was name
+func (f *File) Prune(name string) (*domain.KubeConfigMerge, error) {
+	return f.edit(name, func(root *yaml.Node) {
+		for _, section := range sections {
+			if list := mappingValue(root, section.list); list != nil && list.Kind == yaml.SequenceNode {
+				removeNamed(list, name)
+			}
+		}
+		if current := mappingValue(root, "current-context"); current != nil && current.Value == name {
+			current.Value = ""
+		}
+	})
+}
+
+// edit applies change to the file under its lock and writes it back if that
+// changed anything
+func (f *File) edit(name string, change func(root *yaml.Node)) (*domain.KubeConfigMerge, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result := &domain.KubeConfigMerge{Path: f.path, Context: name}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return nil, f.error("failed to create directory: %v", err)
+	}
+	unlock, err := lockFile(f.path + ".lock")
+	if err != nil {
+		return nil, f.error("%v", err)
+	}
+	defer unlock()
+
+	original, mode, err := readFile(f.path)
+	if err != nil {
+		return nil, f.error("%v", err)
+	}
+	doc, err := parseTarget(original)
+	if err != nil {
+		return nil, f.error("%v", err)
+	}
+	change(doc.Content[0])
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, f.error("failed to encode: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, f.error("failed to encode: %v", err)
+	}
+	if bytes.Equal(buf.Bytes(), original) {
+		return result, nil
+	}
+
+	if original != nil {
+		result.Backup = fmt.Sprintf("%s.%s.bak", f.path, f.now().Format(backupTimeFormat))
+		if err := fsutil.WriteFileAtomic(result.Backup, original, mode); err != nil {
+			return nil, f.error("failed to write backup: %v", err)
+		}
+		if err := f.pruneBackups(); err != nil {
+			return nil, f.error("failed to remove old backups: %v", err)
+		}
+	}
+	if err := fsutil.WriteFileAtomic(f.path, buf.Bytes(), mode); err != nil {
+		return nil, f.error("failed to write: %v", err)
+	}
+	result.Changed = true
+	return result, nil
+}
+
+// pruneBackups removes all but the newest maxBackups backups. Timestamps
+// sort the same as the times they stand for, so names order the backups.
+func (f *File) pruneBackups() error {
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return err
+	}
+	prefix := filepath.Base(f.path) + "."
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".bak") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".bak")
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+	for len(backups) > maxBackups {
+		if err := os.Remove(filepath.Join(filepath.Dir(f.path), backups[0])); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+func (f *File) error(format string, args ...interface{}) error {
+	return &domain.KubeConfigError{Path: f.path, Reason: fmt.Sprintf(format, args...)}
+}
+
+// extractCurrent returns the cluster, user and context entries of the
+// current context of kubeconfig, all renamed to name
+func extractCurrent(name string, kubeconfig []byte) ([3]*yaml.Node, error) {
+	var entries [3]*yaml.Node
+	var doc yaml.Node
+	if err := yaml.Unmarshal(kubeconfig, &doc); err != nil {
+		return entries, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return entries, errors.New("invalid kubeconfig: not a mapping")
+	}
+	root := doc.Content[0]
+
+	contextName := ""
+	if current := mappingValue(root, "current-context"); current != nil {
+		contextName = current.Value
+	}
+	context := findNamed(mappingValue(root, "contexts"), contextName)
+	if context == nil {
+		return entries, fmt.Errorf("invalid kubeconfig: context %q not found", contextName)
+	}
+	contextBody := mappingValue(context, "context")
+	if contextBody == nil || contextBody.Kind != yaml.MappingNode {
+		return entries, fmt.Errorf("invalid kubeconfig: context %q has no body", contextName)
+	}
+
+	refs := map[string]string{}
+	for _, key := range []string{"cluster", "user"} {
+		ref := mappingValue(contextBody, key)
+		if ref == nil || ref.Value == "" {
+			return entries, fmt.Errorf("invalid kubeconfig: context %q names no %s", contextName, key)
+		}
+		refs[key] = ref.Value
+	}
+
+	for i, section := range sections {
+		var body *yaml.Node
+		if section.body == "context" {
+			body = contextBody
+		} else {
+			entry := findNamed(mappingValue(root, section.list), refs[section.body])
+			if entry != nil {
+				body = mappingValue(entry, section.body)
+			}
+			if body == nil {
+				return entries, fmt.Errorf("invalid kubeconfig: %s %q not found", section.body, refs[section.body])
+			}
+		}
+		body = copyNode(body)
+		if section.body == "context" {
+			setMappingValue(body, "cluster", scalar(name))
+			setMappingValue(body, "user", scalar(name))
+		}
+		entries[i] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+			scalar("name"), scalar(name),
+			scalar(section.body), body,
+		}}
+	}
+	return entries, nil
+}
+
+// parseTarget parses the file being merged into; an empty file is a new,
+// empty kubeconfig
+func parseTarget(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{
+			Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+				scalar("apiVersion"), scalar("v1"),
+				scalar("kind"), scalar("Config"),
+			},
+		}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("invalid kubeconfig: not a mapping")
+	}
+	return &doc, nil
+}
+
+// readFile returns the content and mode of path; a missing file is empty
+// and gets mode 0600
+func readFile(path string) ([]byte, os.FileMode, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, 0o600, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := os.ReadFile(path)
+	return data, info.Mode().Perm(), err
+}
+
+// lockFile creates path exclusively, the way kubectl locks a kubeconfig, and
+// returns the function removing it
+func lockFile(path string) (func(), error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, fmt.Errorf("locked by another process; remove %s if none is running", path)
+		}
+		return nil, err
+	}
+	file.Close()
+	return func() { os.Remove(path) }, nil
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// mappingValue returns the value of key in mapping m, or nil
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets key in mapping m to value, appending it if missing
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, scalar(key), value)
+}
+
+// sequenceValue returns the sequence under key in m, replacing a missing or
+// null value with an empty sequence
+func sequenceValue(m *yaml.Node, key string) *yaml.Node {
+	value := mappingValue(m, key)
+	if value == nil || value.Kind != yaml.SequenceNode {
+		value = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		setMappingValue(m, key, value)
+	}
+	return value
+}
+
+// findNamed returns the entry of list whose name is name, or nil
+func findNamed(list *yaml.Node, name string) *yaml.Node {
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, entry := range list.Content {
+		if n := mappingValue(entry, "name"); n != nil && n.Value == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+// removeNamed drops the entries of list whose name is name
+func removeNamed(list *yaml.Node, name string) {
+	kept := list.Content[:0]
+	for _, entry := range list.Content {
+		if n := mappingValue(entry, "name"); n != nil && n.Value == name {
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	list.Content = kept
+}
+
+// copyNode returns a deep copy of n
+func copyNode(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
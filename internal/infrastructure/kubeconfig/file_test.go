@@ -0,0 +1,261 @@
+package kubeconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// profileKubeConfig is the kubeconfig colima writes for a k3s profile
+const profileKubeConfig = `apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: Q0EK
+    server: https://127.0.0.1:6443
+  name: colima
+contexts:
+- context:
+    cluster: colima
+    namespace: apps
+    user: colima
+  name: colima
+current-context: colima
+kind: Config
+preferences: {}
+users:
+- name: colima
+  user:
+    client-certificate-data: Q0VSVAo=
+    client-key-data: S0VZCg==
+`
+
+// existingKubeConfig belongs to the user and must survive merges untouched
+const existingKubeConfig = `apiVersion: v1
+clusters:
+- cluster:
+    server: https://prod.example.com
+  name: prod
+contexts:
+- context:
+    cluster: prod
+    user: admin
+  name: prod
+current-context: prod
+kind: Config
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+type kubeConfigFile struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server string `yaml:"server"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+func readKubeConfig(t *testing.T, path string) kubeConfigFile {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	var config kubeConfigFile
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Failed to parse %s: %v", path, err)
+	}
+	return config
+}
+
+func newTestFile(t *testing.T, content string) *File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".kube", "config")
+	if content != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write kubeconfig: %v", err)
+		}
+	}
+	file := NewFile(path)
+	file.now = func() time.Time { return time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC) }
+	return file
+}
+
+func TestBackupsAreBounded(t *testing.T) {
+	file := newTestFile(t, existingKubeConfig)
+	clock := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	file.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	own := file.Path() + ".mine.bak"
+	if err := os.WriteFile(own, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var last string
+	for i := 0; i < maxBackups+3; i++ {
+		if merge, err := file.Merge("colima-work", []byte(profileKubeConfig)); err != nil || !merge.Changed {
+			t.Fatalf("Expected the merge to change the file, got %+v (%v)", merge, err)
+		}
+		pruned, err := file.Prune("colima-work")
+		if err != nil || !pruned.Changed {
+			t.Fatalf("Expected the prune to change the file, got %+v (%v)", pruned, err)
+		}
+		last = pruned.Backup
+	}
+
+	backups, err := filepath.Glob(file.Path() + ".2024*.bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != maxBackups || backups[len(backups)-1] != last {
+		t.Errorf("Expected the %d newest backups ending with %s, got %v", maxBackups, last, backups)
+	}
+	if _, err := os.Stat(own); err != nil {
+		t.Errorf("Expected backups not named by timestamp to be kept: %v", err)
+	}
+}
+
+func TestMergeIntoExisting(t *testing.T) {
+	file := newTestFile(t, existingKubeConfig)
+
+	merge, err := file.Merge("colima-work", []byte(profileKubeConfig))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !merge.Changed || merge.Backup != file.Path()+".20240501T100000.bak" {
+		t.Errorf("Expected a change with a backup, got %+v", merge)
+	}
+	if backup, _ := os.ReadFile(merge.Backup); string(backup) != existingKubeConfig {
+		t.Errorf("Expected the backup to hold the previous file, got:\n%s", backup)
+	}
+
+	config := readKubeConfig(t, file.Path())
+	if config.CurrentContext != "prod" {
+		t.Errorf("Expected the current context to be kept, got %q", config.CurrentContext)
+	}
+	if len(config.Clusters) != 2 || config.Clusters[1].Name != "colima-work" ||
+		config.Clusters[1].Cluster.Server != "https://127.0.0.1:6443" {
+		t.Errorf("Expected the profile's cluster to be appended, got %+v", config.Clusters)
+	}
+	if len(config.Users) != 2 || config.Users[1].Name != "colima-work" {
+		t.Errorf("Expected the profile's user to be appended, got %+v", config.Users)
+	}
+	context := config.Contexts[len(config.Contexts)-1]
+	if context.Name != "colima-work" || context.Context.Cluster != "colima-work" ||
+		context.Context.User != "colima-work" || context.Context.Namespace != "apps" {
+		t.Errorf("Expected a colima-work context pointing at the renamed entries, got %+v", context)
+	}
+
+	// Merging again changes nothing and leaves no second backup
+	os.Remove(merge.Backup)
+	again, err := file.Merge("colima-work", []byte(profileKubeConfig))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if again.Changed || again.Backup != "" {
+		t.Errorf("Expected a repeated merge to change nothing, got %+v", again)
+	}
+	if len(readKubeConfig(t, file.Path()).Clusters) != 2 {
+		t.Error("Expected a repeated merge to replace the entries, not duplicate them")
+	}
+
+	pruned, err := file.Prune("colima-work")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !pruned.Changed {
+		t.Errorf("Expected the prune to change the file, got %+v", pruned)
+	}
+	// The file is re-indented, but holds what it held before the merge
+	var want, got interface{}
+	yaml.Unmarshal([]byte(existingKubeConfig), &want)
+	data, _ := os.ReadFile(file.Path())
+	yaml.Unmarshal(data, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the prune to restore the original entries, got:\n%s", data)
+	}
+}
+
+func TestMergeIntoMissing(t *testing.T) {
+	file := newTestFile(t, "")
+
+	merge, err := file.Merge("colima-default", []byte(profileKubeConfig))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !merge.Changed || merge.Backup != "" {
+		t.Errorf("Expected a new file without a backup, got %+v", merge)
+	}
+	info, err := os.Stat(file.Path())
+	if err != nil {
+		t.Fatalf("Expected the file to be created: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %o", info.Mode().Perm())
+	}
+	if config := readKubeConfig(t, file.Path()); config.CurrentContext != "colima-default" {
+		t.Errorf("Expected the merged context to become current, got %q", config.CurrentContext)
+	}
+
+	if _, err := file.Prune("colima-default"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config := readKubeConfig(t, file.Path()); config.CurrentContext != "" || len(config.Contexts) != 0 {
+		t.Errorf("Expected the prune to clear the current context, got %+v", config)
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	file := newTestFile(t, existingKubeConfig)
+
+	if _, err := file.Merge("colima-work", []byte("current-context: missing\n")); err == nil ||
+		!strings.Contains(err.Error(), `context "missing" not found`) {
+		t.Errorf("Expected an invalid source kubeconfig to be rejected, got %v", err)
+	}
+
+	if err := os.WriteFile(file.Path()+".lock", nil, 0o600); err != nil {
+		t.Fatalf("Failed to write lock: %v", err)
+	}
+	_, err := file.Merge("colima-work", []byte(profileKubeConfig))
+	var kubeErr *domain.KubeConfigError
+	if !errors.As(err, &kubeErr) || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("Expected a held lock to fail the merge, got %v", err)
+	}
+	os.Remove(file.Path() + ".lock")
+
+	if err := os.WriteFile(file.Path(), []byte("- not\n- a mapping\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	if _, err := file.Prune("colima-work"); !errors.As(err, &kubeErr) {
+		t.Errorf("Expected KubeConfigError for an unreadable file, got %v", err)
+	}
+	if _, err := os.Stat(file.Path() + ".lock"); !os.IsNotExist(err) {
+		t.Error("Expected the lock to be released")
+	}
+}
@@ -0,0 +1,332 @@
+// Package kubernetes probes the readiness of a profile's Kubernetes cluster
+// through its API server
+package kubernetes
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTimeout bounds a check when no timeout is configured
+const DefaultTimeout = 10 * time.Second
+
+const (
+	// maxReadyzBody caps the /readyz body kept as the check's detail
+	maxReadyzBody = 64 << 10
+	// maxNodesBody caps the node list read from the API server
+	maxNodesBody = 8 << 20
+)
+
+// Checker asks the API server of a kubeconfig's current context whether it
+// is ready (/readyz) and which of its nodes are Ready
+type Checker struct {
+	timeout time.Duration
+	now     func() time.Time
+}
+
+// NewChecker returns a Checker whose checks give up after timeout, or after
+// DefaultTimeout when timeout is zero
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout, now: time.Now}
+}
+
+// Check probes the cluster kubeconfig points at. Only a kubeconfig that
+// cannot be used fails the check; an unreachable or unready cluster is
+// reported in the result.
+func (c *Checker) Check(ctx context.Context, kubeconfig []byte) (*domain.KubernetesHealth, error) {
+	api, err := newAPIClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	defer api.close()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	health := &domain.KubernetesHealth{
+		Server:    api.server,
+		Nodes:     []domain.KubernetesNode{},
+		CheckedAt: c.now(),
+	}
+
+	health.APIServer = c.readyz(ctx, api)
+	if !health.APIServer.Ready {
+		health.Reason = "API server is not ready"
+		return health, nil
+	}
+
+	nodes, err := c.nodes(ctx, api)
+	if err != nil {
+		health.Reason = fmt.Sprintf("failed to list nodes: %v", err)
+		return health, nil
+	}
+	health.Nodes = nodes
+
+	switch notReady := notReadyNodes(nodes); {
+	case len(nodes) == 0:
+		health.Reason = "no nodes are registered"
+	case len(notReady) > 0:
+		health.Reason = fmt.Sprintf("nodes not ready: %s", strings.Join(notReady, ", "))
+	default:
+		health.Ready = true
+	}
+	return health, nil
+}
+
+// readyz calls /readyz, which answers 200 "ok" once every readiness check of
+// the API server passes and lists the failing checks otherwise
+func (c *Checker) readyz(ctx context.Context, api *apiClient) domain.KubernetesAPICheck {
+	resp, err := api.get(ctx, "/readyz")
+	if err != nil {
+		return domain.KubernetesAPICheck{Detail: c.requestError(ctx, err).Error()}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReadyzBody))
+	if err != nil {
+		return domain.KubernetesAPICheck{StatusCode: resp.StatusCode, Detail: c.requestError(ctx, err).Error()}
+	}
+	return domain.KubernetesAPICheck{
+		Ready:      resp.StatusCode == http.StatusOK,
+		StatusCode: resp.StatusCode,
+		Detail:     strings.TrimSpace(string(body)),
+	}
+}
+
+// nodeList is the part of a v1 NodeList the check reads
+type nodeList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Conditions []struct {
+				Type    string `json:"type"`
+				Status  string `json:"status"`
+				Reason  string `json:"reason"`
+				Message string `json:"message"`
+			} `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// nodes lists the nodes with their Ready condition; a node that does not
+// report one is not ready
+func (c *Checker) nodes(ctx context.Context, api *apiClient) ([]domain.KubernetesNode, error) {
+	resp, err := api.get(ctx, "/api/v1/nodes")
+	if err != nil {
+		return nil, c.requestError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxReadyzBody))
+		return nil, fmt.Errorf("API server answered %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var list nodeList
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxNodesBody)).Decode(&list); err != nil {
+		return nil, c.requestError(ctx, fmt.Errorf("invalid node list: %w", err))
+	}
+
+	nodes := make([]domain.KubernetesNode, 0, len(list.Items))
+	for _, item := range list.Items {
+		node := domain.KubernetesNode{Name: item.Metadata.Name, Reason: "NoReadyCondition"}
+		for _, condition := range item.Status.Conditions {
+			if condition.Type != "Ready" {
+				continue
+			}
+			node.Ready = condition.Status == "True"
+			node.Reason, node.Message = condition.Reason, condition.Message
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// requestError names the timeout when the check ran out of time
+func (c *Checker) requestError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", c.timeout)
+	}
+	return err
+}
+
+func notReadyNodes(nodes []domain.KubernetesNode) []string {
+	var names []string
+	for _, node := range nodes {
+		if !node.Ready {
+			names = append(names, node.Name)
+		}
+	}
+	return names
+}
+
+// kubeConfig is the part of a kubeconfig needed to reach its current
+// context's API server
+type kubeConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+			TLSServerName            string `yaml:"tls-server-name"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+			Username              string `yaml:"username"`
+			Password              string `yaml:"password"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// apiClient sends authenticated requests to one API server
+type apiClient struct {
+	server   string
+	http     *http.Client
+	token    string
+	username string
+	password string
+}
+
+// newAPIClient builds the client of kubeconfig's current context. Files it
+// names must be absolute, since the kubeconfig's own location is unknown.
+func newAPIClient(kubeconfig []byte) (*apiClient, error) {
+	var config kubeConfig
+	if err := yaml.Unmarshal(kubeconfig, &config); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+
+	var clusterName, userName string
+	found := false
+	for _, ctx := range config.Contexts {
+		if ctx.Name == config.CurrentContext {
+			clusterName, userName, found = ctx.Context.Cluster, ctx.Context.User, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("invalid kubeconfig: context %q not found", config.CurrentContext)
+	}
+
+	api := &apiClient{}
+	tlsConfig := &tls.Config{}
+	found = false
+	for _, cluster := range config.Clusters {
+		if cluster.Name != clusterName {
+			continue
+		}
+		found = true
+		api.server = strings.TrimSuffix(cluster.Cluster.Server, "/")
+		tlsConfig.InsecureSkipVerify = cluster.Cluster.InsecureSkipTLSVerify
+		tlsConfig.ServerName = cluster.Cluster.TLSServerName
+		ca, err := dataOrFile(cluster.Cluster.CertificateAuthorityData, cluster.Cluster.CertificateAuthority)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig: certificate authority: %w", err)
+		}
+		if ca != nil {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+				return nil, errors.New("invalid kubeconfig: certificate authority holds no certificates")
+			}
+		}
+	}
+	if !found || api.server == "" {
+		return nil, fmt.Errorf("invalid kubeconfig: cluster %q has no server", clusterName)
+	}
+
+	for _, user := range config.Users {
+		if user.Name != userName {
+			continue
+		}
+		cert, err := dataOrFile(user.User.ClientCertificateData, user.User.ClientCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig: client certificate: %w", err)
+		}
+		key, err := dataOrFile(user.User.ClientKeyData, user.User.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig: client key: %w", err)
+		}
+		if cert != nil || key != nil {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid kubeconfig: client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{pair}
+		}
+		api.token = user.User.Token
+		if api.token == "" && user.User.TokenFile != "" {
+			token, err := os.ReadFile(user.User.TokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("invalid kubeconfig: token file: %w", err)
+			}
+			api.token = strings.TrimSpace(string(token))
+		}
+		api.username, api.password = user.User.Username, user.User.Password
+	}
+
+	api.http = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	return api, nil
+}
+
+// dataOrFile returns base64 encoded data, or else the content of path
+func dataOrFile(data, path string) ([]byte, error) {
+	switch {
+	case data != "":
+		return base64.StdEncoding.DecodeString(data)
+	case path != "":
+		return os.ReadFile(path)
+	}
+	return nil, nil
+}
+
+func (a *apiClient) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.server+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case a.token != "":
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	case a.username != "":
+		req.SetBasicAuth(a.username, a.password)
+	}
+	return a.http.Do(req)
+}
+
+func (a *apiClient) close() {
+	a.http.CloseIdleConnections()
+}
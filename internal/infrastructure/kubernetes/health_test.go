@@ -0,0 +1,220 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAPIServer answers /readyz and /api/v1/nodes the way a k3s API server
+// does, requiring the bearer token "secret"
+type fakeAPIServer struct {
+	readyz      string // body of /readyz; anything but "ok" fails it
+	nodes       string // body of /api/v1/nodes
+	delay       time.Duration
+	clientCerts bool // require a client certificate instead of the token
+}
+
+func (f *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(f.delay)
+	if f.clientCerts {
+		if len(r.TLS.PeerCertificates) == 0 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	} else if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/readyz":
+		if f.readyz != "ok" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprint(w, f.readyz)
+	case "/api/v1/nodes":
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, f.nodes)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func nodeListJSON(ready ...string) string {
+	items := make([]string, len(ready))
+	for i, status := range ready {
+		items[i] = fmt.Sprintf(`{"metadata":{"name":"node-%d"},"status":{"conditions":[`+
+			`{"type":"MemoryPressure","status":"False"},`+
+			`{"type":"Ready","status":%q,"reason":"KubeletReady","message":"kubelet is posting ready status"}]}}`, i, status)
+	}
+	return `{"kind":"NodeList","apiVersion":"v1","items":[` + strings.Join(items, ",") + `]}`
+}
+
+// startAPIServer starts fake and returns a kubeconfig for it, authenticating
+// with user
+func startAPIServer(t *testing.T, fake *fakeAPIServer, user string) string {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(fake)
+	if fake.clientCerts {
+		srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: colima
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+contexts:
+- name: colima
+  context:
+    cluster: colima
+    user: colima
+current-context: colima
+users:
+- name: colima
+  user:
+%s`, srv.URL, base64.StdEncoding.EncodeToString(ca), user)
+}
+
+const tokenUser = "    token: secret\n"
+
+// clientCertUser returns a kubeconfig user with a self-signed client
+// certificate, as k3s hands out
+func clientCertUser(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "system:admin"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return fmt.Sprintf("    client-certificate-data: %s\n    client-key-data: %s\n",
+		base64.StdEncoding.EncodeToString(cert), base64.StdEncoding.EncodeToString(keyPEM))
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		fake       *fakeAPIServer
+		user       string
+		ready      bool
+		apiReady   bool
+		nodes      int
+		wantReason string
+	}{
+		{"ready", &fakeAPIServer{readyz: "ok", nodes: nodeListJSON("True")}, tokenUser, true, true, 1, ""},
+		{"client certificate", &fakeAPIServer{readyz: "ok", nodes: nodeListJSON("True"), clientCerts: true}, "", true, true, 1, ""},
+		{"api server not ready", &fakeAPIServer{readyz: "[-]etcd failed: reason withheld\nreadyz check failed"},
+			tokenUser, false, false, 0, "API server is not ready"},
+		{"node not ready", &fakeAPIServer{readyz: "ok", nodes: nodeListJSON("True", "False")},
+			tokenUser, false, true, 2, "nodes not ready: node-1"},
+		{"no nodes", &fakeAPIServer{readyz: "ok", nodes: nodeListJSON()}, tokenUser, false, true, 0, "no nodes are registered"},
+		{"unauthorized", &fakeAPIServer{readyz: "ok"}, "    token: wrong\n", false, false, 0, "API server is not ready"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := tt.user
+			if tt.fake.clientCerts {
+				user = clientCertUser(t)
+			}
+			kubeconfig := startAPIServer(t, tt.fake, user)
+
+			health, err := NewChecker(time.Second).Check(context.Background(), []byte(kubeconfig))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if health.Ready != tt.ready || health.APIServer.Ready != tt.apiReady || len(health.Nodes) != tt.nodes {
+				t.Errorf("Expected ready=%v, api_server.ready=%v and %d nodes, got %+v",
+					tt.ready, tt.apiReady, tt.nodes, health)
+			}
+			if health.Reason != tt.wantReason {
+				t.Errorf("Expected reason %q, got %q", tt.wantReason, health.Reason)
+			}
+		})
+	}
+}
+
+func TestCheckReportsFailedReadyz(t *testing.T) {
+	kubeconfig := startAPIServer(t, &fakeAPIServer{readyz: "[-]etcd failed: reason withheld\nreadyz check failed"}, tokenUser)
+
+	health, err := NewChecker(time.Second).Check(context.Background(), []byte(kubeconfig))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if health.APIServer.StatusCode != http.StatusInternalServerError || !strings.Contains(health.APIServer.Detail, "[-]etcd failed") {
+		t.Errorf("Expected the failed checks of /readyz, got %+v", health.APIServer)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	kubeconfig := startAPIServer(t, &fakeAPIServer{readyz: "ok", delay: 500 * time.Millisecond}, tokenUser)
+
+	health, err := NewChecker(50*time.Millisecond).Check(context.Background(), []byte(kubeconfig))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if health.Ready || health.APIServer.Detail != "timed out after 50ms" {
+		t.Errorf("Expected the check to time out, got %+v", health.APIServer)
+	}
+}
+
+func TestCheckUnreachable(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	srv.Close()
+	kubeconfig := "current-context: colima\ncontexts:\n- name: colima\n  context:\n    cluster: colima\n" +
+		"clusters:\n- name: colima\n  cluster:\n    server: " + srv.URL + "\n    insecure-skip-tls-verify: true\n"
+
+	health, err := NewChecker(time.Second).Check(context.Background(), []byte(kubeconfig))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if health.Ready || health.APIServer.StatusCode != 0 || health.APIServer.Detail == "" {
+		t.Errorf("Expected an unreachable API server, got %+v", health.APIServer)
+	}
+}
+
+func TestCheckInvalidKubeConfig(t *testing.T) {
+	for name, kubeconfig := range map[string]string{
+		"not yaml":        "{",
+		"missing context": "current-context: colima\n",
+		"missing server":  "current-context: colima\ncontexts:\n- name: colima\n  context:\n    cluster: colima\n",
+	} {
+		if _, err := NewChecker(time.Second).Check(context.Background(), []byte(kubeconfig)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+// Package cli implements the colima-manager command line. `serve` runs the
+// daemon; every other command talks to a running daemon and falls back to
+// executing the use case in-process when no daemon holds the PID file.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/app"
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/daemon"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+	"github.com/gqadonis/colima-manager/internal/pkg/metrics"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/gqadonis/colima-manager/pkg/client"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK                 = 0
+	ExitError              = 1
+	ExitUsage              = 2
+	ExitProfileNotFound    = 3
+	ExitProfileNotStarted  = 4
+	ExitProfileBusy        = 5
+	ExitProfileUnreachable = 6
+	ExitProfileMalfunction = 7
+	ExitDependency         = 8
+	ExitTimeout            = 9
+	ExitCanceled           = 10
+	ExitDockerContext      = 11
+	ExitJobNotFound        = 12
+	ExitUnauthorized       = 13
+	ExitDaemonNotRunning   = 14
+	ExitInvalidConfig      = 15
+	ExitCleanNotConfirmed  = 16
+)
+
+// pingTimeout bounds how long we look for a running daemon
+const pingTimeout = 2 * time.Second
+
+// ServeFunc runs the daemon with the loaded configuration
+type ServeFunc func(cfg *config.Config)
+
+// App is the command line application
+type App struct {
+	serve  ServeFunc
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	// newLocal builds the use case used when no daemon is running
+	newLocal func(cfg *config.Config) (usecase.ColimaUseCaseInterface, error)
+}
+
+func NewApp(serve ServeFunc) *App {
+	return &App{
+		serve:    serve,
+		stdin:    os.Stdin,
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
+		newLocal: newLocalUseCase,
+	}
+}
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(a *App, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "[-c config] [-d] [-a] [-h host]", "Run the manager daemon", (*App).runServe},
+		{"start", "[flags] [profile]", "Start a profile", (*App).runStart},
+		{"stop", "[flags] [profile | --daemon]", "Stop a profile, or the manager daemon", (*App).runStop},
+		{"status", "[flags] [profile]", "Show the status of a profile", (*App).runStatus},
+		{"kubeconfig", "[flags] [profile]", "Print the kubeconfig of a profile", (*App).runKubeConfig},
+		{"clean", "[flags] (profile | --all)", "Delete a profile, or all profiles", (*App).runClean},
+		{"profiles", "[flags]", "List declared, on-disk and colima profiles", (*App).runProfiles},
+		{"deps", "[flags] [--update]", "Check or update dependencies", (*App).runDeps},
+		{"logs", "[flags] [-f] job-id", "Print the output of a daemon job", (*App).runLogs},
+	}
+}
+
+// Run executes the command in args and returns the process exit code.
+// Without a command, or when args start with a flag, it runs serve so the
+// old `colima-manager -a -d` invocation keeps working.
+func (a *App) Run(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") && !isHelp(args[0]) {
+		return a.exit(a.runServe(args))
+	}
+	if isHelp(args[0]) || args[0] == "help" {
+		a.usage(a.stdout)
+		return ExitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return a.exit(cmd.run(a, args[1:]))
+		}
+	}
+	fmt.Fprintf(a.stderr, "Unknown command %q\n\n", args[0])
+	a.usage(a.stderr)
+	return ExitUsage
+}
+
+func (a *App) usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: colima-manager <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-11s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'colima-manager <command> --help' for the flags of a command.")
+}
+
+func isHelp(arg string) bool {
+	return arg == "--help" || arg == "-help"
+}
+
+// usageError marks errors caused by invalid command line arguments
+type usageError struct {
+	msg      string
+	reported bool // already printed by the flag package
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func (a *App) exit(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	var usageErr *usageError
+	if errors.As(err, &usageErr) && usageErr.reported {
+		return ExitUsage
+	}
+	fmt.Fprintf(a.stderr, "Error: %v\n", err)
+	return ExitCode(err)
+}
+
+// ExitCode maps err onto the exit code documented for it
+func ExitCode(err error) int {
+	var httpErr *client.HTTPError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, new(*usageError)):
+		return ExitUsage
+	case errors.As(err, new(*domain.ProfileNotFoundError)):
+		return ExitProfileNotFound
+	case errors.As(err, new(*domain.ProfileNotStartedError)):
+		return ExitProfileNotStarted
+	case errors.As(err, new(*domain.ProfileBusyError)):
+		return ExitProfileBusy
+	case errors.As(err, new(*domain.ProfileUnreachableError)):
+		return ExitProfileUnreachable
+	case errors.As(err, new(*domain.ProfileMalfunctionError)):
+		return ExitProfileMalfunction
+	case errors.As(err, new(*domain.DependencyError)):
+		return ExitDependency
+	case errors.As(err, new(*domain.OperationTimeoutError)):
+		return ExitTimeout
+	case errors.As(err, new(*domain.OperationCanceledError)), errors.Is(err, context.Canceled):
+		return ExitCanceled
+	case errors.As(err, new(*domain.DockerContextError)):
+		return ExitDockerContext
+	case errors.As(err, new(*domain.JobNotFoundError)):
+		return ExitJobNotFound
+	case errors.As(err, new(*domain.ValidationError)):
+		return ExitInvalidConfig
+	case errors.As(err, new(*domain.CleanNotConfirmedError)):
+		return ExitCleanNotConfirmed
+	case errors.As(err, &httpErr) && (httpErr.StatusCode == 401 || httpErr.StatusCode == 403):
+		return ExitUnauthorized
+	case errors.As(err, new(*daemon.NotRunningError)):
+		return ExitDaemonNotRunning
+	default:
+		return ExitError
+	}
+}
+
+// clientFlags are shared by every command that talks to the daemon
+type clientFlags struct {
+	configPath string
+	output     string
+	address    string
+	token      string
+	local      bool
+	verbose    bool
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.configPath, "c", "", "Path to config file")
+	fs.StringVar(&f.configPath, "config", "", "Path to config file")
+	fs.StringVar(&f.output, "o", formatTable, "Output format: json, table or yaml")
+	fs.StringVar(&f.output, "output", formatTable, "Output format: json, table or yaml")
+	fs.StringVar(&f.address, "address", os.Getenv("COLIMA_MANAGER_ADDRESS"), "Daemon address (unix:///path, tcp://host:port or URL)")
+	fs.StringVar(&f.token, "token", os.Getenv("COLIMA_MANAGER_TOKEN"), "Bearer token for the daemon API")
+	fs.BoolVar(&f.local, "local", false, "Run in-process instead of using the daemon")
+	fs.BoolVar(&f.verbose, "v", false, "Log to stderr")
+	fs.BoolVar(&f.verbose, "verbose", false, "Log to stderr")
+}
+
+// session holds what a client command needs once its flags are parsed
+type session struct {
+	cfg     *config.Config
+	flags   clientFlags
+	useCase usecase.ColimaUseCaseInterface
+	client  *client.Client // nil when running in-process
+}
+
+// newFlagSet returns a flag set for cmd whose errors go to a.stderr
+func (a *App) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	for _, cmd := range commands {
+		cmd := cmd
+		if cmd.name == name {
+			fs.Usage = func() {
+				fmt.Fprintf(a.stderr, "Usage: colima-manager %s %s\n\n%s\n\nFlags:\n", cmd.name, cmd.usage, cmd.summary)
+				fs.PrintDefaults()
+			}
+		}
+	}
+	return fs
+}
+
+// parse parses args and returns at most maxArgs positional arguments. Flags
+// may follow positional arguments.
+func parse(fs *flag.FlagSet, args []string, maxArgs int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, &usageError{msg: err.Error(), reported: true}
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) > maxArgs {
+		return nil, &usageError{msg: fmt.Sprintf("unexpected arguments: %s", strings.Join(positional[maxArgs:], " "))}
+	}
+	return positional, nil
+}
+
+// optionalBool is a boolean flag that leaves its switch nil unless given, so
+// that --kubernetes=false overrides the profile and the defaults
+type optionalBool struct {
+	value **bool
+}
+
+func (b optionalBool) String() string {
+	if b.value == nil || *b.value == nil {
+		return ""
+	}
+	return strconv.FormatBool(**b.value)
+}
+
+func (b optionalBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b.value = &v
+	return nil
+}
+
+// IsBoolFlag lets the flag be given without a value
+func (b optionalBool) IsBoolFlag() bool {
+	return true
+}
+
+// setup validates the shared flags and keeps logs off stdout
+func (a *App) setup(flags clientFlags) error {
+	if !validFormat(flags.output) {
+		return &usageError{msg: fmt.Sprintf("unknown output format %q", flags.output)}
+	}
+
+	var console io.Writer = io.Discard
+	if flags.verbose {
+		console = a.stderr
+	}
+	logger.SetConsoleOutput(console)
+	return nil
+}
+
+// connect loads the config and picks the daemon or the in-process use case
+func (a *App) connect(ctx context.Context, flags clientFlags) (*session, error) {
+	if err := a.setup(flags); err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.LoadConfig(config.Flags{ConfigPath: flags.configPath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	// Client commands log to the console only; the log output belongs to the daemon
+	if err := logger.Configure(logger.Options{Level: cfg.Log.Level, Format: cfg.Log.Format}); err != nil {
+		return nil, fmt.Errorf("invalid log configuration: %w", err)
+	}
+	s := &session{cfg: cfg, flags: flags}
+
+	if !flags.local {
+		address := flags.address
+		if address == "" {
+			address = daemonAddress(cfg)
+		}
+		c, err := client.New(address, client.WithToken(flags.token))
+		if err != nil {
+			return nil, &usageError{msg: err.Error()}
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		defer cancel()
+		err = c.Ping(pingCtx)
+		if err == nil {
+			s.client, s.useCase = c, c
+			return s, nil
+		}
+		// Running in-process next to a live daemon would bypass its profile
+		// locks, auth and audit log
+		if pid, runErr := daemon.Running(cfg.Server.PIDFile); runErr == nil {
+			return nil, fmt.Errorf("colima-manager is running (PID %d) but not reachable at %s: %w; use --local to run in-process anyway",
+				pid, address, err)
+		}
+		if flags.verbose {
+			fmt.Fprintf(a.stderr, "No daemon at %s, running in-process\n", address)
+		}
+	}
+
+	if s.useCase, err = a.newLocal(cfg); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// daemonAddress prefers the configured unix socket, then the first listen
+// address, then host:port
+func daemonAddress(cfg *config.Config) string {
+	for _, address := range cfg.Server.Listen {
+		if strings.HasPrefix(address, "unix://") {
+			return address
+		}
+	}
+	if len(cfg.Server.Listen) > 0 {
+		return cfg.Server.Listen[0]
+	}
+	return fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+}
+
+// newLocalUseCase builds the use case the way the daemon does, so local
+// operations are audited and prune kubeconfigs alike
+func newLocalUseCase(cfg *config.Config) (usecase.ColimaUseCaseInterface, error) {
+	useCases, err := app.NewUseCases(cfg, nil, metrics.NewRegistry())
+	if err != nil {
+		return nil, err
+	}
+	return useCases.Colima, nil
+}
+
+// signalContext is canceled on SIGINT or SIGTERM. Operations run in-process
+// are audited as the cli caller.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx := domain.WithCaller(context.Background(), domain.Caller{Name: "cli"})
+	return signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+}
@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/daemon"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/gqadonis/colima-manager/pkg/client"
+)
+
+type mockUseCase struct {
+	usecase.ColimaUseCaseInterface
+	status   *domain.ColimaStatus
+	profiles []domain.ProfileInfo
+	err      error
+	started  *domain.ColimaConfig
+	cleaned  *domain.CleanRequest
+}
+
+func (m *mockUseCase) Status(ctx context.Context, profile string) (*domain.ColimaStatus, error) {
+	return m.status, m.err
+}
+
+func (m *mockUseCase) ListProfiles(ctx context.Context) ([]domain.ProfileInfo, error) {
+	return m.profiles, m.err
+}
+
+func (m *mockUseCase) Start(ctx context.Context, config domain.ColimaConfig) error {
+	m.started = &config
+	return m.err
+}
+
+func (m *mockUseCase) PlanClean(ctx context.Context, req domain.CleanRequest) (*domain.CleanPlan, error) {
+	return &domain.CleanPlan{Profiles: []string{req.Profile}, Token: "token"}, m.err
+}
+
+func (m *mockUseCase) Clean(ctx context.Context, req domain.CleanRequest) error {
+	m.cleaned = &req
+	return m.err
+}
+
+func newTestApp(local *mockUseCase) (*App, *bytes.Buffer, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+	app := &App{
+		serve:  func(*config.Config) {},
+		stdin:  strings.NewReader(""),
+		stdout: &stdout,
+		stderr: &stderr,
+		newLocal: func(*config.Config) (usecase.ColimaUseCaseInterface, error) {
+			return local, nil
+		},
+	}
+	return app, &stdout, &stderr
+}
+
+func TestStatusLocalJSON(t *testing.T) {
+	local := &mockUseCase{status: &domain.ColimaStatus{Status: domain.ProfileRunning, Profile: "work", CPUs: 4}}
+	app, stdout, stderr := newTestApp(local)
+
+	if code := app.Run([]string{"status", "work", "--local", "-o", "json"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d (stderr: %s)", ExitOK, code, stderr)
+	}
+
+	var status domain.ColimaStatus
+	if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", stdout, err)
+	}
+	if status.Profile != "work" || status.CPUs != 4 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+}
+
+func TestProfilesYAML(t *testing.T) {
+	local := &mockUseCase{profiles: []domain.ProfileInfo{{Name: "default", State: domain.ProfileStateRunning, CPUs: 2}}}
+	app, stdout, _ := newTestApp(local)
+
+	if code := app.Run([]string{"profiles", "--local", "--output", "yaml"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if !strings.HasPrefix(stdout.String(), "- name: default\n  state: running\n") {
+		t.Errorf("Unexpected YAML output:\n%s", stdout)
+	}
+}
+
+func TestStartAndCleanArguments(t *testing.T) {
+	local := &mockUseCase{}
+	app, stdout, stderr := newTestApp(local)
+
+	if code := app.Run([]string{"start", "--local", "work", "--cpus", "6", "--kubernetes"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if local.started == nil || local.started.Profile != "work" || local.started.CPUs != 6 || !domain.Enabled(local.started.Kubernetes) {
+		t.Errorf("Unexpected start config: %+v", local.started)
+	}
+	if !strings.Contains(stdout.String(), "start of profile 'work' succeeded") {
+		t.Errorf("Unexpected output: %q", stdout)
+	}
+	if local.started.NetworkAddress != nil {
+		t.Errorf("Expected --network-address to stay unset, got %v", *local.started.NetworkAddress)
+	}
+
+	if code := app.Run([]string{"start", "--local", "work", "--kubernetes=false"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if local.started.Kubernetes == nil || *local.started.Kubernetes {
+		t.Errorf("Expected --kubernetes=false to turn Kubernetes off, got %+v", local.started)
+	}
+
+	if code := app.Run([]string{"clean", "--local"}); code != ExitUsage {
+		t.Errorf("Expected exit code %d without a profile, got %d", ExitUsage, code)
+	}
+	app.stdin = strings.NewReader("n\n")
+	if code := app.Run([]string{"clean", "--local", "--all"}); code != ExitCleanNotConfirmed {
+		t.Errorf("Expected exit code %d when the prompt is declined, got %d", ExitCleanNotConfirmed, code)
+	}
+	if code := app.Run([]string{"clean", "--local", "--all"}); code != ExitCleanNotConfirmed {
+		t.Errorf("Expected exit code %d without an answer, got %d", ExitCleanNotConfirmed, code)
+	}
+	if local.cleaned != nil {
+		t.Errorf("Expected no clean without confirmation, got %+v", local.cleaned)
+	}
+	if code := app.Run([]string{"clean", "--local", "work", "--preserve", "dev"}); code != ExitUsage {
+		t.Errorf("Expected exit code %d for --preserve without --all, got %d", ExitUsage, code)
+	}
+
+	stdout.Reset()
+	if code := app.Run([]string{"clean", "--local", "work", "--dry-run"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if local.cleaned != nil {
+		t.Errorf("Expected a dry run to clean nothing, got %+v", local.cleaned)
+	}
+	if !strings.Contains(stdout.String(), "TOKEN") || !strings.Contains(stdout.String(), "work") {
+		t.Errorf("Unexpected dry run output: %q", stdout)
+	}
+
+	stderr.Reset()
+	app.stdin = strings.NewReader("y\n")
+	if code := app.Run([]string{"clean", "--local", "--all", "--preserve", "dev,ci"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if !strings.Contains(stderr.String(), "Remove everything listed above?") {
+		t.Errorf("Expected the plan and a prompt on stderr, got %q", stderr)
+	}
+	if local.cleaned == nil || local.cleaned.Profile != "" || local.cleaned.Token != "token" ||
+		len(local.cleaned.Preserve) != 2 {
+		t.Errorf("Expected confirmed clean of all profiles, got %+v", local.cleaned)
+	}
+
+	if code := app.Run([]string{"clean", "--local", "work", "--confirm", "reviewed"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if local.cleaned.Profile != "work" || local.cleaned.Token != "reviewed" {
+		t.Errorf("Expected clean with the given token, got %+v", local.cleaned)
+	}
+}
+
+func TestDaemonErrorExitCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(domain.NewErrorDetail(&domain.ProfileNotFoundError{Profile: "ghost"}))
+	}))
+	defer srv.Close()
+
+	app, _, stderr := newTestApp(&mockUseCase{status: &domain.ColimaStatus{}})
+	if code := app.Run([]string{"status", "ghost", "--address", srv.URL}); code != ExitProfileNotFound {
+		t.Errorf("Expected exit code %d, got %d", ExitProfileNotFound, code)
+	}
+	if !strings.Contains(stderr.String(), "profile 'ghost' does not exist") {
+		t.Errorf("Unexpected error output: %q", stderr)
+	}
+}
+
+func TestFallbackWhenDaemonIsDown(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	address := srv.URL
+	srv.Close()
+
+	local := &mockUseCase{status: &domain.ColimaStatus{Status: domain.ProfileRunning, Profile: "default"}}
+	app, stdout, _ := newTestApp(local)
+	if code := app.Run([]string{"status", "--address", address}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if !strings.Contains(stdout.String(), "Running") {
+		t.Errorf("Expected the local status, got %q", stdout)
+	}
+}
+
+func TestServeIsDefault(t *testing.T) {
+	var served *config.Config
+	app, _, _ := newTestApp(nil)
+	app.serve = func(cfg *config.Config) { served = cfg }
+
+	if code := app.Run([]string{"-d", "-c", "does-not-exist.yaml"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+	if served == nil || !served.Server.Daemon {
+		t.Errorf("Expected serve to run in daemon mode, got %+v", served)
+	}
+
+	if code := app.Run([]string{"bogus"}); code != ExitUsage {
+		t.Errorf("Expected exit code %d for an unknown command, got %d", ExitUsage, code)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{&domain.ProfileBusyError{Profile: "p"}, ExitProfileBusy},
+		{&domain.ProfileNotStartedError{Profile: "p"}, ExitProfileNotStarted},
+		{&domain.OperationTimeoutError{Operation: "start"}, ExitTimeout},
+		{&domain.DependencyError{Dependency: "colima"}, ExitDependency},
+		{&client.HTTPError{StatusCode: http.StatusForbidden, Code: "forbidden"}, ExitUnauthorized},
+		{context.Canceled, ExitCanceled},
+		{&usageError{msg: "bad"}, ExitUsage},
+		{&domain.ValidationError{Profile: "p"}, ExitInvalidConfig},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestStopDaemonNotRunning(t *testing.T) {
+	app, _, stderr := newTestApp(nil)
+	pidFile := t.TempDir() + "/manager.pid"
+
+	if code := app.Run([]string{"stop", "--daemon", "--pid-file", pidFile}); code != ExitDaemonNotRunning {
+		t.Errorf("Expected exit code %d, got %d (stderr: %s)", ExitDaemonNotRunning, code, stderr)
+	}
+	if code := app.Run([]string{"stop", "--daemon", "work"}); code != ExitUsage {
+		t.Errorf("Expected exit code %d with a profile, got %d", ExitUsage, code)
+	}
+}
+
+func TestRunningDaemonUnreachable(t *testing.T) {
+	dir := t.TempDir()
+	pidFile, err := daemon.Acquire(filepath.Join(dir, "manager.pid"))
+	if err != nil {
+		t.Fatalf("Failed to acquire PID file: %v", err)
+	}
+	defer pidFile.Release()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("server:\n  pid_file: "+pidFile.Path()+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	local := &mockUseCase{}
+	app, _, stderr := newTestApp(local)
+	address := "unix://" + filepath.Join(dir, "missing.sock")
+	if code := app.Run([]string{"start", "work", "-c", configPath, "--address", address}); code != ExitError {
+		t.Errorf("Expected exit code %d, got %d (stderr: %s)", ExitError, code, stderr)
+	}
+	if local.started != nil {
+		t.Error("Expected no in-process start while a daemon is running")
+	}
+	if !strings.Contains(stderr.String(), address) {
+		t.Errorf("Expected the unreachable address in the error, got %q", stderr)
+	}
+
+	if code := app.Run([]string{"start", "work", "-c", configPath, "--address", address, "--local"}); code != ExitOK {
+		t.Errorf("Expected --local to run in-process, got exit code %d (stderr: %s)", code, stderr)
+	}
+}
+
+func TestServeLogOptions(t *testing.T) {
+	cfg := config.LogConfig{Level: "debug", Format: "json", Output: "stdout", MaxSizeMB: 5}
+
+	if got := serveLogOptions(cfg, false); got.Output != "stdout" || got.Level != "debug" || got.Rotation.MaxSize != 5<<20 {
+		t.Errorf("Unexpected options in the foreground: %+v", got)
+	}
+	if got := serveLogOptions(cfg, true); got.Output != config.DefaultDaemonLogFile {
+		t.Errorf("Expected a detached daemon to log to %s, got %s", config.DefaultDaemonLogFile, got.Output)
+	}
+
+	cfg.Output = "/var/log/colima-manager/manager.log"
+	if got := serveLogOptions(cfg, true); got.Output != cfg.Output {
+		t.Errorf("Expected the configured file to be kept, got %s", got.Output)
+	}
+}
@@ -0,0 +1,420 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/daemon"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+const (
+	// logsPollInterval is how often `logs -f` checks for new output
+	logsPollInterval = time.Second
+	// defaultDaemonStopTimeout is how long `stop --daemon` waits by default
+	defaultDaemonStopTimeout = 30 * time.Second
+)
+
+// operationResult is printed once start, stop or clean completes or is queued
+type operationResult struct {
+	Operation string `json:"operation"`
+	Profile   string `json:"profile,omitempty"`
+	State     string `json:"state"`
+	JobID     string `json:"job_id,omitempty"`
+}
+
+// daemonResult is printed once `stop --daemon` completes
+type daemonResult struct {
+	PID   int    `json:"pid"`
+	State string `json:"state"`
+}
+
+func (a *App) runServe(args []string) error {
+	var flags config.Flags
+	fs := a.newFlagSet("serve")
+	flags.Register(fs)
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(flags)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := logger.Configure(serveLogOptions(cfg.Log, daemon.IsDetached())); err != nil {
+		return fmt.Errorf("invalid log configuration: %w", err)
+	}
+	defer logger.Close()
+	a.serve(cfg)
+	return nil
+}
+
+// serveLogOptions converts the log config of the daemon. A detached daemon
+// has no standard streams, so it logs to DefaultDaemonLogFile instead.
+func serveLogOptions(cfg config.LogConfig, detached bool) logger.Options {
+	output := cfg.Output
+	switch output {
+	case "", logger.OutputStdout, logger.OutputStderr, logger.OutputJournald:
+		if detached {
+			output = config.DefaultDaemonLogFile
+		}
+	}
+	return logger.Options{
+		Level:  cfg.Level,
+		Format: cfg.Format,
+		Output: output,
+		Rotation: logger.Rotation{
+			MaxSize:    int64(cfg.MaxSizeMB) << 20,
+			Interval:   cfg.RotateInterval,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+		},
+	}
+}
+
+func (a *App) runStart(args []string) error {
+	var flags clientFlags
+	var req domain.ColimaConfig
+	var detach bool
+	fs := a.newFlagSet("start")
+	flags.register(fs)
+	fs.IntVar(&req.CPUs, "cpus", 0, "Number of CPUs")
+	fs.IntVar(&req.Memory, "memory", 0, "Memory in GiB")
+	fs.IntVar(&req.DiskSize, "disk", 0, "Disk size in GiB")
+	fs.StringVar(&req.VMType, "vm-type", "", "Virtual machine type (vz or qemu)")
+	fs.StringVar(&req.Runtime, "runtime", "", "Container runtime (docker or containerd)")
+	fs.Var(optionalBool{&req.NetworkAddress}, "network-address", "Assign a reachable IP address to the VM")
+	fs.Var(optionalBool{&req.Kubernetes}, "kubernetes", "Enable Kubernetes")
+	fs.BoolVar(&detach, "detach", false, "Return once the daemon has queued the job")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	req.Profile = profileArg(positional)
+
+	return a.operation("start", req.Profile, flags, detach, func(ctx context.Context, s *session) (*domain.Job, error) {
+		if detach && s.client != nil {
+			return s.client.SubmitStart(ctx, req)
+		}
+		return nil, s.useCase.Start(ctx, req)
+	})
+}
+
+func (a *App) runStop(args []string) error {
+	var flags clientFlags
+	var detach, stopDaemon bool
+	var pidFile string
+	var timeout time.Duration
+	fs := a.newFlagSet("stop")
+	flags.register(fs)
+	fs.BoolVar(&detach, "detach", false, "Return once the daemon has queued the job")
+	fs.BoolVar(&stopDaemon, "daemon", false, "Stop the manager daemon instead of a profile")
+	fs.StringVar(&pidFile, "pid-file", "", "PID file of the daemon to stop (with --daemon)")
+	fs.DurationVar(&timeout, "timeout", defaultDaemonStopTimeout, "How long to wait for the daemon to exit (with --daemon)")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	profile := profileArg(positional)
+
+	if stopDaemon {
+		if profile != "" || detach {
+			return &usageError{msg: "--daemon takes no profile and cannot be detached"}
+		}
+		return a.stopDaemon(flags, pidFile, timeout)
+	}
+
+	return a.operation("stop", profile, flags, detach, func(ctx context.Context, s *session) (*domain.Job, error) {
+		if detach && s.client != nil {
+			return s.client.SubmitStop(ctx, profile)
+		}
+		return nil, s.useCase.Stop(ctx, profile)
+	})
+}
+
+// stopDaemon signals the daemon named by the PID file and waits for it to exit
+func (a *App) stopDaemon(flags clientFlags, pidFile string, timeout time.Duration) error {
+	if err := a.setup(flags); err != nil {
+		return err
+	}
+	cfg, err := config.LoadConfig(config.Flags{ConfigPath: flags.configPath, PIDFile: pidFile})
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	pid, err := daemon.Stop(cfg.Server.PIDFile, timeout)
+	if err != nil {
+		return err
+	}
+	return a.render(flags.output, daemonResult{PID: pid, State: "stopped"})
+}
+
+func (a *App) runClean(args []string) error {
+	var flags clientFlags
+	var all, detach, dryRun, preserveConfig bool
+	var confirm, preserve string
+	fs := a.newFlagSet("clean")
+	flags.register(fs)
+	fs.BoolVar(&all, "all", false, "Delete every profile")
+	fs.BoolVar(&detach, "detach", false, "Return once the daemon has queued the job")
+	fs.BoolVar(&dryRun, "dry-run", false, "Print what would be removed, and the token confirming it")
+	fs.StringVar(&confirm, "confirm", "", "Token of a reviewed dry run; without it the plan is shown for confirmation")
+	fs.StringVar(&preserve, "preserve", "", "Comma-separated profiles to keep (with --all)")
+	fs.BoolVar(&preserveConfig, "preserve-config", false, "Keep the colima.yaml of each removed profile")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if all == (len(positional) == 1) {
+		return &usageError{msg: "clean needs either a profile or --all"}
+	}
+	req := domain.CleanRequest{PreserveConfig: preserveConfig, Token: confirm}
+	if !all {
+		req.Profile = positional[0]
+	}
+	if preserve != "" {
+		if !all {
+			return &usageError{msg: "--preserve needs --all"}
+		}
+		req.Preserve = strings.Split(preserve, ",")
+	}
+
+	if dryRun {
+		return a.planClean(flags, req)
+	}
+
+	return a.operation("clean", req.Profile, flags, detach, func(ctx context.Context, s *session) (*domain.Job, error) {
+		if confirm == "" {
+			token, err := a.promptClean(ctx, s, req)
+			if err != nil {
+				return nil, err
+			}
+			req.Token = token
+		}
+		if detach && s.client != nil {
+			return s.client.SubmitClean(ctx, req)
+		}
+		return nil, s.useCase.Clean(ctx, req)
+	})
+}
+
+// planClean prints what a clean of req would remove
+func (a *App) planClean(flags clientFlags, req domain.CleanRequest) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+	s, err := a.connect(ctx, flags)
+	if err != nil {
+		return err
+	}
+	plan, err := s.useCase.PlanClean(ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.render(s.flags.output, plan)
+}
+
+// promptClean prints the plan of req and returns its token once the user
+// confirms it on stdin
+func (a *App) promptClean(ctx context.Context, s *session, req domain.CleanRequest) (string, error) {
+	plan, err := s.useCase.PlanClean(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if err := renderTable(a.stderr, plan); err != nil {
+		return "", err
+	}
+	fmt.Fprint(a.stderr, "Remove everything listed above? [y/N] ")
+
+	answer, err := bufio.NewReader(a.stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(a.stderr)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return plan.Token, nil
+	}
+	return "", &domain.CleanNotConfirmedError{Reason: "declined; review with --dry-run and pass its token to --confirm"}
+}
+
+// operation runs a mutating command and prints its result. run returns the
+// queued job when it only submitted one.
+func (a *App) operation(name, profile string, flags clientFlags, detach bool,
+	run func(ctx context.Context, s *session) (*domain.Job, error)) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	s, err := a.connect(ctx, flags)
+	if err != nil {
+		return err
+	}
+	if detach && s.client == nil {
+		return fmt.Errorf("--detach needs a running daemon")
+	}
+
+	job, err := run(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	result := operationResult{Operation: name, Profile: profile, State: string(domain.JobSucceeded)}
+	if job != nil {
+		result.State, result.JobID = string(job.State), job.ID
+	}
+	return a.render(s.flags.output, result)
+}
+
+func (a *App) runStatus(args []string) error {
+	var flags clientFlags
+	fs := a.newFlagSet("status")
+	flags.register(fs)
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	s, err := a.connect(ctx, flags)
+	if err != nil {
+		return err
+	}
+	status, err := s.useCase.Status(ctx, profileArg(positional))
+	if err != nil {
+		return err
+	}
+	return a.render(s.flags.output, status)
+}
+
+func (a *App) runKubeConfig(args []string) error {
+	var flags clientFlags
+	fs := a.newFlagSet("kubeconfig")
+	flags.register(fs)
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	s, err := a.connect(ctx, flags)
+	if err != nil {
+		return err
+	}
+	kubeconfig, err := s.useCase.GetKubeConfig(ctx, profileArg(positional))
+	if err != nil {
+		return err
+	}
+	return a.render(s.flags.output, kubeConfig(kubeconfig))
+}
+
+func (a *App) runProfiles(args []string) error {
+	var flags clientFlags
+	fs := a.newFlagSet("profiles")
+	flags.register(fs)
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	s, err := a.connect(ctx, flags)
+	if err != nil {
+		return err
+	}
+	profiles, err := s.useCase.ListProfiles(ctx)
+	if err != nil {
+		return err
+	}
+	return a.render(s.flags.output, profiles)
+}
+
+func (a *App) runDeps(args []string) error {
+	var flags clientFlags
+	var update bool
+	fs := a.newFlagSet("deps")
+	flags.register(fs)
+	fs.BoolVar(&update, "update", false, "Install or upgrade colima and lima first")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	s, err := a.connect(ctx, flags)
+	if err != nil {
+		return err
+	}
+	if update {
+		if err := s.useCase.UpdateDependencies(ctx); err != nil {
+			return err
+		}
+	}
+	status, err := s.useCase.CheckDependencies(ctx)
+	if err != nil {
+		return err
+	}
+	return a.render(s.flags.output, status)
+}
+
+func (a *App) runLogs(args []string) error {
+	var flags clientFlags
+	var follow bool
+	fs := a.newFlagSet("logs")
+	flags.register(fs)
+	fs.BoolVar(&follow, "f", false, "Follow the output until the job finishes")
+	fs.BoolVar(&follow, "follow", false, "Follow the output until the job finishes")
+	positional, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return &usageError{msg: "logs needs a job ID"}
+	}
+	id := positional[0]
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	s, err := a.connect(ctx, flags)
+	if err != nil {
+		return err
+	}
+	if s.client == nil {
+		return fmt.Errorf("logs needs a running daemon")
+	}
+
+	var printed int
+	for {
+		job, err := s.client.GetJob(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(job.Output) > printed {
+			fmt.Fprint(a.stdout, job.Output[printed:])
+			printed = len(job.Output)
+		}
+		if !follow || job.State.Finished() {
+			if job.State != domain.JobSucceeded && follow {
+				return fmt.Errorf("job %s %s: %s", job.ID, job.State, strings.TrimSpace(job.Error))
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(logsPollInterval):
+		}
+	}
+}
+
+func profileArg(positional []string) string {
+	if len(positional) == 0 {
+		return ""
+	}
+	return positional[0]
+}
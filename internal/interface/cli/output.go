@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats accepted by --output
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+	formatYAML  = "yaml"
+)
+
+// kubeConfig is printed verbatim in table and yaml output
+type kubeConfig string
+
+func validFormat(format string) bool {
+	return format == formatJSON || format == formatTable || format == formatYAML
+}
+
+// render writes v to stdout in format
+func (a *App) render(format string, v interface{}) error {
+	switch format {
+	case formatJSON:
+		if kc, ok := v.(kubeConfig); ok {
+			v = map[string]string{"kubeconfig": string(kc)}
+		}
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		if kc, ok := v.(kubeConfig); ok {
+			_, err := io.WriteString(a.stdout, string(kc))
+			return err
+		}
+		data, err := toYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = a.stdout.Write(data)
+		return err
+	default:
+		return renderTable(a.stdout, v)
+	}
+}
+
+// toYAML marshals v with the same field names and order as its JSON form.
+// v is wrapped in an object so that nested maps decode as ordered MapSlices.
+func toYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.MapSlice
+	wrapped := append(append([]byte(`{"v":`), data...), '}')
+	if err := yaml.Unmarshal(wrapped, &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc[0].Value)
+}
+
+func renderTable(out io.Writer, v interface{}) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	switch v := v.(type) {
+	case *domain.ColimaStatus:
+		rows := [][2]interface{}{
+			{"PROFILE", v.Profile},
+			{"STATUS", v.Status},
+			{"ARCH", v.Arch},
+			{"RUNTIME", v.Runtime},
+			{"VM TYPE", v.VMType},
+			{"MOUNT TYPE", v.MountType},
+			{"CPUS", v.CPUs},
+			{"MEMORY", fmt.Sprintf("%dGiB", v.Memory)},
+			{"DISK", fmt.Sprintf("%dGiB", v.DiskSize)},
+			{"KUBERNETES", kubernetesColumn(v)},
+			{"ADDRESS", v.IPAddress},
+			{"DOCKER SOCKET", dash(v.DockerSocket)},
+			{"CONTAINERD SOCKET", dash(v.ContainerdSocket)},
+		}
+		for _, row := range rows {
+			fmt.Fprintf(w, "%s\t%v\n", row[0], row[1])
+		}
+	case []domain.ProfileInfo:
+		fmt.Fprintln(w, "NAME\tSTATE\tCPUS\tMEMORY\tDISK\tRUNTIME\tARCH\tDECLARED")
+		for _, p := range v {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%v\n", p.Name, p.State,
+				optionalInt(p.CPUs, ""), optionalInt(p.Memory, "GiB"), optionalInt(p.DiskSize, "GiB"),
+				dash(p.Runtime), dash(p.Arch), p.Declared)
+		}
+	case *domain.DependencyStatus:
+		fmt.Fprintln(w, "DEPENDENCY\tINSTALLED\tVERSION\tPATH")
+		fmt.Fprintf(w, "homebrew\t%v\t%s\t%s\n", v.Homebrew, "-", dash(v.HomebrewPath))
+		fmt.Fprintf(w, "colima\t%v\t%s\t%s\n", v.Colima, dash(v.ColimaVersion), dash(v.ColimaPath))
+		fmt.Fprintf(w, "lima\t%v\t%s\t%s\n", v.Lima, dash(v.LimaVersion), "-")
+	case operationResult:
+		if v.JobID != "" {
+			fmt.Fprintf(w, "%s job %s %s\n", v.Operation, v.JobID, v.State)
+			break
+		}
+		target := "all profiles"
+		if v.Profile != "" {
+			target = fmt.Sprintf("profile '%s'", v.Profile)
+		}
+		fmt.Fprintf(w, "%s of %s %s\n", v.Operation, target, v.State)
+	case *domain.CleanPlan:
+		fmt.Fprintln(w, "KIND\tNAME\tSIZE")
+		for _, profile := range v.Profiles {
+			fmt.Fprintf(w, "profile\t%s\t-\n", profile)
+		}
+		for _, dir := range v.Directories {
+			fmt.Fprintf(w, "directory\t%s\t%s\n", dir.Path, formatBytes(dir.Size))
+		}
+		for _, kubeconfig := range v.KubeConfigs {
+			fmt.Fprintf(w, "kubeconfig\t%s\t%s\n", kubeconfig.Path, formatBytes(kubeconfig.Size))
+		}
+		for _, context := range v.DockerContexts {
+			fmt.Fprintf(w, "docker context\t%s\t-\n", context)
+		}
+		for _, kept := range v.Preserved {
+			fmt.Fprintf(w, "preserved\t%s\t%s\n", kept.Path, formatBytes(kept.Size))
+		}
+		fmt.Fprintf(w, "\nTOTAL\t%s\n", formatBytes(v.TotalSize))
+		if v.Token != "" {
+			fmt.Fprintf(w, "TOKEN\t%s\n", v.Token)
+		}
+	case daemonResult:
+		fmt.Fprintf(w, "colima-manager (PID %d) %s\n", v.PID, v.State)
+	case kubeConfig:
+		fmt.Fprint(w, string(v))
+	default:
+		return fmt.Errorf("no table format for %T", v)
+	}
+
+	return w.Flush()
+}
+
+func kubernetesColumn(status *domain.ColimaStatus) string {
+	switch {
+	case !status.Kubernetes:
+		return "disabled"
+	case status.KubernetesVersion != "":
+		return status.KubernetesVersion
+	default:
+		return "enabled"
+	}
+}
+
+func optionalInt(v int, unit string) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%s", v, unit)
+}
+
+// formatBytes prints size with a binary unit, e.g. 1.5GiB
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type AdminHandler struct {
+	shutdown usecase.ShutdownInterface
+}
+
+func NewAdminHandler(shutdown usecase.ShutdownInterface) *AdminHandler {
+	return &AdminHandler{shutdown: shutdown}
+}
+
+type shutdownRequest struct {
+	Policy domain.ShutdownPolicy `json:"policy"`
+}
+
+// Shutdown asks the manager to drain its jobs, apply the shutdown policy and
+// exit. The body may override the configured policy.
+func (h *AdminHandler) Shutdown(c echo.Context) error {
+	var req shutdownRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	policy, err := h.shutdown.Request(req.Policy)
+	switch err.(type) {
+	case nil:
+		return c.JSON(http.StatusAccepted, map[string]string{
+			"state":  "shutting_down",
+			"policy": string(policy),
+		})
+	case *domain.InvalidShutdownPolicyError:
+		return c.JSON(http.StatusBadRequest, domain.NewErrorDetail(err))
+	case *domain.ShuttingDownError:
+		return c.JSON(http.StatusServiceUnavailable, domain.NewErrorDetail(err))
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+}
+
+// Interrupted lists the operations the previous shutdown had to cancel
+func (h *AdminHandler) Interrupted(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.shutdown.Interrupted())
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+func TestAdminShutdown(t *testing.T) {
+	mockUC := &mockUseCase{}
+	h := NewAdminHandler(usecase.NewShutdown(mockUC, usecase.NewJobRunner(mockUC), &config.Config{}))
+	e := echo.New()
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedKey  string
+		expectedVal  string
+	}{
+		{"invalid policy", `{"policy":"reboot"}`, http.StatusBadRequest, "error", "unknown shutdown policy 'reboot'"},
+		{"configured policy", `{}`, http.StatusAccepted, "policy", "leave-running"},
+		{"already shutting down", `{"policy":"stop-all"}`, http.StatusServiceUnavailable, "code", "shutting_down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/shutdown", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := h.Shutdown(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if rec.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, rec.Code)
+			}
+
+			var response map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if !strings.HasPrefix(response[tt.expectedKey], tt.expectedVal) {
+				t.Errorf("Expected %s '%s', got '%s'", tt.expectedKey, tt.expectedVal, response[tt.expectedKey])
+			}
+		})
+	}
+}
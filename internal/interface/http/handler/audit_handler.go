@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type AuditHandler struct {
+	audit usecase.AuditInterface
+	now   func() time.Time
+}
+
+func NewAuditHandler(audit usecase.AuditInterface) *AuditHandler {
+	return &AuditHandler{audit: audit, now: time.Now}
+}
+
+// List returns audit records filtered by the profile, op, since and limit
+// query parameters. since is an RFC 3339 time or a duration such as 24h.
+func (h *AuditHandler) List(c echo.Context) error {
+	filter := domain.AuditFilter{
+		Profile:   c.QueryParam("profile"),
+		Operation: c.QueryParam("op"),
+	}
+
+	if since := c.QueryParam("since"); since != "" {
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			filter.Since = t
+		} else if d, err := time.ParseDuration(since); err == nil && d > 0 {
+			filter.Since = h.now().Add(-d)
+		} else {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "since must be an RFC 3339 time or a positive duration"})
+		}
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "limit must be a non-negative integer"})
+		}
+		filter.Limit = n
+	}
+
+	records, err := h.audit.History(filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	return c.JSON(http.StatusOK, records)
+}
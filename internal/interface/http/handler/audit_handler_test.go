@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type mockAudit struct {
+	filter domain.AuditFilter
+}
+
+func (m *mockAudit) History(filter domain.AuditFilter) ([]domain.AuditRecord, error) {
+	m.filter = filter
+	return []domain.AuditRecord{}, nil
+}
+
+func TestAuditList(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	audit := &mockAudit{}
+	h := NewAuditHandler(audit)
+	h.now = func() time.Time { return now }
+	e := echo.New()
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expectedFrom time.Time
+	}{
+		{"duration", "?profile=web&op=start&since=2h&limit=5", http.StatusOK, now.Add(-2 * time.Hour)},
+		{"timestamp", "?since=2024-04-30T00:00:00Z", http.StatusOK, time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+		{"invalid since", "?since=yesterday", http.StatusBadRequest, time.Time{}},
+		{"invalid limit", "?limit=-1", http.StatusBadRequest, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit.filter = domain.AuditFilter{}
+			rec := httptest.NewRecorder()
+			if err := h.List(e.NewContext(httptest.NewRequest(http.MethodGet, "/audit"+tt.query, nil), rec)); err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if rec.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if !audit.filter.Since.Equal(tt.expectedFrom) {
+				t.Errorf("Expected since %v, got %v", tt.expectedFrom, audit.filter.Since)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	if err := h.List(e.NewContext(httptest.NewRequest(http.MethodGet, "/audit?profile=web&op=start&limit=5", nil), rec)); err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if audit.filter.Profile != "web" || audit.filter.Operation != "start" || audit.filter.Limit != 5 {
+		t.Errorf("Unexpected filter: %+v", audit.filter)
+	}
+}
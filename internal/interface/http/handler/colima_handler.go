@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gqadonis/colima-manager/internal/domain"
 	"github.com/gqadonis/colima-manager/internal/pkg/logger"
@@ -11,38 +12,40 @@ import (
 
 type ColimaHandler struct {
 	useCase usecase.ColimaUseCaseInterface
+	jobs    usecase.JobRunnerInterface
 	log     *logger.Logger
 }
 
-func NewColimaHandler(useCase usecase.ColimaUseCaseInterface) *ColimaHandler {
+func NewColimaHandler(useCase usecase.ColimaUseCaseInterface, jobs usecase.JobRunnerInterface) *ColimaHandler {
 	return &ColimaHandler{
 		useCase: useCase,
+		jobs:    jobs,
 		log:     logger.GetLogger(),
 	}
 }
 
 func (h *ColimaHandler) handleError(c echo.Context, err error) error {
-	switch e := err.(type) {
-	case *domain.ProfileNotFoundError:
-		return c.JSON(http.StatusNotFound, map[string]string{"error": e.Error()})
+	var status int
+	switch err.(type) {
+	case *domain.ProfileNotFoundError, *domain.JobNotFoundError:
+		status = http.StatusNotFound
 	case *domain.ProfileNotStartedError:
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": e.Error()})
-	case *domain.ProfileUnreachableError:
-		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": e.Error()})
-	case *domain.ProfileMalfunctionError:
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": e.Error()})
-	case *domain.ProfileBusyError:
-		return c.JSON(http.StatusServiceUnavailable, map[string]string{
-			"error": e.Error(),
-			"code":  "profile_busy",
-		})
-	case *domain.DependencyError:
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": e.Error()})
-	case *domain.DockerContextError:
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": e.Error()})
+		status = http.StatusBadRequest
+	case *domain.ProfileUnreachableError, *domain.ProfileBusyError, *domain.OperationCanceledError,
+		*domain.ShuttingDownError:
+		status = http.StatusServiceUnavailable
+	case *domain.ProfileMalfunctionError, *domain.DependencyError, *domain.DockerContextError:
+		status = http.StatusInternalServerError
+	case *domain.OperationTimeoutError:
+		status = http.StatusGatewayTimeout
+	case *domain.ValidationError:
+		status = http.StatusUnprocessableEntity
+	case *domain.CleanNotConfirmedError:
+		status = http.StatusPreconditionRequired
 	default:
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 	}
+	return c.JSON(status, domain.NewErrorDetail(err))
 }
 
 func (h *ColimaHandler) CheckDependencies(c echo.Context) error {
@@ -66,18 +69,20 @@ func (h *ColimaHandler) Start(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 	}
 
-	if err := h.useCase.Start(c.Request().Context(), config); err != nil {
+	job, err := h.jobs.SubmitStart(c.Request().Context(), config)
+	if err != nil {
 		return h.handleError(c, err)
 	}
-	return c.NoContent(http.StatusOK)
+	return h.accepted(c, job)
 }
 
 func (h *ColimaHandler) Stop(c echo.Context) error {
 	profile := c.QueryParam("profile")
-	if err := h.useCase.Stop(c.Request().Context(), profile); err != nil {
+	job, err := h.jobs.SubmitStop(c.Request().Context(), profile)
+	if err != nil {
 		return h.handleError(c, err)
 	}
-	return c.NoContent(http.StatusOK)
+	return h.accepted(c, job)
 }
 
 func (h *ColimaHandler) Status(c echo.Context) error {
@@ -89,6 +94,14 @@ func (h *ColimaHandler) Status(c echo.Context) error {
 	return c.JSON(http.StatusOK, status)
 }
 
+func (h *ColimaHandler) ListProfiles(c echo.Context) error {
+	profiles, err := h.useCase.ListProfiles(c.Request().Context())
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, profiles)
+}
+
 func (h *ColimaHandler) GetKubeConfig(c echo.Context) error {
 	profile := c.QueryParam("profile")
 	kubeconfig, err := h.useCase.GetKubeConfig(c.Request().Context(), profile)
@@ -104,8 +117,41 @@ func (h *ColimaHandler) Clean(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 	}
 
-	if err := h.useCase.Clean(c.Request().Context(), req); err != nil {
+	if dryRun := c.QueryParam("dry_run"); dryRun != "" {
+		ok, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid dry_run parameter"})
+		}
+		if ok {
+			plan, err := h.useCase.PlanClean(c.Request().Context(), req)
+			if err != nil {
+				return h.handleError(c, err)
+			}
+			return c.JSON(http.StatusOK, plan)
+		}
+	}
+
+	job, err := h.jobs.SubmitClean(c.Request().Context(), req)
+	if err != nil {
 		return h.handleError(c, err)
 	}
-	return c.NoContent(http.StatusOK)
+	return h.accepted(c, job)
+}
+
+func (h *ColimaHandler) ListJobs(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.jobs.List())
+}
+
+func (h *ColimaHandler) GetJob(c echo.Context) error {
+	job, err := h.jobs.Get(c.Param("id"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// accepted responds with 202 and points the client at the job resource
+func (h *ColimaHandler) accepted(c echo.Context, job *domain.Job) error {
+	c.Response().Header().Set(echo.HeaderLocation, "/jobs/"+job.ID)
+	return c.JSON(http.StatusAccepted, job)
 }
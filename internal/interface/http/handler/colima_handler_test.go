@@ -6,15 +6,18 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/usecase"
 	"github.com/labstack/echo/v4"
 )
 
 type mockUseCase struct {
 	mockDependencyStatus *domain.DependencyStatus
 	mockColimaStatus     *domain.ColimaStatus
+	mockProfiles         []domain.ProfileInfo
 	mockKubeConfig       string
 	mockError            error
 }
@@ -39,22 +42,32 @@ func (m *mockUseCase) Status(ctx context.Context, profile string) (*domain.Colim
 	return m.mockColimaStatus, m.mockError
 }
 
+func (m *mockUseCase) ListProfiles(ctx context.Context) ([]domain.ProfileInfo, error) {
+	return m.mockProfiles, m.mockError
+}
+
 func (m *mockUseCase) GetKubeConfig(ctx context.Context, profile string) (string, error) {
 	return m.mockKubeConfig, m.mockError
 }
 
+func (m *mockUseCase) PlanClean(ctx context.Context, req domain.CleanRequest) (*domain.CleanPlan, error) {
+	return &domain.CleanPlan{Profiles: []string{req.Profile}, Token: "token"}, m.mockError
+}
+
 func (m *mockUseCase) Clean(ctx context.Context, req domain.CleanRequest) error {
 	return m.mockError
 }
 
 func TestHandlerProfileBusy(t *testing.T) {
-	// Create mock use case that returns ProfileBusyError
-	mockUC := &mockUseCase{
-		mockError: &domain.ProfileBusyError{Profile: "test-profile"},
-	}
+	// Hold the profile lock so the job runner rejects the request
+	domain.ResetProfileLock()
+	domain.GetProfileLock().Lock("test-profile")
+	defer domain.ResetProfileLock()
+
+	mockUC := &mockUseCase{}
 
 	// Create handler with mock use case
-	h := NewColimaHandler(mockUC)
+	h := NewColimaHandler(mockUC, usecase.NewJobRunner(mockUC))
 
 	// Create Echo instance
 	e := echo.New()
@@ -116,7 +129,7 @@ func TestHandlerSuccess(t *testing.T) {
 	}
 
 	// Create handler with mock use case
-	h := NewColimaHandler(mockUC)
+	h := NewColimaHandler(mockUC, usecase.NewJobRunner(mockUC))
 
 	// Create Echo instance
 	e := echo.New()
@@ -140,13 +153,13 @@ func TestHandlerSuccess(t *testing.T) {
 			method:         http.MethodPost,
 			path:           "/start",
 			body:           domain.ColimaConfig{Profile: "test"},
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusAccepted,
 		},
 		{
 			name:           "Stop",
 			method:         http.MethodPost,
 			path:           "/stop",
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusAccepted,
 		},
 		{
 			name:           "Status",
@@ -154,6 +167,12 @@ func TestHandlerSuccess(t *testing.T) {
 			path:           "/status",
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "ListProfiles",
+			method:         http.MethodGet,
+			path:           "/profiles",
+			expectedStatus: http.StatusOK,
+		},
 		{
 			name:           "GetKubeConfig",
 			method:         http.MethodGet,
@@ -164,8 +183,8 @@ func TestHandlerSuccess(t *testing.T) {
 			name:           "Clean",
 			method:         http.MethodPost,
 			path:           "/clean",
-			body:           domain.CleanRequest{Profile: "test"},
-			expectedStatus: http.StatusOK,
+			body:           domain.CleanRequest{Profile: "test-clean", Token: "token"},
+			expectedStatus: http.StatusAccepted,
 		},
 	}
 
@@ -195,6 +214,8 @@ func TestHandlerSuccess(t *testing.T) {
 				err = h.Stop(c)
 			case "Status":
 				err = h.Status(c)
+			case "ListProfiles":
+				err = h.ListProfiles(c)
 			case "GetKubeConfig":
 				err = h.GetKubeConfig(c)
 			case "Clean":
@@ -211,3 +232,188 @@ func TestHandlerSuccess(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerValidation(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockUC := &mockUseCase{}
+	h := NewColimaHandler(mockUC, usecase.NewJobRunner(mockUC))
+	e := echo.New()
+
+	tests := []struct {
+		name    string
+		handler echo.HandlerFunc
+		path    string
+		body    string
+		fields  []string
+	}{
+		{"start", h.Start, "/start", `{"profile":"a/b","cpus":-1,"vm_type":"banana","runtime":"podman"}`,
+			[]string{"profile", "cpus", "vm_type", "runtime"}},
+		{"stop", h.Stop, "/stop?profile=..", "", []string{"profile"}},
+		{"clean", h.Clean, "/clean", `{"profile":"../../etc"}`, []string{"profile"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			if err := tt.handler(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Errorf("Expected status code %d, got %d", http.StatusUnprocessableEntity, rec.Code)
+			}
+			var detail domain.ErrorDetail
+			if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			var fields []string
+			for _, field := range detail.Fields {
+				fields = append(fields, field.Field)
+			}
+			if detail.Code != domain.CodeValidationFailed || strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("Expected %s with fields %v, got %+v", domain.CodeValidationFailed, tt.fields, detail)
+			}
+		})
+	}
+	if jobs := h.jobs.List(); len(jobs) != 0 {
+		t.Errorf("Expected no job to be queued, got %d", len(jobs))
+	}
+}
+
+func TestHandlerCleanConfirmation(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockUC := &mockUseCase{}
+	h := NewColimaHandler(mockUC, usecase.NewJobRunner(mockUC))
+	e := echo.New()
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"dry run", "/clean?dry_run=true", `{"profile":"work"}`, http.StatusOK, ""},
+		{"invalid dry run", "/clean?dry_run=maybe", `{"profile":"work"}`, http.StatusBadRequest, ""},
+		{"missing token", "/clean", `{"profile":"work"}`, http.StatusPreconditionRequired, domain.CodeCleanNotConfirmed},
+		{"stale token", "/clean", `{"profile":"work","token":"stale"}`, http.StatusPreconditionRequired, domain.CodeCleanNotConfirmed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			if err := h.Clean(e.NewContext(req, rec)); err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+
+			if rec.Code != tt.status {
+				t.Errorf("Expected status code %d, got %d", tt.status, rec.Code)
+			}
+			if tt.status == http.StatusOK {
+				var plan domain.CleanPlan
+				if err := json.Unmarshal(rec.Body.Bytes(), &plan); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if plan.Token != "token" || len(plan.Profiles) != 1 || plan.Profiles[0] != "work" {
+					t.Errorf("Unexpected plan: %+v", plan)
+				}
+				return
+			}
+			var detail domain.ErrorDetail
+			if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if tt.code != "" && detail.Code != tt.code {
+				t.Errorf("Expected code %s, got %+v", tt.code, detail)
+			}
+		})
+	}
+	if jobs := h.jobs.List(); len(jobs) != 0 {
+		t.Errorf("Expected no job to be queued, got %d", len(jobs))
+	}
+}
+
+func TestHandlerJobs(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockUC := &mockUseCase{}
+	jobs := usecase.NewJobRunner(mockUC)
+	h := NewColimaHandler(mockUC, jobs)
+	e := echo.New()
+
+	// Submit a start job
+	startJSON, _ := json.Marshal(domain.ColimaConfig{Profile: "job-profile"})
+	req := httptest.NewRequest(http.MethodPost, "/start", bytes.NewReader(startJSON))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	if err := h.Start(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d", http.StatusAccepted, rec.Code)
+	}
+
+	var job domain.Job
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if job.ID == "" {
+		t.Fatal("Expected job ID in response")
+	}
+	if location := rec.Header().Get(echo.HeaderLocation); location != "/jobs/"+job.ID {
+		t.Errorf("Expected Location /jobs/%s, got %s", job.ID, location)
+	}
+	if _, err := jobs.Wait(context.Background(), job.ID); err != nil {
+		t.Fatalf("Failed to wait for job: %v", err)
+	}
+
+	// Fetch the job
+	req = httptest.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(job.ID)
+	if err := h.GetJob(c); err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if job.State != domain.JobSucceeded {
+		t.Errorf("Expected state %s, got %s", domain.JobSucceeded, job.State)
+	}
+
+	// List jobs
+	req = httptest.NewRequest(http.MethodGet, "/jobs", nil)
+	rec = httptest.NewRecorder()
+	if err := h.ListJobs(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	var list []domain.Job
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(list) != 1 {
+		t.Errorf("Expected 1 job, got %d", len(list))
+	}
+
+	// Unknown job
+	req = httptest.NewRequest(http.MethodGet, "/jobs/missing", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("missing")
+	if err := h.GetJob(c); err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
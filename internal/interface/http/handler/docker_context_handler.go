@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type DockerContextHandler struct {
+	contexts usecase.DockerContextInterface
+}
+
+func NewDockerContextHandler(contexts usecase.DockerContextInterface) *DockerContextHandler {
+	return &DockerContextHandler{contexts: contexts}
+}
+
+// syncContextRequest names the profile whose context is created or refreshed
+type syncContextRequest struct {
+	Profile string `json:"profile"`
+	// Use makes the context the docker CLI's default
+	Use bool `json:"use"`
+}
+
+func (h *DockerContextHandler) handleError(c echo.Context, err error) error {
+	var status int
+	switch err.(type) {
+	case *domain.ProfileNotFoundError:
+		status = http.StatusNotFound
+	case *domain.ProfileNotStartedError:
+		status = http.StatusBadRequest
+	case *domain.ProfileUnreachableError, *domain.OperationCanceledError:
+		status = http.StatusServiceUnavailable
+	case *domain.ProfileMalfunctionError, *domain.DockerContextError:
+		status = http.StatusInternalServerError
+	case *domain.OperationTimeoutError:
+		status = http.StatusGatewayTimeout
+	case *domain.ValidationError:
+		status = http.StatusUnprocessableEntity
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	return c.JSON(status, domain.NewErrorDetail(err))
+}
+
+// List returns the colima Docker contexts, flagging orphaned ones
+func (h *DockerContextHandler) List(c echo.Context) error {
+	contexts, err := h.contexts.ListDockerContexts(c.Request().Context())
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, contexts)
+}
+
+// Sync creates or refreshes the context of a running profile
+func (h *DockerContextHandler) Sync(c echo.Context) error {
+	var req syncContextRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	synced, err := h.contexts.SyncDockerContext(c.Request().Context(), req.Profile, req.Use)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, synced)
+}
+
+// Delete removes the context of the profile query parameter, or every
+// orphaned context with orphaned=true
+func (h *DockerContextHandler) Delete(c echo.Context) error {
+	profile := c.QueryParam("profile")
+	orphaned := false
+	if raw := c.QueryParam("orphaned"); raw != "" {
+		var err error
+		if orphaned, err = strconv.ParseBool(raw); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid orphaned parameter"})
+		}
+	}
+	if (profile == "") == !orphaned {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Either profile or orphaned=true is required"})
+	}
+
+	if orphaned {
+		removed, err := h.contexts.PruneDockerContexts(c.Request().Context())
+		if err != nil {
+			return h.handleError(c, err)
+		}
+		return c.JSON(http.StatusOK, removed)
+	}
+	if err := h.contexts.RemoveDockerContext(c.Request().Context(), profile); err != nil {
+		return h.handleError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
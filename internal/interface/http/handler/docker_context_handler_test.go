@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type mockContexts struct {
+	contexts []domain.DockerContext
+	removed  []string
+}
+
+func (m *mockContexts) ListDockerContexts(ctx context.Context) ([]domain.DockerContext, error) {
+	return m.contexts, nil
+}
+
+func (m *mockContexts) SyncDockerContext(ctx context.Context, profile string, use bool) (*domain.DockerContext, error) {
+	if profile == "stopped" {
+		return nil, &domain.ProfileNotStartedError{Profile: profile}
+	}
+	return &domain.DockerContext{Name: "colima-" + profile, Profile: profile, Current: use}, nil
+}
+
+func (m *mockContexts) RemoveDockerContext(ctx context.Context, profile string) error {
+	m.removed = append(m.removed, profile)
+	return nil
+}
+
+func (m *mockContexts) PruneDockerContexts(ctx context.Context) ([]domain.DockerContext, error) {
+	var removed []domain.DockerContext
+	for _, c := range m.contexts {
+		if c.Orphaned {
+			removed = append(removed, c)
+		}
+	}
+	return removed, nil
+}
+
+func TestDockerContextHandler(t *testing.T) {
+	contexts := &mockContexts{contexts: []domain.DockerContext{
+		{Name: "colima", Profile: "default"},
+		{Name: "colima-gone", Profile: "gone", Orphaned: true},
+	}}
+	h := NewDockerContextHandler(contexts)
+	e := echo.New()
+	e.GET("/docker/contexts", h.List)
+	e.POST("/docker/contexts", h.Sync)
+	e.DELETE("/docker/contexts", h.Delete)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"list", http.MethodGet, "/docker/contexts", "", http.StatusOK, `"orphaned":true`},
+		{"sync", http.MethodPost, "/docker/contexts", `{"profile":"work","use":true}`, http.StatusOK, `"current":true`},
+		{"sync stopped", http.MethodPost, "/docker/contexts", `{"profile":"stopped"}`, http.StatusBadRequest, `"code":"profile_not_started"`},
+		{"delete", http.MethodDelete, "/docker/contexts?profile=work", "", http.StatusNoContent, ""},
+		{"prune", http.MethodDelete, "/docker/contexts?orphaned=true", "", http.StatusOK, `"name":"colima-gone"`},
+		{"delete nothing", http.MethodDelete, "/docker/contexts", "", http.StatusBadRequest, `"error"`},
+		{"delete both", http.MethodDelete, "/docker/contexts?profile=work&orphaned=true", "", http.StatusBadRequest, `"error"`},
+		{"invalid orphaned", http.MethodDelete, "/docker/contexts?orphaned=maybe", "", http.StatusBadRequest, `"error"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d (%s)", tt.expectedCode, rec.Code, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.expectedBody, rec.Body)
+			}
+		})
+	}
+
+	if len(contexts.removed) != 1 || contexts.removed[0] != "work" {
+		t.Errorf("Expected only the context of work to be removed, got %v", contexts.removed)
+	}
+}
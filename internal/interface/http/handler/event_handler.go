@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// keepAliveInterval is how often a comment is sent to keep idle streams open
+const keepAliveInterval = 15 * time.Second
+
+// EventSubscriber provides a filtered stream of lifecycle events
+type EventSubscriber interface {
+	Subscribe(profile string) (<-chan domain.Event, func())
+}
+
+type EventHandler struct {
+	events EventSubscriber
+	log    *logger.Logger
+}
+
+func NewEventHandler(events EventSubscriber) *EventHandler {
+	return &EventHandler{
+		events: events,
+		log:    logger.GetLogger(),
+	}
+}
+
+// Stream sends lifecycle events as Server-Sent Events until the client
+// disconnects or the subscription is closed at shutdown, optionally filtered
+// by the profile query parameter
+func (h *EventHandler) Stream(c echo.Context) error {
+	profile := c.QueryParam("profile")
+	events, unsubscribe := h.events.Subscribe(profile)
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	h.log.Info("Event stream opened - Profile: %s", profile)
+	defer h.log.Info("Event stream closed - Profile: %s", profile)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return h.log.LogError(err, "failed to encode event")
+			}
+			if _, err := fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/events"
+	"github.com/labstack/echo/v4"
+)
+
+func TestEventStream(t *testing.T) {
+	bus := events.NewBus()
+	h := NewEventHandler(bus)
+
+	e := echo.New()
+	e.GET("/events", h.Stream)
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?profile=work", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get(echo.HeaderContentType); ct != "text/event-stream" {
+		t.Errorf("Expected content type text/event-stream, got %s", ct)
+	}
+
+	// Headers are flushed once the subscription exists, so events published
+	// now reach the stream
+	bus.Publish(domain.Event{Type: domain.EventStarted, Profile: "default"})
+	bus.Publish(domain.Event{Type: domain.EventStopped, Profile: "work"})
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read stream: %v", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if lines[0] != "id: 2" {
+		t.Errorf("Expected filtered event id 2, got %q", lines[0])
+	}
+	if lines[1] != "event: stopped" {
+		t.Errorf("Expected stopped event, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "data: ") || !strings.Contains(lines[2], `"profile":"work"`) {
+		t.Errorf("Unexpected data line %q", lines[2])
+	}
+}
+
+func TestEventStreamEndsOnClose(t *testing.T) {
+	bus := events.NewBus()
+	h := NewEventHandler(bus)
+
+	e := echo.New()
+	e.GET("/events", h.Stream)
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events?profile=work")
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	bus.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read stream: %v", err)
+	}
+	if !strings.Contains(string(body), "event: shutdown\n") {
+		t.Errorf("Expected a final shutdown event, got %q", body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := server.Config.Shutdown(ctx); err != nil {
+		t.Errorf("Expected shutdown not to wait for the closed stream: %v", err)
+	}
+}
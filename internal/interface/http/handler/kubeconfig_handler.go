@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type KubeConfigHandler struct {
+	kubeconfig usecase.KubeConfigInterface
+}
+
+func NewKubeConfigHandler(kubeconfig usecase.KubeConfigInterface) *KubeConfigHandler {
+	return &KubeConfigHandler{kubeconfig: kubeconfig}
+}
+
+func (h *KubeConfigHandler) handleError(c echo.Context, err error) error {
+	var status int
+	switch err.(type) {
+	case *domain.ProfileNotFoundError:
+		status = http.StatusNotFound
+	case *domain.ProfileNotStartedError:
+		status = http.StatusBadRequest
+	case *domain.KubeConfigError:
+		status = http.StatusConflict
+	case *domain.ProfileUnreachableError, *domain.OperationCanceledError:
+		status = http.StatusServiceUnavailable
+	case *domain.ProfileMalfunctionError:
+		status = http.StatusInternalServerError
+	case *domain.OperationTimeoutError:
+		status = http.StatusGatewayTimeout
+	case *domain.ValidationError:
+		status = http.StatusUnprocessableEntity
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	return c.JSON(status, domain.NewErrorDetail(err))
+}
+
+// Merge adds the kubeconfig of the profile query parameter to the shared
+// kubeconfig file as colima-<profile>
+func (h *KubeConfigHandler) Merge(c echo.Context) error {
+	merge, err := h.kubeconfig.MergeKubeConfig(c.Request().Context(), c.QueryParam("profile"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, merge)
+}
+
+// Prune removes colima-<profile> from the shared kubeconfig file
+func (h *KubeConfigHandler) Prune(c echo.Context) error {
+	merge, err := h.kubeconfig.PruneKubeConfig(c.Request().Context(), c.QueryParam("profile"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, merge)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type mockKubeConfig struct {
+	pruned []string
+}
+
+func (m *mockKubeConfig) MergeKubeConfig(ctx context.Context, profile string) (*domain.KubeConfigMerge, error) {
+	switch profile {
+	case "missing":
+		return nil, &domain.ProfileNotFoundError{Profile: profile}
+	case "locked":
+		return nil, &domain.KubeConfigError{Path: "/home/dev/.kube/config", Reason: "locked by another process"}
+	}
+	return &domain.KubeConfigMerge{Profile: profile, Path: "/home/dev/.kube/config",
+		Context: domain.KubeContextName(profile), Changed: true}, nil
+}
+
+func (m *mockKubeConfig) PruneKubeConfig(ctx context.Context, profile string) (*domain.KubeConfigMerge, error) {
+	m.pruned = append(m.pruned, profile)
+	return &domain.KubeConfigMerge{Profile: profile, Path: "/home/dev/.kube/config",
+		Context: domain.KubeContextName(profile)}, nil
+}
+
+func TestKubeConfigHandler(t *testing.T) {
+	kubeconfig := &mockKubeConfig{}
+	h := NewKubeConfigHandler(kubeconfig)
+	e := echo.New()
+	e.POST("/kubeconfig/merge", h.Merge)
+	e.DELETE("/kubeconfig/merge", h.Prune)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"merge", http.MethodPost, "/kubeconfig/merge?profile=work", http.StatusOK, `"context":"colima-work"`},
+		{"merge missing", http.MethodPost, "/kubeconfig/merge?profile=missing", http.StatusNotFound, `"code":"profile_not_found"`},
+		{"merge locked", http.MethodPost, "/kubeconfig/merge?profile=locked", http.StatusConflict, `"code":"kubeconfig_error"`},
+		{"prune", http.MethodDelete, "/kubeconfig/merge?profile=work", http.StatusOK, `"changed":false`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d (%s)", tt.expectedCode, rec.Code, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.expectedBody, rec.Body)
+			}
+		})
+	}
+
+	if len(kubeconfig.pruned) != 1 || kubeconfig.pruned[0] != "work" {
+		t.Errorf("Expected only work to be pruned, got %v", kubeconfig.pruned)
+	}
+}
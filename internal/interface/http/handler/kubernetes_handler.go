@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type KubernetesHandler struct {
+	kubernetes usecase.KubernetesInterface
+}
+
+func NewKubernetesHandler(kubernetes usecase.KubernetesInterface) *KubernetesHandler {
+	return &KubernetesHandler{kubernetes: kubernetes}
+}
+
+func (h *KubernetesHandler) handleError(c echo.Context, err error) error {
+	var status int
+	switch err.(type) {
+	case *domain.ProfileNotFoundError:
+		status = http.StatusNotFound
+	case *domain.ProfileNotStartedError:
+		status = http.StatusBadRequest
+	case *domain.ProfileUnreachableError, *domain.OperationCanceledError:
+		status = http.StatusServiceUnavailable
+	case *domain.ProfileMalfunctionError:
+		status = http.StatusInternalServerError
+	case *domain.OperationTimeoutError:
+		status = http.StatusGatewayTimeout
+	case *domain.ValidationError:
+		status = http.StatusUnprocessableEntity
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	return c.JSON(status, domain.NewErrorDetail(err))
+}
+
+// Health reports whether the Kubernetes cluster of a running profile is
+// ready. An unready cluster is still a 200; the body says why.
+func (h *KubernetesHandler) Health(c echo.Context) error {
+	health, err := h.kubernetes.KubernetesHealth(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, health)
+}
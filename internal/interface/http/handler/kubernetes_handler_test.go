@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type mockKubernetes struct{}
+
+func (m *mockKubernetes) KubernetesHealth(ctx context.Context, profile string) (*domain.KubernetesHealth, error) {
+	switch profile {
+	case "missing":
+		return nil, &domain.ProfileNotFoundError{Profile: profile}
+	case "stopped":
+		return nil, &domain.ProfileNotStartedError{Profile: profile}
+	case "booting":
+		return &domain.KubernetesHealth{Profile: profile, Reason: "API server is not ready",
+			APIServer: domain.KubernetesAPICheck{StatusCode: http.StatusInternalServerError, Detail: "[-]etcd failed"},
+			Nodes:     []domain.KubernetesNode{}}, nil
+	}
+	return &domain.KubernetesHealth{Profile: profile, Ready: true,
+		APIServer: domain.KubernetesAPICheck{Ready: true, StatusCode: http.StatusOK, Detail: "ok"},
+		Nodes:     []domain.KubernetesNode{{Name: "colima", Ready: true}}}, nil
+}
+
+func TestKubernetesHandler(t *testing.T) {
+	h := NewKubernetesHandler(&mockKubernetes{})
+	e := echo.New()
+	e.GET("/profiles/:name/kubernetes/health", h.Health)
+
+	tests := []struct {
+		name         string
+		profile      string
+		expectedCode int
+		expectedBody string
+	}{
+		{"ready", "k8s", http.StatusOK, `"nodes":[{"name":"colima","ready":true}]`},
+		{"not ready", "booting", http.StatusOK, `"reason":"API server is not ready"`},
+		{"missing", "missing", http.StatusNotFound, `"code":"profile_not_found"`},
+		{"stopped", "stopped", http.StatusBadRequest, `"code":"profile_not_started"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/profiles/"+tt.profile+"/kubernetes/health", nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d (%s)", tt.expectedCode, rec.Code, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.expectedBody, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type ProfileHandler struct {
+	profiles usecase.ProfileInterface
+}
+
+func NewProfileHandler(profiles usecase.ProfileInterface) *ProfileHandler {
+	return &ProfileHandler{profiles: profiles}
+}
+
+// createProfileRequest is a profile definition together with its name
+type createProfileRequest struct {
+	Name string `json:"name"`
+	config.ProfileConfig
+}
+
+func (h *ProfileHandler) handleError(c echo.Context, err error) error {
+	var status int
+	switch err.(type) {
+	case *domain.ProfileNotFoundError:
+		status = http.StatusNotFound
+	case *domain.ProfileExistsError:
+		status = http.StatusConflict
+	case *domain.ValidationError:
+		status = http.StatusUnprocessableEntity
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	return c.JSON(status, domain.NewErrorDetail(err))
+}
+
+// Get returns the stored definition of a profile
+func (h *ProfileHandler) Get(c echo.Context) error {
+	profile, err := h.profiles.GetProfile(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+// Effective returns the merged configuration a start of the profile would
+// use and where each setting comes from
+func (h *ProfileHandler) Effective(c echo.Context) error {
+	effective, err := h.profiles.EffectiveProfile(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, effective)
+}
+
+// Create stores a new profile definition in the config file
+func (h *ProfileHandler) Create(c echo.Context) error {
+	var req createProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := h.profiles.CreateProfile(c.Request().Context(), req.Name, req.ProfileConfig); err != nil {
+		return h.handleError(c, err)
+	}
+	c.Response().Header().Set(echo.HeaderLocation, "/profiles/"+req.Name+"/config")
+	return c.JSON(http.StatusCreated, req.ProfileConfig)
+}
+
+// Update replaces the definition of an existing profile
+func (h *ProfileHandler) Update(c echo.Context) error {
+	var profile config.ProfileConfig
+	if err := c.Bind(&profile); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := h.profiles.UpdateProfile(c.Request().Context(), c.Param("name"), profile); err != nil {
+		return h.handleError(c, err)
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+// Delete removes a profile definition; the instance itself is left alone
+func (h *ProfileHandler) Delete(c echo.Context) error {
+	if err := h.profiles.DeleteProfile(c.Request().Context(), c.Param("name")); err != nil {
+		return h.handleError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type mockProfiles struct {
+	profiles map[string]config.ProfileConfig
+}
+
+func (m *mockProfiles) GetProfile(ctx context.Context, name string) (config.ProfileConfig, error) {
+	profile, ok := m.profiles[name]
+	if !ok {
+		return config.ProfileConfig{}, &domain.ProfileNotFoundError{Profile: name}
+	}
+	return profile, nil
+}
+
+func (m *mockProfiles) CreateProfile(ctx context.Context, name string, profile config.ProfileConfig) error {
+	if name == "" {
+		return &domain.ValidationError{Profile: name, Fields: []domain.FieldError{{Field: "profile", Message: "must not be empty"}}}
+	}
+	if _, ok := m.profiles[name]; ok {
+		return &domain.ProfileExistsError{Profile: name}
+	}
+	m.profiles[name] = profile
+	return nil
+}
+
+func (m *mockProfiles) UpdateProfile(ctx context.Context, name string, profile config.ProfileConfig) error {
+	if _, ok := m.profiles[name]; !ok {
+		return &domain.ProfileNotFoundError{Profile: name}
+	}
+	m.profiles[name] = profile
+	return nil
+}
+
+func (m *mockProfiles) DeleteProfile(ctx context.Context, name string) error {
+	if _, ok := m.profiles[name]; !ok {
+		return &domain.ProfileNotFoundError{Profile: name}
+	}
+	delete(m.profiles, name)
+	return nil
+}
+
+func (m *mockProfiles) EffectiveProfile(ctx context.Context, name string) (*domain.EffectiveConfig, error) {
+	profile, ok := m.profiles[name]
+	effective := &domain.EffectiveConfig{Profile: name, Declared: ok, Sources: map[string]domain.ConfigSource{"cpus": domain.SourceBuiltIn}}
+	effective.Config = domain.ColimaConfig{Profile: name, CPUs: 12}
+	if ok && profile.CPUs != 0 {
+		effective.Config.CPUs = profile.CPUs
+		effective.Sources["cpus"] = domain.SourceProfile
+	}
+	return effective, nil
+}
+
+func TestProfileHandler(t *testing.T) {
+	profiles := &mockProfiles{profiles: map[string]config.ProfileConfig{"work": {CPUs: 2}}}
+	h := NewProfileHandler(profiles)
+	e := echo.New()
+	e.POST("/profiles", h.Create)
+	e.GET("/profiles/:name/config", h.Get)
+	e.GET("/profiles/:name/effective", h.Effective)
+	e.PUT("/profiles/:name", h.Update)
+	e.DELETE("/profiles/:name", h.Delete)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"create", http.MethodPost, "/profiles", `{"name":"k8s","cpus":4,"kubernetes":true}`, http.StatusCreated, `"cpus":4`},
+		{"create existing", http.MethodPost, "/profiles", `{"name":"k8s"}`, http.StatusConflict, `"code":"profile_exists"`},
+		{"create invalid", http.MethodPost, "/profiles", `{"cpus":1}`, http.StatusUnprocessableEntity, `"fields":[{"field":"profile"`},
+		{"get", http.MethodGet, "/profiles/k8s/config", "", http.StatusOK, `"kubernetes":true`},
+		{"get missing", http.MethodGet, "/profiles/nope/config", "", http.StatusNotFound, `"code":"profile_not_found"`},
+		{"effective", http.MethodGet, "/profiles/work/effective", "", http.StatusOK, `"sources":{"cpus":"profile"}`},
+		{"effective undeclared", http.MethodGet, "/profiles/nope/effective", "", http.StatusOK, `"declared":false`},
+		{"update", http.MethodPut, "/profiles/work", `{"cpus":8,"desired_state":"running"}`, http.StatusOK, `"desired_state":"running"`},
+		{"update missing", http.MethodPut, "/profiles/nope", `{"cpus":8}`, http.StatusNotFound, `"code":"profile_not_found"`},
+		{"delete", http.MethodDelete, "/profiles/k8s", "", http.StatusNoContent, ""},
+		{"delete missing", http.MethodDelete, "/profiles/k8s", "", http.StatusNotFound, `"code":"profile_not_found"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("Expected status code %d, got %d (%s)", tt.expectedCode, rec.Code, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.expectedBody, rec.Body)
+			}
+		})
+	}
+
+	if profiles.profiles["work"].CPUs != 8 {
+		t.Errorf("Expected work to be updated, got %+v", profiles.profiles["work"])
+	}
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type ReconcileHandler struct {
+	reconciler usecase.ReconcilerInterface
+}
+
+func NewReconcileHandler(reconciler usecase.ReconcilerInterface) *ReconcileHandler {
+	return &ReconcileHandler{reconciler: reconciler}
+}
+
+func (h *ReconcileHandler) Status(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.reconciler.Status())
+}
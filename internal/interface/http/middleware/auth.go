@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// Scope is a permission level; each scope includes the ones below it
+type Scope string
+
+const (
+	ScopeRead    Scope = "read"
+	ScopeOperate Scope = "operate"
+	ScopeDestroy Scope = "destroy"
+)
+
+// callerKey is the echo context key holding the authenticated token name
+const callerKey = "auth.caller"
+
+var scopeRank = map[Scope]int{
+	ScopeRead:    1,
+	ScopeOperate: 2,
+	ScopeDestroy: 3,
+}
+
+// Token is a credential with its granted scopes; only the hash is kept
+type Token struct {
+	Name   string
+	Hash   [sha256.Size]byte
+	Scopes []Scope
+}
+
+// allows reports whether the token grants required
+func (t Token) allows(required Scope) bool {
+	for _, scope := range t.Scopes {
+		if scopeRank[scope] >= scopeRank[required] {
+			return true
+		}
+	}
+	return false
+}
+
+type unixSocketKey struct{}
+
+// WithUnixSocket marks a connection context as coming from the unix socket
+func WithUnixSocket(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unixSocketKey{}, true)
+}
+
+// FromUnixSocket reports whether the request arrived on the unix socket
+func FromUnixSocket(req *http.Request) bool {
+	unix, _ := req.Context().Value(unixSocketKey{}).(bool)
+	return unix
+}
+
+// Authenticator checks bearer tokens against the configured scopes
+type Authenticator struct {
+	enabled         bool
+	trustUnixSocket bool
+	tokens          []Token
+	log             *logger.Logger
+}
+
+// NewAuthenticator builds an Authenticator from the auth config, reading
+// the token file if one is configured
+func NewAuthenticator(cfg config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		enabled:         cfg.Enabled,
+		trustUnixSocket: cfg.TrustUnixSocket,
+		log:             logger.GetLogger(),
+	}
+
+	for _, t := range cfg.Tokens {
+		token, err := newToken(t.Name, t.Token, t.SHA256, t.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		a.tokens = append(a.tokens, token)
+	}
+
+	if cfg.TokenFile != "" {
+		tokens, err := LoadTokenFile(cfg.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		a.tokens = append(a.tokens, tokens...)
+	}
+
+	if a.enabled && len(a.tokens) == 0 {
+		return nil, fmt.Errorf("auth is enabled but no tokens are configured")
+	}
+	return a, nil
+}
+
+// LoadTokenFile reads hashed tokens, one per line in the form
+// "<name> <sha256-hex> <scope>[,<scope>...]". Blank lines and lines
+// starting with # are ignored.
+func LoadTokenFile(path string) ([]Token, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token file: %w", err)
+	}
+	defer file.Close()
+
+	var tokens []Token
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("token file %s:%d: expected name, hash and scopes", path, lineNo)
+		}
+		token, err := newToken(fields[0], "", fields[1], strings.Split(fields[2], ","))
+		if err != nil {
+			return nil, fmt.Errorf("token file %s:%d: %w", path, lineNo, err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	return tokens, nil
+}
+
+func newToken(name, plain, hashHex string, scopes []string) (Token, error) {
+	token := Token{Name: name}
+	if name == "" {
+		return token, fmt.Errorf("token without a name")
+	}
+
+	switch {
+	case plain != "" && hashHex != "":
+		return token, fmt.Errorf("token %q: set either token or sha256, not both", name)
+	case plain != "":
+		token.Hash = sha256.Sum256([]byte(plain))
+	case hashHex != "":
+		hash, err := hex.DecodeString(hashHex)
+		if err != nil || len(hash) != sha256.Size {
+			return token, fmt.Errorf("token %q: invalid sha256 digest", name)
+		}
+		copy(token.Hash[:], hash)
+	default:
+		return token, fmt.Errorf("token %q: missing token or sha256", name)
+	}
+
+	if len(scopes) == 0 {
+		return token, fmt.Errorf("token %q: no scopes", name)
+	}
+	for _, s := range scopes {
+		scope := Scope(strings.TrimSpace(s))
+		if _, ok := scopeRank[scope]; !ok {
+			return token, fmt.Errorf("token %q: unknown scope %q", name, s)
+		}
+		token.Scopes = append(token.Scopes, scope)
+	}
+	return token, nil
+}
+
+// Enabled reports whether requests must carry a token
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Require rejects requests that do not carry a token granting scope
+func (a *Authenticator) Require(scope Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !a.enabled || (a.trustUnixSocket && FromUnixSocket(c.Request())) {
+				withCaller(c, "")
+				return next(c)
+			}
+
+			presented, ok := bearerToken(c.Request())
+			if !ok {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="colima-manager"`)
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "missing bearer token",
+					"code":  "unauthorized",
+				})
+			}
+
+			token, ok := a.lookup(presented)
+			if !ok {
+				a.log.Error("Rejected invalid token from %s", remoteIP(c.Request()))
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="colima-manager", error="invalid_token"`)
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "invalid bearer token",
+					"code":  "unauthorized",
+				})
+			}
+
+			if !token.allows(scope) {
+				a.log.Error("Token %s lacks scope %s for %s %s", token.Name, scope, c.Request().Method, c.Path())
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": fmt.Sprintf("token lacks required scope '%s'", scope),
+					"code":  "forbidden",
+				})
+			}
+
+			c.Set(callerKey, token.Name)
+			withCaller(c, token.Name)
+			return next(c)
+		}
+	}
+}
+
+// lookup finds the token matching presented, comparing hashes in constant time
+func (a *Authenticator) lookup(presented string) (Token, bool) {
+	hash := sha256.Sum256([]byte(presented))
+	var found Token
+	matched := false
+	for _, token := range a.tokens {
+		if subtle.ConstantTimeCompare(hash[:], token.Hash[:]) == 1 {
+			found = token
+			matched = true
+		}
+	}
+	return found, matched
+}
+
+// Caller returns the name of the token that authenticated the request, if any
+func Caller(c echo.Context) string {
+	name, _ := c.Get(callerKey).(string)
+	return name
+}
+
+// withCaller records the caller in the request context so the use case can
+// attribute the operation to it
+func withCaller(c echo.Context, name string) {
+	req := c.Request()
+	address := remoteIP(req)
+	if FromUnixSocket(req) {
+		address = "unix"
+	}
+	c.SetRequest(req.WithContext(domain.WithCaller(req.Context(), domain.Caller{Name: name, Address: address})))
+}
+
+// remoteIP returns the address the request came from. X-Forwarded-For and
+// X-Real-IP are ignored: any client can set them, and the address ends up in
+// the audit log.
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func bearerToken(req *http.Request) (string, bool) {
+	header := req.Header.Get(echo.HeaderAuthorization)
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(prefix):]), true
+}
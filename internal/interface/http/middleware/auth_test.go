@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireScopes(t *testing.T) {
+	auth, err := NewAuthenticator(config.AuthConfig{
+		Enabled: true,
+		Tokens: []config.AuthToken{
+			{Name: "viewer", Token: "read-token", Scopes: []string{"read"}},
+			{Name: "admin", Token: "admin-token", Scopes: []string{"destroy"}},
+		},
+	})
+	require.NoError(t, err)
+
+	e := echo.New()
+	var caller string
+	ok := func(c echo.Context) error {
+		caller = Caller(c)
+		assert.Equal(t, caller, domain.CallerFrom(c.Request().Context()).Name)
+		return c.NoContent(http.StatusOK)
+	}
+	e.GET("/status", ok, auth.Require(ScopeRead))
+	e.POST("/clean", ok, auth.Require(ScopeDestroy))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		header         string
+		expectedStatus int
+		expectedCaller string
+	}{
+		{"missing token", http.MethodGet, "/status", "", http.StatusUnauthorized, ""},
+		{"wrong scheme", http.MethodGet, "/status", "Basic read-token", http.StatusUnauthorized, ""},
+		{"invalid token", http.MethodGet, "/status", "Bearer nope", http.StatusUnauthorized, ""},
+		{"read allowed", http.MethodGet, "/status", "Bearer read-token", http.StatusOK, "viewer"},
+		{"destroy forbidden for read", http.MethodPost, "/clean", "Bearer read-token", http.StatusForbidden, ""},
+		{"destroy implies read", http.MethodGet, "/status", "Bearer admin-token", http.StatusOK, "admin"},
+		{"destroy allowed", http.MethodPost, "/clean", "bearer admin-token", http.StatusOK, "admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller = ""
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedCaller, caller)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, rec.Header().Get(echo.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
+
+func TestRequireDisabled(t *testing.T) {
+	auth, err := NewAuthenticator(config.AuthConfig{})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.POST("/clean", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, auth.Require(ScopeDestroy))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/clean", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestLoadTokenFile(t *testing.T) {
+	sum := sha256.Sum256([]byte("file-token"))
+	path := filepath.Join(t.TempDir(), "tokens")
+	content := "# name hash scopes\n\nci " + hex.EncodeToString(sum[:]) + " read,operate\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	auth, err := NewAuthenticator(config.AuthConfig{Enabled: true, TokenFile: path})
+	require.NoError(t, err)
+
+	token, ok := auth.lookup("file-token")
+	require.True(t, ok)
+	assert.Equal(t, "ci", token.Name)
+	assert.True(t, token.allows(ScopeOperate))
+	assert.False(t, token.allows(ScopeDestroy))
+}
+
+func TestNewAuthenticatorErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.AuthConfig
+	}{
+		{"enabled without tokens", config.AuthConfig{Enabled: true}},
+		{"unknown scope", config.AuthConfig{Tokens: []config.AuthToken{{Name: "x", Token: "t", Scopes: []string{"admin"}}}}},
+		{"no scopes", config.AuthConfig{Tokens: []config.AuthToken{{Name: "x", Token: "t"}}}},
+		{"bad digest", config.AuthConfig{Tokens: []config.AuthToken{{Name: "x", SHA256: "abc", Scopes: []string{"read"}}}}},
+		{"both secrets", config.AuthConfig{Tokens: []config.AuthToken{{Name: "x", Token: "t", SHA256: "abc", Scopes: []string{"read"}}}}},
+		{"missing file", config.AuthConfig{TokenFile: "/nonexistent/tokens"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAuthenticator(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRequireTrustsUnixSocket(t *testing.T) {
+	auth, err := NewAuthenticator(config.AuthConfig{
+		Enabled:         true,
+		TrustUnixSocket: true,
+		Tokens:          []config.AuthToken{{Name: "viewer", Token: "read-token", Scopes: []string{"read"}}},
+	})
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.POST("/clean", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, auth.Require(ScopeDestroy))
+
+	// TCP requests still need a token
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/clean", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Requests on the unix socket rely on file permissions instead
+	req := httptest.NewRequest(http.MethodPost, "/clean", nil)
+	req = req.WithContext(WithUnixSocket(req.Context()))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCallerAddressIgnoresForwardedHeaders(t *testing.T) {
+	auth, err := NewAuthenticator(config.AuthConfig{
+		Enabled: true,
+		Tokens:  []config.AuthToken{{Name: "admin", Token: "admin-token", Scopes: []string{"destroy"}}},
+	})
+	require.NoError(t, err)
+
+	e := echo.New()
+	var caller domain.Caller
+	e.POST("/clean", func(c echo.Context) error {
+		caller = domain.CallerFrom(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	}, auth.Require(ScopeDestroy))
+
+	req := httptest.NewRequest(http.MethodPost, "/clean", nil)
+	req.RemoteAddr = "192.0.2.7:51234"
+	req.Header.Set(echo.HeaderAuthorization, "Bearer admin-token")
+	req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.1")
+	req.Header.Set(echo.HeaderXRealIP, "203.0.113.2")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, domain.Caller{Name: "admin", Address: "192.0.2.7"}, caller)
+}
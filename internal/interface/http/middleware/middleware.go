@@ -5,11 +5,13 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// RequestLogger logs each request; run it after RequestID so the line
+// carries the request ID
 func RequestLogger(log *logger.Logger) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			req := c.Request()
-			log.Info("Request: %s %s", req.Method, req.URL.Path)
+			log.WithContext(req.Context()).Info("Request: %s %s", req.Method, req.URL.Path)
 			return next(c)
 		}
 	}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		reuse  bool
+	}{
+		{name: "generated when missing"},
+		{name: "reused when valid", header: "abc-123_x.y", reuse: true},
+		{name: "replaced when unsafe", header: "bad id\n"},
+		{name: "replaced when too long", header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			var seen string
+			handler := RequestID()(func(c echo.Context) error {
+				seen = logger.RequestID(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/status", nil)
+			if tt.header != "" {
+				req.Header.Set(echo.HeaderXRequestID, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			if err := handler(e.NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+
+			if seen == "" {
+				t.Fatal("no request ID in the request context")
+			}
+			if got := rec.Header().Get(echo.HeaderXRequestID); got != seen {
+				t.Errorf("response header = %q, context = %q", got, seen)
+			}
+			if tt.reuse && seen != tt.header {
+				t.Errorf("request ID = %q, want %q", seen, tt.header)
+			}
+			if !tt.reuse && seen == tt.header {
+				t.Errorf("invalid request ID %q was reused", tt.header)
+			}
+		})
+	}
+}
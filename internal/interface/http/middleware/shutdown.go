@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// RejectWhileShuttingDown answers mutating requests with 503 once
+// shuttingDown reports true. Reads keep working so clients can follow the
+// jobs that are draining.
+func RejectWhileShuttingDown(shuttingDown func() bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+			if shuttingDown() {
+				return c.JSON(http.StatusServiceUnavailable, domain.NewErrorDetail(&domain.ShuttingDownError{}))
+			}
+			return next(c)
+		}
+	}
+}
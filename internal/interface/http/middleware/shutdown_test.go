@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRejectWhileShuttingDown(t *testing.T) {
+	shuttingDown := false
+	e := echo.New()
+	e.Use(RejectWhileShuttingDown(func() bool { return shuttingDown }))
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.GET("/jobs", ok)
+	e.POST("/start", ok)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/start").Code)
+
+	shuttingDown = true
+	rec := serve(http.MethodPost, "/start")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"shutting_down"`)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/jobs").Code)
+}
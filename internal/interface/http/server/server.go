@@ -0,0 +1,303 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/interface/http/middleware"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+// defaultSocketMode restricts the socket to its owner unless configured
+const defaultSocketMode os.FileMode = 0600
+
+// Config describes where the HTTP API listens
+type Config struct {
+	// Listen holds unix:///path or tcp://host:port addresses
+	Listen      []string
+	SocketMode  os.FileMode
+	SocketGroup string
+}
+
+// Address is a parsed listen address
+type Address struct {
+	Network string // "tcp" or "unix"
+	Address string
+}
+
+func (a Address) String() string {
+	return a.Network + "://" + a.Address
+}
+
+// Loopback reports whether only local processes can connect to a: a unix
+// socket, or a tcp address on localhost or a loopback IP. An empty host
+// listens on every interface.
+func (a Address) Loopback() bool {
+	if a.Network == "unix" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(a.Address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ParseAddress parses unix:///path, tcp://host:port or a bare host:port
+func ParseAddress(s string) (Address, error) {
+	switch {
+	case strings.HasPrefix(s, "unix://"):
+		path := strings.TrimPrefix(s, "unix://")
+		if path == "" {
+			return Address{}, fmt.Errorf("listen address %q has no socket path", s)
+		}
+		return Address{Network: "unix", Address: path}, nil
+	case strings.HasPrefix(s, "tcp://"):
+		s = strings.TrimPrefix(s, "tcp://")
+	case strings.Contains(s, "://"):
+		return Address{}, fmt.Errorf("unsupported listen address %q", s)
+	}
+
+	if _, _, err := net.SplitHostPort(s); err != nil {
+		return Address{}, fmt.Errorf("invalid tcp listen address %q: %w", s, err)
+	}
+	return Address{Network: "tcp", Address: s}, nil
+}
+
+// ParseSocketMode parses an octal file mode such as "0660"; empty means the default
+func ParseSocketMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return defaultSocketMode, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid socket mode %q", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// Server serves one handler on several listeners at once
+type Server struct {
+	servers   []*http.Server
+	listeners []net.Listener
+	sockets   []string
+	log       *logger.Logger
+}
+
+// New opens every configured listener. Nothing is served until Serve is called.
+func New(handler http.Handler, cfg Config) (*Server, error) {
+	s := &Server{log: logger.GetLogger()}
+
+	for _, raw := range cfg.Listen {
+		addr, err := ParseAddress(raw)
+		if err != nil {
+			s.closeListeners()
+			return nil, err
+		}
+
+		var ln net.Listener
+		srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		if addr.Network == "unix" {
+			ln, err = listenUnix(addr.Address, cfg.SocketMode, cfg.SocketGroup)
+			srv.ConnContext = func(ctx context.Context, _ net.Conn) context.Context {
+				return middleware.WithUnixSocket(ctx)
+			}
+			if err == nil {
+				s.sockets = append(s.sockets, addr.Address)
+			}
+		} else {
+			ln, err = net.Listen("tcp", addr.Address)
+		}
+		if err != nil {
+			s.closeListeners()
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+
+		s.servers = append(s.servers, srv)
+		s.listeners = append(s.listeners, ln)
+	}
+
+	if len(s.listeners) == 0 {
+		return nil, fmt.Errorf("no listen addresses configured")
+	}
+	return s, nil
+}
+
+// Addrs returns the addresses actually bound, useful with port 0
+func (s *Server) Addrs() []net.Addr {
+	addrs := make([]net.Addr, len(s.listeners))
+	for i, ln := range s.listeners {
+		addrs[i] = ln.Addr()
+	}
+	return addrs
+}
+
+// Serve serves on all listeners and returns once every listener has stopped.
+// The first unexpected error is returned.
+func (s *Server) Serve() error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i := range s.servers {
+		srv, ln := s.servers[i], s.listeners[i]
+		s.log.Info("HTTP server listening on %s://%s", ln.Addr().Network(), ln.Addr().String())
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// Shutdown gracefully stops all servers and removes unix socket files.
+// Connections still open when ctx is done, such as event streams, are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var firstErr error
+	for _, srv := range s.servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			srv.Close()
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	for _, path := range s.sockets {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			s.log.Error("Failed to remove socket %s: %v", path, err)
+		}
+	}
+	return firstErr
+}
+
+func (s *Server) closeListeners() {
+	for _, ln := range s.listeners {
+		ln.Close()
+	}
+}
+
+// listenUnix creates the socket at path with the given mode and group,
+// replacing a stale socket left behind by a previous run
+func listenUnix(path string, mode os.FileMode, group string) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	// Bind in a directory only we can enter and move the socket into place
+	// once its mode and group are set, so it is never reachable with the
+	// permissions the process umask would give it
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	defer os.Remove(dir)
+	tmp := filepath.Join(dir, "s")
+
+	ln, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// The socket will not be at tmp when the listener closes
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	fail := func(err error) (net.Listener, error) {
+		ln.Close()
+		os.Remove(tmp)
+		return nil, err
+	}
+
+	if mode == 0 {
+		mode = defaultSocketMode
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		return fail(fmt.Errorf("failed to set socket mode: %w", err))
+	}
+	if group != "" {
+		gid, err := lookupGroup(group)
+		if err != nil {
+			return fail(err)
+		}
+		if err := os.Chown(tmp, -1, gid); err != nil {
+			return fail(fmt.Errorf("failed to set socket group: %w", err))
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fail(fmt.Errorf("failed to move socket into place: %w", err))
+	}
+	return &socketListener{Listener: ln, path: path}, nil
+}
+
+// socketListener reports the path its socket was moved to and removes it
+// when closed
+type socketListener struct {
+	net.Listener
+	path string
+}
+
+func (l *socketListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: l.path, Net: "unix"}
+}
+
+func (l *socketListener) Close() error {
+	err := l.Listener.Close()
+	if removeErr := os.Remove(l.path); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// removeStaleSocket deletes path if it is a socket nobody is listening on
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+// lookupGroup resolves a group name or numeric ID
+func lookupGroup(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, fmt.Errorf("unknown socket group %q: %w", group, err)
+	}
+	return strconv.Atoi(g.Gid)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/interface/http/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Address
+		wantErr  bool
+	}{
+		{input: "unix:///tmp/manager.sock", expected: Address{Network: "unix", Address: "/tmp/manager.sock"}},
+		{input: "tcp://localhost:8080", expected: Address{Network: "tcp", Address: "localhost:8080"}},
+		{input: "127.0.0.1:9090", expected: Address{Network: "tcp", Address: "127.0.0.1:9090"}},
+		{input: "unix://", wantErr: true},
+		{input: "http://localhost:8080", wantErr: true},
+		{input: "tcp://localhost", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			addr, err := ParseAddress(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, addr)
+		})
+	}
+}
+
+func TestAddressLoopback(t *testing.T) {
+	for input, want := range map[string]bool{
+		"unix:///tmp/manager.sock": true,
+		"tcp://localhost:8080":     true,
+		"127.0.0.1:8080":           true,
+		"[::1]:8080":               true,
+		"0.0.0.0:8080":             false,
+		":8080":                    false,
+		"[::]:8080":                false,
+		"192.168.1.10:8080":        false,
+		"manager.lan:8080":         false,
+	} {
+		addr, err := ParseAddress(input)
+		require.NoError(t, err)
+		assert.Equal(t, want, addr.Loopback(), input)
+	}
+}
+
+func TestParseSocketMode(t *testing.T) {
+	mode, err := ParseSocketMode("")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), mode)
+
+	mode, err = ParseSocketMode("0660")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0660), mode)
+
+	_, err = ParseSocketMode("999")
+	assert.Error(t, err)
+}
+
+func TestServeTCPAndUnix(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "manager.sock")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if middleware.FromUnixSocket(r) {
+			io.WriteString(w, "unix")
+			return
+		}
+		io.WriteString(w, "tcp")
+	})
+
+	srv, err := New(handler, Config{
+		Listen:     []string{"tcp://127.0.0.1:0", "unix://" + socketPath},
+		SocketMode: 0660,
+	})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve() }()
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+	assert.Equal(t, socketPath, srv.Addrs()[1].String())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "expected the private socket directory to be removed")
+
+	// TCP listener
+	resp, err := http.Get("http://" + srv.Addrs()[0].String())
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "tcp", string(body))
+
+	// Unix socket listener
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		},
+	}}
+	resp, err = client.Get("http://unix/")
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "unix", string(body))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, srv.Shutdown(ctx))
+	require.NoError(t, <-done)
+
+	_, err = os.Stat(socketPath)
+	assert.True(t, os.IsNotExist(err), "expected socket to be removed")
+}
+
+func TestStaleSocketIsReplaced(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "stale.sock")
+
+	// Leave a socket file behind without anyone listening on it
+	ln, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+
+	srv, err := New(http.NotFoundHandler(), Config{Listen: []string{"unix://" + socketPath}})
+	require.NoError(t, err)
+	require.NoError(t, srv.Shutdown(context.Background()))
+}
+
+func TestSocketInUse(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "busy.sock")
+
+	ln, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	_, err = New(http.NotFoundHandler(), Config{Listen: []string{"unix://" + socketPath}})
+	assert.Error(t, err)
+}
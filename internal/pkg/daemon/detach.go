@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// detachedEnv marks the re-executed child so it does not detach again
+const detachedEnv = "COLIMA_MANAGER_DETACHED"
+
+// IsDetached reports whether this process is the detached daemon
+func IsDetached() bool {
+	return os.Getenv(detachedEnv) == "1"
+}
+
+// readPIDFile returns the PID written to path without locking it
+func readPIDFile(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return readPID(file)
+}
+
+// Detach re-executes the current binary with args in a new session without
+// a terminal, then waits up to timeout for it to lock the PID file at
+// pidPath. It returns the daemon's PID.
+func Detach(args []string, pidPath string, timeout time.Duration) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", os.DevNull, err)
+	}
+	defer devNull.Close()
+
+	cmd := exec.Command(exe, args...)
+	cmd.Env = append(os.Environ(), detachedEnv+"=1")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = devNull, devNull, devNull
+	newSession(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start daemon: %w", err)
+	}
+	pid := cmd.Process.Pid
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	ticker := time.NewTicker(stopPollInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case err := <-exited:
+			return pid, fmt.Errorf("daemon exited during startup: %v", err)
+		case <-deadline:
+			return pid, fmt.Errorf("daemon process %d did not lock %s within %s", pid, pidPath, timeout)
+		case <-ticker.C:
+			// The child writes its PID only once it holds the lock. Reading
+			// it, rather than probing the lock, keeps us from colliding with
+			// the child's Acquire.
+			if written, err := readPIDFile(pidPath); err == nil && written == pid {
+				return pid, nil
+			}
+		}
+	}
+}
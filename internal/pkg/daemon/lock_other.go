@@ -0,0 +1,23 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package daemon
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+var errUnsupported = errors.New("PID file locking is not supported on this platform")
+
+func lockFile(file *os.File) error {
+	return errUnsupported
+}
+
+func probeLock(file *os.File) error {
+	return errUnsupported
+}
+
+func unlockFile(file *os.File) {}
+
+func newSession(cmd *exec.Cmd) {}
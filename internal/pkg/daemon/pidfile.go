@@ -0,0 +1,202 @@
+// Package daemon runs colima-manager in the background and keeps a locked
+// PID file so that only one daemon runs at a time.
+package daemon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// stopPollInterval is how often Stop checks whether the daemon has exited
+const stopPollInterval = 100 * time.Millisecond
+
+// lockAttempts and lockRetryInterval bound how long Acquire waits out
+// Running probes before deciding another daemon holds the lock
+const (
+	lockAttempts      = 5
+	lockRetryInterval = 10 * time.Millisecond
+)
+
+// errLocked is returned by lockFile when another process holds the lock
+var errLocked = errors.New("file is locked")
+
+// AlreadyRunningError is returned when another daemon holds the PID file
+type AlreadyRunningError struct {
+	PID  int
+	Path string
+}
+
+func (e *AlreadyRunningError) Error() string {
+	return fmt.Sprintf("colima-manager is already running (PID %d, %s)", e.PID, e.Path)
+}
+
+// NotRunningError is returned when no daemon holds the PID file
+type NotRunningError struct {
+	Path string
+}
+
+func (e *NotRunningError) Error() string {
+	return fmt.Sprintf("colima-manager is not running (no live daemon holds %s)", e.Path)
+}
+
+// PIDFile is a PID file locked with flock for the life of the daemon. The
+// lock, not the PID, is what proves a daemon is alive: the kernel drops it
+// when the process dies, so a leftover file is recognized as stale.
+type PIDFile struct {
+	path     string
+	file     *os.File
+	stalePID int
+}
+
+// Acquire locks path and writes the current PID to it
+func Acquire(path string) (*PIDFile, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PID file: %w", err)
+	}
+
+	if err := lockWithRetry(file); err != nil {
+		pid, _ := readPID(file)
+		file.Close()
+		if errors.Is(err, errLocked) {
+			return nil, &AlreadyRunningError{PID: pid, Path: path}
+		}
+		return nil, fmt.Errorf("failed to lock PID file: %w", err)
+	}
+
+	// We hold the lock, so any PID already in the file is stale
+	stalePID, _ := readPID(file)
+
+	if err := file.Truncate(0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate PID file: %w", err)
+	}
+	if _, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write PID file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write PID file: %w", err)
+	}
+
+	return &PIDFile{path: path, file: file, stalePID: stalePID}, nil
+}
+
+// Path returns the location of the PID file
+func (p *PIDFile) Path() string {
+	return p.path
+}
+
+// StalePID returns the PID left behind by a daemon that died without
+// cleaning up, or 0
+func (p *PIDFile) StalePID() int {
+	return p.stalePID
+}
+
+// Release empties the PID file and drops the lock. The file is left in
+// place: unlinking it would let a new daemon lock a fresh file while a
+// process still holding the old one believes it owns the PID file.
+func (p *PIDFile) Release() error {
+	// Empty it while still holding the lock so a new daemon never sees our PID
+	truncErr := p.file.Truncate(0)
+	unlockFile(p.file)
+	closeErr := p.file.Close()
+	if truncErr != nil {
+		return fmt.Errorf("failed to empty PID file: %w", truncErr)
+	}
+	return closeErr
+}
+
+// Running returns the PID of the daemon holding path
+func Running(path string) (int, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, &NotRunningError{Path: path}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open PID file: %w", err)
+	}
+	defer file.Close()
+
+	err = probeLock(file)
+	if err == nil {
+		// Nobody holds the lock: the file is stale
+		unlockFile(file)
+		return 0, &NotRunningError{Path: path}
+	}
+	if !errors.Is(err, errLocked) {
+		return 0, fmt.Errorf("failed to check PID file lock: %w", err)
+	}
+
+	pid, err := readPID(file)
+	if err != nil {
+		return 0, fmt.Errorf("invalid PID file %s: %w", path, err)
+	}
+	return pid, nil
+}
+
+// Stop sends SIGTERM to the daemon holding path and waits up to timeout for
+// it to exit. It returns the daemon's PID.
+func Stop(path string, timeout time.Duration) (int, error) {
+	pid, err := Running(path)
+	if err != nil {
+		return 0, err
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, fmt.Errorf("failed to find daemon process %d: %w", pid, err)
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return pid, fmt.Errorf("failed to signal daemon process %d: %w", pid, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		_, err := Running(path)
+		var notRunning *NotRunningError
+		if errors.As(err, &notRunning) {
+			return pid, nil
+		}
+		if time.Now().After(deadline) {
+			return pid, fmt.Errorf("daemon process %d did not exit within %s", pid, timeout)
+		}
+		time.Sleep(stopPollInterval)
+	}
+}
+
+// lockWithRetry takes the daemon's exclusive lock, retrying briefly since a
+// concurrent Running probe holds a shared lock for a moment
+func lockWithRetry(file *os.File) error {
+	var err error
+	for attempt := 0; attempt < lockAttempts; attempt++ {
+		if err = lockFile(file); !errors.Is(err, errLocked) {
+			return err
+		}
+		time.Sleep(lockRetryInterval)
+	}
+	return err
+}
+
+func readPID(file *os.File) (int, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return 0, errors.New("empty PID file")
+	}
+	return strconv.Atoi(line)
+}
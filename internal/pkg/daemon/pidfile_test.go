@@ -0,0 +1,138 @@
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helperPIDFileEnv makes the test binary act as a daemon holding the PID file
+const helperPIDFileEnv = "DAEMON_TEST_PID_FILE"
+
+func TestMain(m *testing.M) {
+	if path := os.Getenv(helperPIDFileEnv); path != "" {
+		runHelperDaemon(path)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// runHelperDaemon holds the PID file until SIGTERM
+func runHelperDaemon(path string) {
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM)
+
+	pidFile, err := Acquire(path)
+	if err != nil {
+		os.Exit(1)
+	}
+	<-quit
+	pidFile.Release()
+	os.Exit(0)
+}
+
+func TestAcquireAndRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manager.pid")
+
+	pidFile, err := Acquire(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, pidFile.StalePID())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(data))
+
+	pid, err := Running(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+
+	_, err = Acquire(path)
+	var running *AlreadyRunningError
+	require.ErrorAs(t, err, &running)
+	assert.Equal(t, os.Getpid(), running.PID)
+
+	// The file stays, emptied, so nobody can lock a second inode at path
+	require.NoError(t, pidFile.Release())
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	_, err = Running(path)
+	var notRunning *NotRunningError
+	assert.ErrorAs(t, err, &notRunning)
+}
+
+func TestAcquireDuringProbe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manager.pid")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+
+	// Hold the shared lock Running takes while a daemon starts
+	probe, err := os.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, probeLock(probe))
+	released := make(chan struct{})
+	go func() {
+		defer close(released)
+		time.Sleep(2 * lockRetryInterval)
+		unlockFile(probe)
+	}()
+
+	pidFile, err := Acquire(path)
+	// Close the probe only once the goroutine is done with its descriptor
+	<-released
+	probe.Close()
+	require.NoError(t, err, "a probe must not make Acquire report a running daemon")
+	require.NoError(t, pidFile.Release())
+}
+
+func TestStalePIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manager.pid")
+	require.NoError(t, os.WriteFile(path, []byte("999999\n"), 0644))
+
+	_, err := Running(path)
+	var notRunning *NotRunningError
+	require.ErrorAs(t, err, &notRunning)
+
+	pidFile, err := Acquire(path)
+	require.NoError(t, err)
+	defer pidFile.Release()
+	assert.Equal(t, 999999, pidFile.StalePID())
+}
+
+func TestStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manager.pid")
+
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), helperPIDFileEnv+"="+path)
+	require.NoError(t, cmd.Start())
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	require.Eventually(t, func() bool {
+		pid, err := Running(path)
+		return err == nil && pid == cmd.Process.Pid
+	}, 5*time.Second, 10*time.Millisecond)
+
+	pid, err := Stop(path, 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, cmd.Process.Pid, pid)
+
+	select {
+	case err := <-exited:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("helper daemon did not exit")
+	}
+
+	_, err = Stop(path, time.Second)
+	var notRunning *NotRunningError
+	assert.ErrorAs(t, err, &notRunning)
+}
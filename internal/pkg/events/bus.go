@@ -0,0 +1,104 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it
+const subscriberBuffer = 64
+
+// Bus fans out published events to all interested subscribers
+type Bus struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[*subscription]struct{}
+	closed bool
+}
+
+type subscription struct {
+	profile string
+	ch      chan domain.Event
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[*subscription]struct{}),
+	}
+}
+
+// Publish assigns the event an ID and timestamp and delivers it without
+// blocking; subscribers whose buffer is full miss the event
+func (b *Bus) Publish(event domain.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event.ID = b.nextID
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	for sub := range b.subs {
+		if sub.profile != "" && sub.profile != event.Profile {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of events for profile, or for every profile
+// when profile is empty, and a function that ends the subscription. The
+// channel is closed once the bus is.
+func (b *Bus) Subscribe(profile string) (<-chan domain.Event, func()) {
+	sub := &subscription{
+		profile: profile,
+		ch:      make(chan domain.Event, subscriberBuffer),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	b.subs[sub] = struct{}{}
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Close sends every subscriber an EventShutdown and closes their channels,
+// so that streams end while the server shuts down instead of holding it up.
+// Events published afterwards are dropped.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.nextID++
+	event := domain.Event{ID: b.nextID, Type: domain.EventShutdown, Time: time.Now()}
+	// Every subscriber gets it, whatever profile it follows
+	for sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		default:
+		}
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+	b.closed = true
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusFiltersByProfile(t *testing.T) {
+	bus := NewBus()
+
+	all, unsubscribeAll := bus.Subscribe("")
+	defer unsubscribeAll()
+	work, unsubscribeWork := bus.Subscribe("work")
+	defer unsubscribeWork()
+
+	bus.Publish(domain.Event{Type: domain.EventStarted, Profile: "default"})
+	bus.Publish(domain.Event{Type: domain.EventStopped, Profile: "work"})
+
+	first := <-all
+	assert.Equal(t, uint64(1), first.ID)
+	assert.Equal(t, "default", first.Profile)
+	assert.False(t, first.Time.IsZero())
+
+	second := <-all
+	assert.Equal(t, uint64(2), second.ID)
+
+	got := <-work
+	assert.Equal(t, domain.EventStopped, got.Type)
+	assert.Equal(t, "work", got.Profile)
+	assert.Empty(t, work)
+}
+
+func TestBusUnsubscribe(t *testing.T) {
+	bus := NewBus()
+
+	ch, unsubscribe := bus.Subscribe("")
+	unsubscribe()
+	unsubscribe()
+
+	bus.Publish(domain.Event{Type: domain.EventStarted})
+	_, ok := <-ch
+	require.False(t, ok, "expected channel to be closed")
+}
+
+func TestBusDropsForSlowSubscribers(t *testing.T) {
+	bus := NewBus()
+
+	ch, unsubscribe := bus.Subscribe("")
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		bus.Publish(domain.Event{Type: domain.EventStarted})
+	}
+	assert.Len(t, ch, subscriberBuffer)
+}
+
+func TestBusClose(t *testing.T) {
+	bus := NewBus()
+
+	ch, unsubscribe := bus.Subscribe("work")
+	bus.Close()
+	unsubscribe()
+
+	event, ok := <-ch
+	require.True(t, ok, "expected a final event")
+	assert.Equal(t, domain.EventShutdown, event.Type)
+	_, ok = <-ch
+	assert.False(t, ok, "expected channel to be closed")
+
+	late, _ := bus.Subscribe("")
+	_, ok = <-late
+	assert.False(t, ok, "expected subscriptions after Close to be closed")
+}
@@ -0,0 +1,17 @@
+// Package hostinfo reports the resources of the machine colima runs on
+package hostinfo
+
+import (
+	"runtime"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+// Capacity returns the CPUs and memory of this host. Memory is zero where it
+// cannot be determined.
+func Capacity() domain.HostCapacity {
+	return domain.HostCapacity{
+		CPUs:   runtime.NumCPU(),
+		Memory: int(totalMemory() >> 30),
+	}
+}
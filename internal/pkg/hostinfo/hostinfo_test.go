@@ -0,0 +1,13 @@
+package hostinfo
+
+import "testing"
+
+func TestCapacity(t *testing.T) {
+	host := Capacity()
+	if host.CPUs < 1 {
+		t.Errorf("Expected at least one CPU, got %d", host.CPUs)
+	}
+	if host.Memory < 0 {
+		t.Errorf("Expected memory not to be negative, got %d", host.Memory)
+	}
+}
@@ -0,0 +1,18 @@
+package hostinfo
+
+import (
+	"encoding/binary"
+	"syscall"
+)
+
+// totalMemory returns the installed memory in bytes
+func totalMemory() uint64 {
+	value, err := syscall.Sysctl("hw.memsize")
+	if err != nil {
+		return 0
+	}
+	// Sysctl drops the trailing NUL byte it takes for a string terminator
+	buf := make([]byte, 8)
+	copy(buf, value)
+	return binary.LittleEndian.Uint64(buf)
+}
@@ -0,0 +1,12 @@
+package hostinfo
+
+import "syscall"
+
+// totalMemory returns the installed memory in bytes
+func totalMemory() uint64 {
+	var info syscall.Sysinfo_t
+	if err := syscall.Sysinfo(&info); err != nil {
+		return 0
+	}
+	return uint64(info.Totalram) * uint64(info.Unit)
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package hostinfo
+
+// totalMemory is unknown on this platform
+func totalMemory() uint64 {
+	return 0
+}
@@ -1,88 +1,204 @@
 package logger
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// LevelFatal is logged by Fatal right before the process exits
+const LevelFatal = slog.LevelError + 4
+
+// Output formats accepted by Configure
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options selects the level, format and destination of log output
+type Options struct {
+	Level  string // debug, info, warn or error; default info
+	Format string // text or json; default text
+	// Output is stdout (default), stderr, journald, syslog or a file path.
+	// journald writes syslog priority-prefixed lines to stderr.
+	Output string
+	// Rotation applies to file outputs only
+	Rotation Rotation
+}
+
+// Logger logs printf-style messages through log/slog. A Logger bound to a
+// context with WithContext adds the context's request ID to every record.
 type Logger struct {
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
-	debugLogger *log.Logger
+	ctx context.Context
 }
 
 var (
-	defaultLogger *Logger
-	logFile       *os.File
+	defaultLogger              = &Logger{ctx: context.Background()}
+	console       io.Writer    = os.Stdout
+	level                      = new(slog.LevelVar)
+	current       atomic.Value // *output
 )
 
-func init() {
-	// Create logs directory if it doesn't exist
-	if err := os.MkdirAll("logs", 0755); err != nil {
-		log.Fatalf("Failed to create logs directory: %v", err)
+// output pairs a record format with the sink receiving formatted records
+type output struct {
+	format func(w io.Writer) slog.Handler
+	sink   sink
+}
+
+// active returns the output installed by Configure, or text on the console
+// until Configure is called. Nothing touches the filesystem before then.
+func active() *output {
+	if out, ok := current.Load().(*output); ok {
+		return out
+	}
+	return &output{format: textFormat(true), sink: consoleSink{}}
+}
+
+// Configure installs the output described by opts. Loggers obtained earlier
+// switch to it as well, and it becomes the slog default. The previous output
+// is closed.
+func Configure(opts Options) error {
+	var lvl slog.Level
+	if opts.Level != "" {
+		if err := lvl.UnmarshalText([]byte(opts.Level)); err != nil {
+			return fmt.Errorf("invalid log level %q", opts.Level)
+		}
 	}
 
-	// Create or open log file with timestamp
-	timestamp := time.Now().Format("2006-01-02")
-	logPath := filepath.Join("logs", fmt.Sprintf("colima-manager-%s.log", timestamp))
-	var err error
-	logFile, err = os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	// Syslog and journald stamp records themselves
+	withTime := opts.Output != OutputJournald && opts.Output != OutputSyslog
+	var format func(io.Writer) slog.Handler
+	switch opts.Format {
+	case "", FormatText:
+		format = textFormat(withTime)
+	case FormatJSON:
+		format = jsonFormat(withTime)
+	default:
+		return fmt.Errorf("invalid log format %q (want text or json)", opts.Format)
+	}
+
+	s, err := openSink(opts.Output, opts.Rotation)
 	if err != nil {
-		log.Fatalf("Failed to open log file: %v", err)
+		return err
 	}
 
-	// Create multi-writer for both file and stdout
-	multiWriter := MultiWriter{writers: []Writer{
-		&FileWriter{file: logFile},
-		&ConsoleWriter{},
-	}}
-
-	// Initialize default logger
-	defaultLogger = &Logger{
-		infoLogger:  log.New(multiWriter, "INFO: ", log.Ldate|log.Ltime),
-		errorLogger: log.New(multiWriter, "ERROR: ", log.Ldate|log.Ltime),
-		debugLogger: log.New(multiWriter, "DEBUG: ", log.Ldate|log.Ltime),
+	level.Set(lvl)
+	previous, _ := current.Swap(&output{format: format, sink: s}).(*output)
+	slog.SetDefault(slog.New(rootHandler{}))
+	if previous != nil {
+		previous.sink.Close()
+	}
+	return nil
+}
+
+// Close closes the configured output and falls back to the console
+func Close() {
+	if previous, ok := current.Swap(&output{format: textFormat(true), sink: consoleSink{}}).(*output); ok {
+		previous.sink.Close()
 	}
 }
 
-// Writer interface for different output destinations
-type Writer interface {
-	Write(p []byte) (n int, err error)
+func textFormat(withTime bool) func(io.Writer) slog.Handler {
+	opts := handlerOptions(withTime)
+	return func(w io.Writer) slog.Handler { return slog.NewTextHandler(w, opts) }
 }
 
-// MultiWriter writes to multiple writers
-type MultiWriter struct {
-	writers []Writer
+func jsonFormat(withTime bool) func(io.Writer) slog.Handler {
+	opts := handlerOptions(withTime)
+	return func(w io.Writer) slog.Handler { return slog.NewJSONHandler(w, opts) }
 }
 
-func (mw MultiWriter) Write(p []byte) (n int, err error) {
-	for _, w := range mw.writers {
-		n, err = w.Write(p)
-		if err != nil {
-			return
+func handlerOptions(withTime bool) *slog.HandlerOptions {
+	return &slog.HandlerOptions{
+		AddSource: true,
+		Level:     level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if !withTime && len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return replaceAttr(groups, a)
+		},
+	}
+}
+
+// replaceAttr shortens sources to file:line and names the fatal level
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.SourceKey:
+		if src, ok := a.Value.Any().(*slog.Source); ok {
+			a.Value = slog.StringValue(fmt.Sprintf("%s:%d", filepath.Base(src.File), src.Line))
+		}
+	case slog.LevelKey:
+		if lvl, ok := a.Value.Any().(slog.Level); ok && lvl >= LevelFatal {
+			a.Value = slog.StringValue("FATAL")
 		}
 	}
-	return len(p), nil
+	return a
+}
+
+// rootHandler formats records with the output installed by Configure and
+// adds the request ID found in the record's context
+type rootHandler struct {
+	steps []step
+}
+
+// step is a WithAttrs or WithGroup call, replayed in order on each record
+type step struct {
+	group string
+	attrs []slog.Attr
 }
 
-// FileWriter writes to a file
-type FileWriter struct {
-	file *os.File
+func (h rootHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= level.Level()
+}
+
+func (h rootHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+
+	out := active()
+	var buf bytes.Buffer
+	handler := out.format(&buf)
+	for _, s := range h.steps {
+		if s.group != "" {
+			handler = handler.WithGroup(s.group)
+		} else {
+			handler = handler.WithAttrs(s.attrs)
+		}
+	}
+	if err := handler.Handle(ctx, r); err != nil {
+		return err
+	}
+	return out.sink.Write(r.Level, buf.Bytes())
 }
 
-func (fw *FileWriter) Write(p []byte) (n int, err error) {
-	return fw.file.Write(p)
+func (h rootHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return rootHandler{steps: append(h.steps[:len(h.steps):len(h.steps)], step{attrs: attrs})}
 }
 
-// ConsoleWriter writes to stdout
-type ConsoleWriter struct{}
+func (h rootHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return rootHandler{steps: append(h.steps[:len(h.steps):len(h.steps)], step{group: name})}
+}
 
-func (cw *ConsoleWriter) Write(p []byte) (n int, err error) {
-	return os.Stdout.Write(p)
+// SetConsoleOutput redirects stdout logging to w, e.g. so CLI commands
+// keep stdout free for their own output
+func SetConsoleOutput(w io.Writer) {
+	console = w
 }
 
 // GetLogger returns the default logger instance
@@ -90,54 +206,61 @@ func GetLogger() *Logger {
 	return defaultLogger
 }
 
-// Close closes the log file
-func Close() {
-	if logFile != nil {
-		logFile.Close()
-	}
+// Slog returns a *slog.Logger writing through the configured output, for
+// code that wants structured attributes
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(rootHandler{})
+}
+
+// WithContext returns a logger that tags its records with the request ID in ctx
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	return &Logger{ctx: ctx}
 }
 
-// Helper function to get file and line number
-func getCallerInfo() string {
-	_, file, line, ok := runtime.Caller(2)
-	if !ok {
-		file = "unknown"
-		line = 0
+// log emits a record whose source is the caller of the exported method
+func (l *Logger) log(lvl slog.Level, msg string) {
+	handler := rootHandler{}
+	if !handler.Enabled(l.ctx, lvl) {
+		return
 	}
-	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), lvl, strings.TrimSuffix(msg, "\n"), pcs[0])
+	_ = handler.Handle(l.ctx, r)
 }
 
 // Info logs an info message with caller information
 func (l *Logger) Info(format string, v ...interface{}) {
-	caller := getCallerInfo()
-	l.infoLogger.Printf("%s - "+format, append([]interface{}{caller}, v...)...)
+	l.log(slog.LevelInfo, fmt.Sprintf(format, v...))
+}
+
+// Warn logs a warning with caller information
+func (l *Logger) Warn(format string, v ...interface{}) {
+	l.log(slog.LevelWarn, fmt.Sprintf(format, v...))
 }
 
 // Error logs an error message with caller information
 func (l *Logger) Error(format string, v ...interface{}) {
-	caller := getCallerInfo()
-	l.errorLogger.Printf("%s - "+format, append([]interface{}{caller}, v...)...)
+	l.log(slog.LevelError, fmt.Sprintf(format, v...))
 }
 
-// Debug logs a debug message with caller information
+// Debug logs a debug message with caller information; it is dropped unless
+// the level is debug
 func (l *Logger) Debug(format string, v ...interface{}) {
-	caller := getCallerInfo()
-	l.debugLogger.Printf("%s - "+format, append([]interface{}{caller}, v...)...)
+	l.log(slog.LevelDebug, fmt.Sprintf(format, v...))
 }
 
 // LogError logs an error and returns it
 func (l *Logger) LogError(err error, format string, v ...interface{}) error {
 	if err != nil {
-		caller := getCallerInfo()
-		msg := fmt.Sprintf(format, v...)
-		l.errorLogger.Printf("%s - %s: %v", caller, msg, err)
+		l.log(slog.LevelError, fmt.Sprintf("%s: %v", fmt.Sprintf(format, v...), err))
 	}
 	return err
 }
 
-// Fatal logs a fatal error and exits
+// Fatal logs a fatal error, closes the output and exits
 func (l *Logger) Fatal(format string, v ...interface{}) {
-	caller := getCallerInfo()
-	l.errorLogger.Printf("%s - FATAL: "+format, append([]interface{}{caller}, v...)...)
+	l.log(LevelFatal, fmt.Sprintf(format, v...))
+	Close()
 	os.Exit(1)
 }
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// capture sends log output to a buffer for the duration of the test
+func capture(t *testing.T, opts Options) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	SetConsoleOutput(&buf)
+	t.Cleanup(func() {
+		SetConsoleOutput(os.Stdout)
+		Close()
+	})
+	if err := Configure(opts); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	return &buf
+}
+
+func TestLevelFiltering(t *testing.T) {
+	buf := capture(t, Options{Level: "warn"})
+	log := GetLogger()
+
+	log.Debug("debug line")
+	log.Info("info line")
+	log.Warn("warn line")
+	log.Error("error line")
+
+	out := buf.String()
+	for _, dropped := range []string{"debug line", "info line"} {
+		if strings.Contains(out, dropped) {
+			t.Errorf("%q logged at level warn:\n%s", dropped, out)
+		}
+	}
+	for _, kept := range []string{"warn line", "error line"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("%q missing at level warn:\n%s", kept, out)
+		}
+	}
+}
+
+func TestJSONWithRequestID(t *testing.T) {
+	buf := capture(t, Options{Level: "debug", Format: FormatJSON})
+	ctx := WithRequestID(context.Background(), "req-42")
+
+	GetLogger().WithContext(ctx).Debug("starting %s", "default")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output is not a JSON record: %v\n%s", err, buf)
+	}
+	if record["msg"] != "starting default" {
+		t.Errorf("msg = %v, want starting default", record["msg"])
+	}
+	if record["level"] != "DEBUG" {
+		t.Errorf("level = %v, want DEBUG", record["level"])
+	}
+	if record["request_id"] != "req-42" {
+		t.Errorf("request_id = %v, want req-42", record["request_id"])
+	}
+	if source, _ := record["source"].(string); !strings.HasPrefix(source, "logger_test.go:") {
+		t.Errorf("source = %v, want the calling line", record["source"])
+	}
+}
+
+func TestLoggersFollowConfigure(t *testing.T) {
+	buf := capture(t, Options{})
+	log := GetLogger()
+	slogger := log.Slog()
+
+	if err := Configure(Options{Format: FormatJSON}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	log.Info("from logger")
+	slogger.Info("from slog", "profile", "default")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf)
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("line was not reconfigured to JSON: %s", line)
+		}
+	}
+}
+
+func TestConfigureRejectsInvalidOptions(t *testing.T) {
+	capture(t, Options{})
+	if err := Configure(Options{Level: "loud"}); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if err := Configure(Options{Format: "xml"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestFileOutputIsCreatedByConfigure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "manager.log")
+	t.Cleanup(Close)
+
+	GetLogger().Info("before configure")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("log file exists before Configure: %v", err)
+	}
+
+	if err := Configure(Options{Output: path}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	GetLogger().Info("after configure")
+	Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "after configure") || strings.Contains(string(data), "before configure") {
+		t.Errorf("unexpected log file contents:\n%s", data)
+	}
+}
+
+func TestJournaldPriorityPrefix(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	s := &streamSink{file: file, prefix: true}
+
+	for _, lvl := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError, LevelFatal} {
+		if err := s.Write(lvl, []byte("msg\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<7>msg\n<6>msg\n<4>msg\n<3>msg\n<2>msg\n"; string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat stamps rotated files; it sorts chronologically and avoids
+// characters that are awkward in file names
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// Rotation controls when a log file is rotated and how many rotated files
+// are kept. Zero values disable the respective limit.
+type Rotation struct {
+	// MaxSize rotates the file before a write would grow it past this many bytes
+	MaxSize int64
+	// Interval rotates the file once it has been written to for this long
+	Interval time.Duration
+	// MaxBackups is the number of rotated files to keep
+	MaxBackups int
+	// MaxAge removes rotated files older than this
+	MaxAge time.Duration
+}
+
+// rotatingFile appends to path and renames it to path's name stamped with
+// the rotation time, e.g. colima-manager-2024-05-01T10-00-00.000.log, once
+// it grows too large or too old
+type rotatingFile struct {
+	path     string
+	rotation Rotation
+	now      func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+func openRotatingFile(path string, rotation Rotation) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, rotation: rotation, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size, f.opened = file, info.Size(), f.now()
+	return nil
+}
+
+func (f *rotatingFile) Write(_ slog.Level, line []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	if f.due(int64(len(line))) {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	return err
+}
+
+// due reports whether writing n more bytes calls for a rotation first.
+// An empty file is never rotated, so a single oversized line still lands.
+func (f *rotatingFile) due(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.rotation.MaxSize > 0 && f.size+n > f.rotation.MaxSize {
+		return true
+	}
+	return f.rotation.Interval > 0 && f.now().Sub(f.opened) >= f.rotation.Interval
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	if err := os.Rename(f.path, f.backupName(f.now())); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+func (f *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// prune removes the rotated files beyond MaxBackups or older than MaxAge
+func (f *rotatingFile) prune() {
+	if f.rotation.MaxBackups <= 0 && f.rotation.MaxAge <= 0 {
+		return
+	}
+
+	backups := f.backups()
+	cutoff := f.now().Add(-f.rotation.MaxAge)
+	for i, backup := range backups {
+		expired := f.rotation.MaxAge > 0 && backup.rotated.Before(cutoff)
+		excess := f.rotation.MaxBackups > 0 && i >= f.rotation.MaxBackups
+		if expired || excess {
+			os.Remove(backup.path)
+		}
+	}
+}
+
+type backup struct {
+	path    string
+	rotated time.Time
+}
+
+// backups lists the rotated files of f, newest first
+func (f *rotatingFile) backups() []backup {
+	dir := filepath.Dir(f.path)
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		rotated, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), rotated: rotated})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotated.After(backups[j].rotated) })
+	return backups
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
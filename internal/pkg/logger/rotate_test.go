@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a time that tests advance by hand
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestFile(t *testing.T, rotation Rotation) (*rotatingFile, *fakeClock, string) {
+	t.Helper()
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 10, 0, 0, 0, time.Local)}
+	f := &rotatingFile{path: filepath.Join(dir, "manager.log"), rotation: rotation, now: clock.now}
+	if err := f.open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f, clock, dir
+}
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotateOnSize(t *testing.T) {
+	f, clock, dir := newTestFile(t, Rotation{MaxSize: 10})
+
+	line := []byte("123456\n")
+	if err := f.Write(0, line); err != nil {
+		t.Fatal(err)
+	}
+	clock.t = clock.t.Add(time.Second)
+	if err := f.Write(0, line); err != nil {
+		t.Fatal(err)
+	}
+
+	names := listDir(t, dir)
+	want := []string{"manager-2024-05-01T10-00-01.000.log", "manager.log"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	for _, name := range names {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		if string(data) != string(line) {
+			t.Errorf("%s = %q, want one line", name, data)
+		}
+	}
+}
+
+func TestRotateOnInterval(t *testing.T) {
+	f, clock, dir := newTestFile(t, Rotation{Interval: time.Hour})
+
+	f.Write(0, []byte("first\n"))
+	clock.t = clock.t.Add(59 * time.Minute)
+	f.Write(0, []byte("second\n"))
+	if names := listDir(t, dir); len(names) != 1 {
+		t.Fatalf("rotated before the interval: %v", names)
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	f.Write(0, []byte("third\n"))
+	if names := listDir(t, dir); len(names) != 2 {
+		t.Fatalf("files = %v, want the log and one backup", names)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	f, clock, dir := newTestFile(t, Rotation{MaxSize: 1, MaxBackups: 2, MaxAge: 90 * time.Minute})
+
+	// Each write rotates the previous one out, an hour apart
+	for i := 0; i < 5; i++ {
+		if err := f.Write(0, []byte("x\n")); err != nil {
+			t.Fatal(err)
+		}
+		clock.t = clock.t.Add(time.Hour)
+	}
+
+	// Backups were made at 11:00, 12:00, 13:00 and 14:00; the last write
+	// happened at 14:00, so only the 13:00 and 14:00 ones are within both
+	// limits
+	names := listDir(t, dir)
+	want := []string{
+		"manager-2024-05-01T13-00-00.000.log",
+		"manager-2024-05-01T14-00-00.000.log",
+		"manager.log",
+	}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+
+	// Tighten the age limit: only the newest backup survives
+	f.rotation.MaxAge = 30 * time.Minute
+	f.Write(0, []byte("x\n"))
+	names = listDir(t, dir)
+	if len(names) != 2 || names[0] != "manager-2024-05-01T15-00-00.000.log" {
+		t.Errorf("files = %v, want manager.log and the 15:00 backup", names)
+	}
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Destinations accepted in Options.Output besides a file path
+const (
+	OutputStdout   = "stdout"
+	OutputStderr   = "stderr"
+	OutputJournald = "journald"
+	OutputSyslog   = "syslog"
+)
+
+// sink receives formatted records, one line per call
+type sink interface {
+	Write(lvl slog.Level, line []byte) error
+	Close() error
+}
+
+func openSink(dest string, rotation Rotation) (sink, error) {
+	switch dest {
+	case "", OutputStdout:
+		return consoleSink{}, nil
+	case OutputStderr:
+		return &streamSink{file: os.Stderr}, nil
+	case OutputJournald:
+		return &streamSink{file: os.Stderr, prefix: true}, nil
+	case OutputSyslog:
+		return openSyslog()
+	default:
+		file, err := openRotatingFile(dest, rotation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		return file, nil
+	}
+}
+
+// consoleSink writes to stdout unless redirected with SetConsoleOutput
+type consoleSink struct{}
+
+func (consoleSink) Write(_ slog.Level, line []byte) error {
+	_, err := console.Write(line)
+	return err
+}
+
+func (consoleSink) Close() error { return nil }
+
+// streamSink writes to a standard stream. With prefix set every line starts
+// with its syslog priority (<3> for errors, <6> for info, ...) which journald
+// and syslog-aware supervisors use as the entry's level.
+type streamSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	prefix bool
+}
+
+func (s *streamSink) Write(lvl slog.Level, line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.prefix {
+		line = append([]byte(fmt.Sprintf("<%d>", priority(lvl))), line...)
+	}
+	_, err := s.file.Write(line)
+	return err
+}
+
+// Close leaves the standard stream open
+func (s *streamSink) Close() error { return nil }
+
+// priority maps a level to its syslog severity
+func priority(lvl slog.Level) int {
+	switch {
+	case lvl >= LevelFatal:
+		return 2 // crit
+	case lvl >= slog.LevelError:
+		return 3 // err
+	case lvl >= slog.LevelWarn:
+		return 4 // warning
+	case lvl >= slog.LevelInfo:
+		return 6 // info
+	default:
+		return 7 // debug
+	}
+}
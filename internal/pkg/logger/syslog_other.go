@@ -0,0 +1,9 @@
+//go:build !unix
+
+package logger
+
+import "errors"
+
+func openSyslog() (sink, error) {
+	return nil, errors.New("syslog output is not supported on this platform")
+}
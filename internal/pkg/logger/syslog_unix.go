@@ -0,0 +1,43 @@
+//go:build unix
+
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"log/syslog"
+)
+
+// syslogSink sends each record to the local syslog daemon at its level's
+// severity
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+func openSyslog() (sink, error) {
+	w, err := syslog.New(syslog.LOG_DAEMON|syslog.LOG_INFO, "colima-manager")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) Write(lvl slog.Level, line []byte) error {
+	msg := string(line)
+	switch priority(lvl) {
+	case 2:
+		return s.w.Crit(msg)
+	case 3:
+		return s.w.Err(msg)
+	case 4:
+		return s.w.Warning(msg)
+	case 6:
+		return s.w.Info(msg)
+	default:
+		return s.w.Debug(msg)
+	}
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
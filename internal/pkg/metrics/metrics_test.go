@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryTextFormat(t *testing.T) {
+	r := NewRegistry()
+
+	counter := r.NewCounterVec("ops_total", "Operations run.", "op", "outcome")
+	counter.Inc("start", "success")
+	counter.Inc("start", "success")
+	counter.Add(3, "clean", "error")
+
+	gauge := r.NewGaugeVec("profile_cpus", "Configured CPUs.", "profile")
+	gauge.Set(4, `we"ird`)
+	gauge.Set(2, "default")
+
+	hist := r.NewHistogramVec("op_seconds", "Operation duration.", []float64{1, 0.5}, "op")
+	hist.Observe(0.2, "start")
+	hist.Observe(0.7, "start")
+	hist.Observe(3, "start")
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+
+	expected := `# HELP ops_total Operations run.
+# TYPE ops_total counter
+ops_total{op="clean",outcome="error"} 3
+ops_total{op="start",outcome="success"} 2
+# HELP profile_cpus Configured CPUs.
+# TYPE profile_cpus gauge
+profile_cpus{profile="default"} 2
+profile_cpus{profile="we\"ird"} 4
+# HELP op_seconds Operation duration.
+# TYPE op_seconds histogram
+op_seconds_bucket{op="start",le="0.5"} 1
+op_seconds_bucket{op="start",le="1"} 2
+op_seconds_bucket{op="start",le="+Inf"} 3
+op_seconds_sum{op="start"} 3.9
+op_seconds_count{op="start"} 3
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestGaugeDeleteMatching(t *testing.T) {
+	r := NewRegistry()
+	gauge := r.NewGaugeVec("state", "State.", "profile", "state")
+	gauge.Set(1, "default", "running")
+	gauge.Set(1, "work", "stopped")
+
+	gauge.DeleteMatching("profile", "default")
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), `profile="default"`)
+	assert.Contains(t, buf.String(), `state{profile="work",state="stopped"} 1`)
+}
+
+func TestGaugeDeleteUnless(t *testing.T) {
+	r := NewRegistry()
+	gauge := r.NewGaugeVec("cpus", "CPUs.", "profile")
+	gauge.Set(2, "default")
+	gauge.Set(4, "work")
+
+	gauge.DeleteUnless("profile", func(profile string) bool { return profile == "work" })
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), `profile="default"`)
+	assert.Contains(t, buf.String(), `cpus{profile="work"} 4`)
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("requests_total", "Requests.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "requests_total 1\n")
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	r := NewRegistry()
+	counter := r.NewCounterVec("ops_total", "Operations.", "op")
+	assert.Panics(t, func() { counter.Inc() })
+}
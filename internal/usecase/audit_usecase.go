@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+type AuditInterface interface {
+	History(filter domain.AuditFilter) ([]domain.AuditRecord, error)
+}
+
+// AuditedUseCase records every mutating operation of another use case in an
+// audit store. Reads pass through unrecorded.
+type AuditedUseCase struct {
+	next  ColimaUseCaseInterface
+	store domain.AuditStore
+	log   *logger.Logger
+	now   func() time.Time
+}
+
+func NewAuditedUseCase(next ColimaUseCaseInterface, store domain.AuditStore) *AuditedUseCase {
+	return &AuditedUseCase{
+		next:  next,
+		store: store,
+		log:   logger.GetLogger(),
+		now:   time.Now,
+	}
+}
+
+func (a *AuditedUseCase) CheckDependencies(ctx context.Context) (*domain.DependencyStatus, error) {
+	return a.next.CheckDependencies(ctx)
+}
+
+func (a *AuditedUseCase) UpdateDependencies(ctx context.Context) error {
+	started := a.now()
+	err := a.next.UpdateDependencies(ctx)
+	a.record(ctx, domain.AuditRecord{Operation: "update_dependencies"}, started, err)
+	return err
+}
+
+func (a *AuditedUseCase) Start(ctx context.Context, config domain.ColimaConfig) error {
+	started := a.now()
+	err := a.next.Start(ctx, config)
+	requested := config
+	a.record(ctx, domain.AuditRecord{
+		Operation: "start",
+		Profile:   normalizeProfile(config.Profile),
+		Config:    &requested,
+	}, started, err)
+	return err
+}
+
+func (a *AuditedUseCase) Stop(ctx context.Context, profile string) error {
+	started := a.now()
+	err := a.next.Stop(ctx, profile)
+	a.record(ctx, domain.AuditRecord{Operation: "stop", Profile: normalizeProfile(profile)}, started, err)
+	return err
+}
+
+func (a *AuditedUseCase) Status(ctx context.Context, profile string) (*domain.ColimaStatus, error) {
+	return a.next.Status(ctx, profile)
+}
+
+func (a *AuditedUseCase) ListProfiles(ctx context.Context) ([]domain.ProfileInfo, error) {
+	return a.next.ListProfiles(ctx)
+}
+
+func (a *AuditedUseCase) GetKubeConfig(ctx context.Context, profile string) (string, error) {
+	return a.next.GetKubeConfig(ctx, profile)
+}
+
+func (a *AuditedUseCase) PlanClean(ctx context.Context, req domain.CleanRequest) (*domain.CleanPlan, error) {
+	return a.next.PlanClean(ctx, req)
+}
+
+func (a *AuditedUseCase) Clean(ctx context.Context, req domain.CleanRequest) error {
+	started := a.now()
+	err := a.next.Clean(ctx, req)
+	profile := req.Profile
+	if profile == "" {
+		profile = "all"
+	}
+	a.record(ctx, domain.AuditRecord{Operation: "clean", Profile: profile}, started, err)
+	return err
+}
+
+// History returns the recorded operations matching filter, oldest first
+func (a *AuditedUseCase) History(filter domain.AuditFilter) ([]domain.AuditRecord, error) {
+	records, err := a.store.Query(filter)
+	if err != nil {
+		return nil, a.log.LogError(err, "failed to query audit log")
+	}
+	return records, nil
+}
+
+// record completes record with the caller, timing and outcome and appends
+// it. A failing store is logged but never fails the operation.
+func (a *AuditedUseCase) record(ctx context.Context, record domain.AuditRecord, started time.Time, err error) {
+	record.Time = started
+	record.Caller = domain.CallerFrom(ctx)
+	record.JobID = domain.JobID(ctx)
+	record.Duration = a.now().Sub(started).Seconds()
+	record.Outcome = outcome(err)
+	if err != nil {
+		detail := domain.NewErrorDetail(err)
+		record.Error, record.ErrorCode = detail.Error, detail.Code
+	}
+
+	if err := a.store.Append(record); err != nil {
+		a.log.Error("Failed to write audit record for %s of %s: %v", record.Operation, record.Profile, err)
+	}
+}
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+type memoryAuditStore struct {
+	mu      sync.Mutex
+	records []domain.AuditRecord
+}
+
+func (s *memoryAuditStore) Append(record domain.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memoryAuditStore) Query(filter domain.AuditFilter) ([]domain.AuditRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var records []domain.AuditRecord
+	for _, r := range s.records {
+		if filter.Matches(r) {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+func TestAuditedUseCase(t *testing.T) {
+	domain.ResetProfileLock()
+
+	store := &memoryAuditStore{}
+	mockRepo := &mockRepository{mockStatus: &domain.ColimaStatus{Status: domain.ProfileRunning}}
+	audited := NewAuditedUseCase(NewColimaUseCase(mockRepo, nil, nil), store)
+	runner := NewJobRunner(audited)
+
+	ctx := domain.WithCaller(context.Background(), domain.Caller{Name: "ci", Address: "10.0.0.7"})
+	job, err := runner.SubmitStart(ctx, domain.ColimaConfig{Profile: "web", CPUs: 6})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	waitForJob(t, runner, job.ID)
+
+	if _, err := audited.Status(ctx, "web"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	mockRepo.mockError = errors.New("boom")
+	if err := audited.Clean(ctx, domain.CleanRequest{}); err == nil {
+		t.Fatal("Expected clean to fail")
+	}
+
+	records, _ := audited.History(domain.AuditFilter{})
+	if len(records) != 2 {
+		t.Fatalf("Expected start and clean to be audited, got %+v", records)
+	}
+
+	start := records[0]
+	if start.Operation != "start" || start.Profile != "web" || start.Outcome != "success" {
+		t.Errorf("Unexpected start record: %+v", start)
+	}
+	if start.Caller.Name != "ci" || start.Caller.Address != "10.0.0.7" || start.JobID != job.ID {
+		t.Errorf("Expected the job's caller and ID on the record, got %+v", start)
+	}
+	if start.Config == nil || start.Config.CPUs != 6 {
+		t.Errorf("Expected the requested config on the record, got %+v", start.Config)
+	}
+	if start.Duration <= 0 {
+		t.Errorf("Expected a duration, got %v", start.Duration)
+	}
+
+	clean := records[1]
+	if clean.Profile != "all" || clean.Outcome != "error" || clean.Error != "boom" {
+		t.Errorf("Unexpected clean record: %+v", clean)
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strconv"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+// cleanTokens signs clean plans so a clean only runs once its plan has been
+// seen. Tokens are derived from the plan rather than stored: one stays valid
+// while its plan is unchanged, until the process restarts.
+type cleanTokens struct {
+	secret []byte
+}
+
+func newCleanTokens() *cleanTokens {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic("failed to generate clean token secret: " + err.Error())
+	}
+	return &cleanTokens{secret: secret}
+}
+
+// sign returns the token of plan, the plan of req. Sizes are left out since
+// disk images grow while profiles run.
+func (t *cleanTokens) sign(req domain.CleanRequest, plan *domain.CleanPlan) string {
+	mac := hmac.New(sha256.New, t.secret)
+	writeField(mac, "profile", req.Profile)
+	writeField(mac, "preserve_config", strconv.FormatBool(req.PreserveConfig))
+	writeField(mac, "profiles", plan.Profiles...)
+	writeField(mac, "directories", cleanPaths(plan.Directories)...)
+	writeField(mac, "docker_contexts", plan.DockerContexts...)
+	writeField(mac, "kubeconfigs", cleanPaths(plan.KubeConfigs)...)
+	writeField(mac, "preserved", cleanPaths(plan.Preserved)...)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// writeField feeds a named list to mac, NUL separated so values cannot run
+// into each other
+func writeField(mac hash.Hash, name string, values ...string) {
+	mac.Write([]byte(name + "\x00" + strconv.Itoa(len(values)) + "\x00"))
+	for _, value := range values {
+		mac.Write([]byte(value + "\x00"))
+	}
+}
+
+func cleanPaths(paths []domain.CleanPath) []string {
+	names := make([]string, len(paths))
+	for i, p := range paths {
+		names[i] = p.Path
+	}
+	return names
+}
+
+// confirmClean checks that token confirms plan
+func confirmClean(token string, plan *domain.CleanPlan) error {
+	if token == "" {
+		return &domain.CleanNotConfirmedError{Reason: "a token from a dry run is required"}
+	}
+	if !hmac.Equal([]byte(token), []byte(plan.Token)) {
+		return &domain.CleanNotConfirmedError{Reason: "the token does not match the current plan; run a dry run again"}
+	}
+	return nil
+}
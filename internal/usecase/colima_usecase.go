@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"sort"
 
+	"github.com/gqadonis/colima-manager/internal/config"
 	"github.com/gqadonis/colima-manager/internal/domain"
 	"github.com/gqadonis/colima-manager/internal/pkg/logger"
 )
@@ -13,102 +15,128 @@ type ColimaUseCaseInterface interface {
 	Start(ctx context.Context, config domain.ColimaConfig) error
 	Stop(ctx context.Context, profile string) error
 	Status(ctx context.Context, profile string) (*domain.ColimaStatus, error)
+	ListProfiles(ctx context.Context) ([]domain.ProfileInfo, error)
 	GetKubeConfig(ctx context.Context, profile string) (string, error)
+	PlanClean(ctx context.Context, req domain.CleanRequest) (*domain.CleanPlan, error)
 	Clean(ctx context.Context, req domain.CleanRequest) error
 }
 
 type ColimaUseCase struct {
-	repo domain.ColimaRepository
-	log  *logger.Logger
+	repo     domain.ColimaRepository
+	cfg      *config.Config
+	events   domain.EventPublisher
+	tokens   *cleanTokens
+	contexts *DockerContextUseCase
+	log      *logger.Logger
 }
 
-func NewColimaUseCase(repo domain.ColimaRepository) ColimaUseCaseInterface {
+// NewColimaUseCase creates the use case; cfg and events may be nil
+func NewColimaUseCase(repo domain.ColimaRepository, cfg *config.Config, events domain.EventPublisher) ColimaUseCaseInterface {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if events == nil {
+		events = noopPublisher{}
+	}
 	return &ColimaUseCase{
-		repo: repo,
-		log:  logger.GetLogger(),
+		repo:     repo,
+		cfg:      cfg,
+		events:   events,
+		tokens:   newCleanTokens(),
+		contexts: NewDockerContextUseCase(repo),
+		log:      logger.GetLogger(),
 	}
 }
 
+// noopPublisher discards events when no bus is configured
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(domain.Event) {}
+
+// publish emits eventType for profile, or EventFailed when err is set
+func (uc *ColimaUseCase) publish(eventType domain.EventType, profile, operation string, err error) {
+	event := domain.Event{
+		Type:      eventType,
+		Profile:   profile,
+		Operation: operation,
+	}
+	if err != nil {
+		event.Type = domain.EventFailed
+		event.Error = err.Error()
+	}
+	uc.events.Publish(event)
+}
+
 func (uc *ColimaUseCase) CheckDependencies(ctx context.Context) (*domain.DependencyStatus, error) {
-	uc.log.Info("Checking dependencies in usecase")
+	log := uc.log.WithContext(ctx)
+	log.Info("Checking dependencies in usecase")
 	status, err := uc.repo.CheckDependencies(ctx)
 	if err != nil {
-		return nil, uc.log.LogError(err, "dependency check failed in usecase")
+		return nil, log.LogError(err, "dependency check failed in usecase")
 	}
-	uc.log.Info("Dependencies checked successfully - Homebrew: %v, Colima: %v, Lima: %v",
+	log.Info("Dependencies checked successfully - Homebrew: %v, Colima: %v, Lima: %v",
 		status.Homebrew, status.Colima, status.Lima)
 	return status, nil
 }
 
 func (uc *ColimaUseCase) UpdateDependencies(ctx context.Context) error {
-	uc.log.Info("Updating dependencies in usecase")
-	if err := uc.repo.UpdateDependencies(ctx); err != nil {
-		return uc.log.LogError(err, "failed to update dependencies in usecase")
+	log := uc.log.WithContext(ctx)
+	log.Info("Updating dependencies in usecase")
+	err := uc.repo.UpdateDependencies(ctx)
+	uc.publish(domain.EventDependenciesUpdated, "", "update_dependencies", err)
+	if err != nil {
+		return log.LogError(err, "failed to update dependencies in usecase")
 	}
-	uc.log.Info("Dependencies updated successfully")
+	log.Info("Dependencies updated successfully")
 	return nil
 }
 
-func (uc *ColimaUseCase) Start(ctx context.Context, config domain.ColimaConfig) error {
-	uc.log.Info("Starting Colima instance with config: %+v", config)
+func (uc *ColimaUseCase) Start(ctx context.Context, config domain.ColimaConfig) (err error) {
+	log := uc.log.WithContext(ctx)
+	log.Info("Starting Colima instance with config: %+v", config)
+
+	effective := ResolveConfig(uc.cfg, config)
+	config = effective.Config
+	log.Debug("Resolved start config of profile %s: %+v (sources: %v)", config.Profile, config, effective.Sources)
+	if err := domain.ValidateColimaConfig(config, uc.cfg.Host()); err != nil {
+		return log.LogError(err, "invalid start config")
+	}
 
 	// Try to acquire lock
 	profileLock := domain.GetProfileLock()
-	if !profileLock.Lock(config.Profile) {
+	if !profileLock.LockOperation(config.Profile, "start") {
 		return &domain.ProfileBusyError{Profile: config.Profile}
 	}
 	defer profileLock.Unlock(config.Profile)
 
-	// Apply defaults if not set
-	defaults := domain.DefaultColimaConfig()
-
-	if config.Profile == "" {
-		config.Profile = defaults.Profile
-		uc.log.Debug("Using default profile: %s", config.Profile)
-	}
-	if config.CPUs == 0 {
-		config.CPUs = defaults.CPUs
-		uc.log.Debug("Using default CPUs: %d", config.CPUs)
-	}
-	if config.Memory == 0 {
-		config.Memory = defaults.Memory
-		uc.log.Debug("Using default Memory: %d", config.Memory)
-	}
-	if config.DiskSize == 0 {
-		config.DiskSize = defaults.DiskSize
-		uc.log.Debug("Using default DiskSize: %d", config.DiskSize)
-	}
-	if config.VMType == "" {
-		config.VMType = defaults.VMType
-		uc.log.Debug("Using default VMType: %s", config.VMType)
-	}
-	if config.Runtime == "" {
-		config.Runtime = defaults.Runtime
-		uc.log.Debug("Using default Runtime: %s", config.Runtime)
-	}
+	uc.publish(domain.EventStartRequested, config.Profile, "start", nil)
+	defer func() {
+		uc.publish(domain.EventStarted, config.Profile, "start", err)
+	}()
 
 	// Check dependencies before starting
-	uc.log.Debug("Checking dependencies before start")
+	log.Debug("Checking dependencies before start")
 	status, err := uc.repo.CheckDependencies(ctx)
 	if err != nil {
-		return uc.log.LogError(err, "dependency check failed before start")
+		return log.LogError(err, "dependency check failed before start")
 	}
 
 	// If dependencies are missing or outdated, try to update them
 	if !status.Colima || !status.Lima {
-		uc.log.Info("Missing dependencies detected, attempting update")
+		log.Info("Missing dependencies detected, attempting update")
 		if err := uc.repo.UpdateDependencies(ctx); err != nil {
-			return uc.log.LogError(err, "failed to update dependencies before start")
+			return log.LogError(err, "failed to update dependencies before start")
 		}
+		uc.publish(domain.EventDependenciesUpdated, config.Profile, "update_dependencies", nil)
 
 		// Check again after update
-		uc.log.Debug("Verifying dependencies after update")
+		log.Debug("Verifying dependencies after update")
 		status, err = uc.repo.CheckDependencies(ctx)
 		if err != nil {
-			return uc.log.LogError(err, "dependency check failed after update")
+			return log.LogError(err, "dependency check failed after update")
 		}
 		if !status.Colima || !status.Lima {
-			return uc.log.LogError(&domain.DependencyError{
+			return log.LogError(&domain.DependencyError{
 				Dependency: "colima/lima",
 				Reason:     "failed to install required dependencies",
 			}, "dependencies still missing after update")
@@ -116,103 +144,165 @@ func (uc *ColimaUseCase) Start(ctx context.Context, config domain.ColimaConfig)
 	}
 
 	if err := uc.repo.Start(ctx, config); err != nil {
-		return uc.log.LogError(err, "failed to start Colima instance")
+		return log.LogError(err, "failed to start Colima instance")
 	}
 
-	uc.log.Info("Colima instance started successfully - Profile: %s", config.Profile)
+	log.Info("Colima instance started successfully - Profile: %s", config.Profile)
+
+	// The profile is up either way, so a context failure only warns
+	if uc.cfg.Docker.ManageContexts && config.Runtime == "docker" {
+		if _, err := uc.contexts.SyncDockerContext(ctx, config.Profile, uc.cfg.Docker.UseContext); err != nil {
+			log.Warn("Docker context of profile %s was not updated: %v", config.Profile, err)
+		}
+	}
 	return nil
 }
 
 func (uc *ColimaUseCase) Stop(ctx context.Context, profile string) error {
-	uc.log.Info("Stopping Colima instance - Profile: %s", profile)
-
-	// Try to acquire lock
-	profileLock := domain.GetProfileLock()
-	if !profileLock.Lock(profile) {
-		return &domain.ProfileBusyError{Profile: profile}
-	}
-	defer profileLock.Unlock(profile)
+	log := uc.log.WithContext(ctx)
+	log.Info("Stopping Colima instance - Profile: %s", profile)
 
 	if profile == "" {
 		profile = domain.DefaultColimaConfig().Profile
-		uc.log.Debug("Using default profile: %s", profile)
+		log.Debug("Using default profile: %s", profile)
 	}
-
-	// First stop the Colima instance
-	stopErr := uc.repo.Stop(ctx, profile)
-	if stopErr != nil {
-		uc.log.Error("Failed to stop Colima instance: %v", stopErr)
+	if err := domain.ValidateProfileName(profile); err != nil {
+		return err
 	}
 
-	// Always attempt to stop the daemon, regardless of whether Colima stop succeeded
-	if err := uc.repo.StopDaemon(ctx); err != nil {
-		uc.log.Error("Failed to stop daemon: %v", err)
-		// Don't return daemon error as it's not critical
+	// Try to acquire lock
+	profileLock := domain.GetProfileLock()
+	if !profileLock.LockOperation(profile, "stop") {
+		return &domain.ProfileBusyError{Profile: profile}
 	}
+	defer profileLock.Unlock(profile)
 
-	// If Colima stop failed, return that error
+	stopErr := uc.repo.Stop(ctx, profile)
+	uc.publish(domain.EventStopped, profile, "stop", stopErr)
 	if stopErr != nil {
-		return uc.log.LogError(stopErr, "failed to stop Colima instance")
+		return log.LogError(stopErr, "failed to stop Colima instance")
 	}
 
-	uc.log.Info("Colima instance and daemon stopped successfully - Profile: %s", profile)
+	log.Info("Colima instance stopped successfully - Profile: %s", profile)
 	return nil
 }
 
 func (uc *ColimaUseCase) Status(ctx context.Context, profile string) (*domain.ColimaStatus, error) {
-	uc.log.Info("Checking Colima status - Profile: %s", profile)
+	log := uc.log.WithContext(ctx)
+	log.Info("Checking Colima status - Profile: %s", profile)
 
 	if profile == "" {
 		profile = domain.DefaultColimaConfig().Profile
-		uc.log.Debug("Using default profile: %s", profile)
+		log.Debug("Using default profile: %s", profile)
+	}
+	if err := domain.ValidateProfileName(profile); err != nil {
+		return nil, err
 	}
 
 	status, err := uc.repo.Status(ctx, profile)
 	if err != nil {
-		return nil, uc.log.LogError(err, "failed to get Colima status")
+		return nil, log.LogError(err, "failed to get Colima status")
 	}
 
-	uc.log.Info("Colima status retrieved successfully - Profile: %s, Status: %+v", profile, status)
+	log.Info("Colima status retrieved successfully - Profile: %s, Status: %+v", profile, status)
 	return status, nil
 }
 
+func (uc *ColimaUseCase) ListProfiles(ctx context.Context) ([]domain.ProfileInfo, error) {
+	log := uc.log.WithContext(ctx)
+	log.Info("Listing profiles")
+
+	definitions := uc.cfg.DeclaredProfiles()
+	declared := make([]string, 0, len(definitions))
+	for name := range definitions {
+		declared = append(declared, name)
+	}
+	sort.Strings(declared)
+
+	profiles, err := uc.repo.ListProfiles(ctx, declared)
+	if err != nil {
+		return nil, log.LogError(err, "failed to list profiles")
+	}
+
+	log.Info("Profiles listed successfully - Count: %d", len(profiles))
+	return profiles, nil
+}
+
 func (uc *ColimaUseCase) GetKubeConfig(ctx context.Context, profile string) (string, error) {
-	uc.log.Info("Getting kubeconfig - Profile: %s", profile)
+	log := uc.log.WithContext(ctx)
+	log.Info("Getting kubeconfig - Profile: %s", profile)
 
 	if profile == "" {
 		profile = domain.DefaultColimaConfig().Profile
-		uc.log.Debug("Using default profile: %s", profile)
+		log.Debug("Using default profile: %s", profile)
+	}
+	if err := domain.ValidateProfileName(profile); err != nil {
+		return "", err
 	}
 
 	kubeconfig, err := uc.repo.GetKubeConfig(ctx, profile)
 	if err != nil {
-		return "", uc.log.LogError(err, "failed to get kubeconfig")
+		return "", log.LogError(err, "failed to get kubeconfig")
 	}
 
-	uc.log.Info("Kubeconfig retrieved successfully - Profile: %s", profile)
+	log.Info("Kubeconfig retrieved successfully - Profile: %s", profile)
 	return kubeconfig, nil
 }
 
+// PlanClean reports what a clean of req would remove, together with the
+// token that confirms it
+func (uc *ColimaUseCase) PlanClean(ctx context.Context, req domain.CleanRequest) (*domain.CleanPlan, error) {
+	log := uc.log.WithContext(ctx)
+	log.Info("Planning clean of Colima resources - Profile: %s", req.Profile)
+
+	if err := domain.ValidateCleanRequest(req); err != nil {
+		return nil, err
+	}
+	plan, err := uc.repo.PlanClean(ctx, req)
+	if err != nil {
+		return nil, log.LogError(err, "failed to plan clean")
+	}
+	plan.Token = uc.tokens.sign(req, plan)
+	return plan, nil
+}
+
+// Clean removes what PlanClean reports for req, provided req carries the
+// token of that plan. The plan is worked out again so the clean removes
+// exactly what was confirmed.
 func (uc *ColimaUseCase) Clean(ctx context.Context, req domain.CleanRequest) error {
-	uc.log.Info("Cleaning Colima resources - Profile: %s", req.Profile)
+	log := uc.log.WithContext(ctx)
+	log.Info("Cleaning Colima resources - Profile: %s", req.Profile)
+
+	if err := domain.ValidateCleanRequest(req); err != nil {
+		return err
+	}
 
 	// Try to acquire lock if specific profile
 	if req.Profile != "" {
 		profileLock := domain.GetProfileLock()
-		if !profileLock.Lock(req.Profile) {
+		if !profileLock.LockOperation(req.Profile, "clean") {
 			return &domain.ProfileBusyError{Profile: req.Profile}
 		}
 		defer profileLock.Unlock(req.Profile)
 	}
 
-	if err := uc.repo.Clean(ctx, req); err != nil {
-		return uc.log.LogError(err, "failed to clean Colima resources")
+	plan, err := uc.repo.PlanClean(ctx, req)
+	if err == nil {
+		plan.Token = uc.tokens.sign(req, plan)
+		err = confirmClean(req.Token, plan)
+	}
+	if err == nil {
+		err = uc.repo.Clean(ctx, plan)
+	}
+	uc.publish(domain.EventCleaned, req.Profile, "clean", err)
+	if err != nil {
+		return log.LogError(err, "failed to clean Colima resources")
 	}
 
 	if req.Profile == "" {
-		uc.log.Info("All Colima resources cleaned successfully")
+		log.Info("Colima resources cleaned successfully - Profiles: %v", plan.Profiles)
 	} else {
-		uc.log.Info("Colima resources cleaned successfully - Profile: %s", req.Profile)
+		log.Info("Colima resources cleaned successfully - Profile: %s", req.Profile)
 	}
 	return nil
 }
@@ -2,10 +2,13 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/gqadonis/colima-manager/internal/config"
 	"github.com/gqadonis/colima-manager/internal/domain"
 )
 
@@ -16,7 +19,18 @@ type mockRepository struct {
 	statusProfile     string
 	kubeConfigCalled  bool
 	kubeConfigProfile string
+	mockKubeConfig    string
 	mockStatus        *domain.ColimaStatus
+	mockProfiles      []domain.ProfileInfo
+	listDeclared      []string
+	statusFn          func(profile string) (*domain.ColimaStatus, error)
+	startFn           func(ctx context.Context) error
+	stoppedProfiles   []string
+	cleanedProfiles   []string
+	mockContexts      []domain.DockerContext
+	contextSockets    map[string]string // profile to socket of created contexts
+	usedContext       string
+	removedContexts   []string
 	mockError         error
 	mu                sync.Mutex // protect concurrent access to mock fields
 }
@@ -26,37 +40,60 @@ func (m *mockRepository) Start(ctx context.Context, config domain.ColimaConfig)
 	m.startCalled = true
 	m.startConfig = config
 	m.mu.Unlock()
+	if m.startFn != nil {
+		return m.startFn(ctx)
+	}
 	// Simulate some work
 	time.Sleep(100 * time.Millisecond)
 	return m.mockError
 }
 
 func (m *mockRepository) Stop(ctx context.Context, profile string) error {
+	m.mu.Lock()
+	m.stoppedProfiles = append(m.stoppedProfiles, profile)
+	m.mu.Unlock()
 	time.Sleep(100 * time.Millisecond)
 	return m.mockError
 }
 
-func (m *mockRepository) StopDaemon(ctx context.Context) error {
-	return m.mockError
-}
-
 func (m *mockRepository) Status(ctx context.Context, profile string) (*domain.ColimaStatus, error) {
 	m.mu.Lock()
 	m.statusCalled = true
 	m.statusProfile = profile
 	m.mu.Unlock()
+	if m.statusFn != nil {
+		return m.statusFn(profile)
+	}
 	return m.mockStatus, m.mockError
 }
 
+func (m *mockRepository) ListProfiles(ctx context.Context, declared []string) ([]domain.ProfileInfo, error) {
+	m.mu.Lock()
+	m.listDeclared = declared
+	m.mu.Unlock()
+	return m.mockProfiles, m.mockError
+}
+
 func (m *mockRepository) GetKubeConfig(ctx context.Context, profile string) (string, error) {
 	m.mu.Lock()
 	m.kubeConfigCalled = true
 	m.kubeConfigProfile = profile
 	m.mu.Unlock()
-	return "", m.mockError
+	return m.mockKubeConfig, m.mockError
 }
 
-func (m *mockRepository) Clean(ctx context.Context, req domain.CleanRequest) error {
+func (m *mockRepository) PlanClean(ctx context.Context, req domain.CleanRequest) (*domain.CleanPlan, error) {
+	plan := &domain.CleanPlan{Profiles: []string{}}
+	if req.Profile != "" {
+		plan.Profiles = append(plan.Profiles, req.Profile)
+	}
+	return plan, m.mockError
+}
+
+func (m *mockRepository) Clean(ctx context.Context, plan *domain.CleanPlan) error {
+	m.mu.Lock()
+	m.cleanedProfiles = append(m.cleanedProfiles, plan.Profiles...)
+	m.mu.Unlock()
 	time.Sleep(100 * time.Millisecond)
 	return m.mockError
 }
@@ -73,16 +110,34 @@ func (m *mockRepository) UpdateDependencies(ctx context.Context) error {
 	return m.mockError
 }
 
-func (m *mockRepository) CreateDockerContext(ctx context.Context, profile string) error {
+func (m *mockRepository) CreateDockerContext(ctx context.Context, profile, socket string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.contextSockets == nil {
+		m.contextSockets = make(map[string]string)
+	}
+	m.contextSockets[profile] = socket
+	return m.mockError
+}
+
+func (m *mockRepository) UseDockerContext(ctx context.Context, profile string) error {
+	m.mu.Lock()
+	m.usedContext = profile
+	m.mu.Unlock()
 	return m.mockError
 }
 
 func (m *mockRepository) RemoveDockerContext(ctx context.Context, profile string) error {
+	m.mu.Lock()
+	m.removedContexts = append(m.removedContexts, profile)
+	m.mu.Unlock()
 	return m.mockError
 }
 
 func (m *mockRepository) ListDockerContexts(ctx context.Context) ([]domain.DockerContext, error) {
-	return nil, m.mockError
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mockContexts, m.mockError
 }
 
 func TestStartupSequence(t *testing.T) {
@@ -102,7 +157,7 @@ func TestStartupSequence(t *testing.T) {
 	}
 
 	// Create use case with mock repository
-	useCase := NewColimaUseCase(mockRepo)
+	useCase := NewColimaUseCase(mockRepo, nil, nil)
 
 	// Test configuration
 	config := domain.ColimaConfig{
@@ -111,8 +166,8 @@ func TestStartupSequence(t *testing.T) {
 		DiskSize:       60,
 		VMType:         "vz",
 		Runtime:        "containerd",
-		NetworkAddress: true,
-		Kubernetes:     true,
+		NetworkAddress: domain.Bool(true),
+		Kubernetes:     domain.Bool(true),
 		Profile:        "default",
 	}
 
@@ -127,7 +182,7 @@ func TestStartupSequence(t *testing.T) {
 	if !mockRepo.startCalled {
 		t.Error("Expected Start to be called")
 	}
-	if mockRepo.startConfig != config {
+	if !reflect.DeepEqual(mockRepo.startConfig, config) {
 		t.Errorf("Expected config %+v, got %+v", config, mockRepo.startConfig)
 	}
 	mockRepo.mu.Unlock()
@@ -161,12 +216,12 @@ func TestStartupSequence(t *testing.T) {
 	if status.DiskSize != config.DiskSize {
 		t.Errorf("Expected DiskSize %d, got %d", config.DiskSize, status.DiskSize)
 	}
-	if status.Kubernetes != config.Kubernetes {
-		t.Errorf("Expected Kubernetes %v, got %v", config.Kubernetes, status.Kubernetes)
+	if status.Kubernetes != *config.Kubernetes {
+		t.Errorf("Expected Kubernetes %v, got %v", *config.Kubernetes, status.Kubernetes)
 	}
 
 	// Verify kubeconfig check for Kubernetes-enabled profile
-	if config.Kubernetes {
+	if domain.Enabled(config.Kubernetes) {
 		_, err = useCase.GetKubeConfig(context.Background(), config.Profile)
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
@@ -187,8 +242,8 @@ func TestProfileLocking(t *testing.T) {
 	domain.ResetProfileLock()
 
 	mockRepo := &mockRepository{}
-	useCase1 := NewColimaUseCase(mockRepo)
-	useCase2 := NewColimaUseCase(mockRepo)
+	useCase1 := NewColimaUseCase(mockRepo, nil, nil)
+	useCase2 := NewColimaUseCase(mockRepo, nil, nil)
 
 	// Test configuration
 	config := domain.ColimaConfig{
@@ -264,3 +319,153 @@ func TestProfileLocking(t *testing.T) {
 		t.Errorf("Expected no error after lock release, got %v", err)
 	}
 }
+
+func TestListProfilesPassesDeclaredProfiles(t *testing.T) {
+	mockRepo := &mockRepository{
+		mockProfiles: []domain.ProfileInfo{{Name: "default", State: domain.ProfileStateRunning}},
+	}
+	cfg := &config.Config{Profiles: map[string]config.ProfileConfig{
+		"work":    {CPUs: 2},
+		"default": {CPUs: 4},
+	}}
+	useCase := NewColimaUseCase(mockRepo, cfg, nil)
+
+	profiles, err := useCase.ListProfiles(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "default" {
+		t.Errorf("Unexpected profiles: %+v", profiles)
+	}
+
+	mockRepo.mu.Lock()
+	defer mockRepo.mu.Unlock()
+	if len(mockRepo.listDeclared) != 2 || mockRepo.listDeclared[0] != "default" || mockRepo.listDeclared[1] != "work" {
+		t.Errorf("Expected declared profiles [default work], got %v", mockRepo.listDeclared)
+	}
+}
+
+func TestStartUsesStoredProfile(t *testing.T) {
+	domain.ResetProfileLock()
+	mockRepo := &mockRepository{}
+	cfg := &config.Config{Profiles: map[string]config.ProfileConfig{
+		"work": {CPUs: 6, Memory: 12, VMType: "qemu", Kubernetes: domain.Bool(true)},
+	}}
+	useCase := NewColimaUseCase(mockRepo, cfg, nil)
+
+	if err := useCase.Start(context.Background(), domain.ColimaConfig{Profile: "work", Memory: 16}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockRepo.mu.Lock()
+	defer mockRepo.mu.Unlock()
+	got := mockRepo.startConfig
+	defaults := domain.DefaultColimaConfig()
+	if got.CPUs != 6 || got.Memory != 16 || got.VMType != "qemu" || !domain.Enabled(got.Kubernetes) {
+		t.Errorf("Expected the stored definition with the requested memory, got %+v", got)
+	}
+	if got.DiskSize != defaults.DiskSize || got.Runtime != defaults.Runtime {
+		t.Errorf("Expected defaults for fields the definition leaves empty, got %+v", got)
+	}
+}
+
+func TestStartRejectsInvalidConfig(t *testing.T) {
+	domain.ResetProfileLock()
+	mockRepo := &mockRepository{}
+	cfg := &config.Config{Profiles: map[string]config.ProfileConfig{
+		"work": {Runtime: "podman"},
+	}}
+	useCase := NewColimaUseCase(mockRepo, cfg, nil)
+
+	err := useCase.Start(context.Background(), domain.ColimaConfig{Profile: "work", CPUs: -2})
+	var invalid *domain.ValidationError
+	if !errors.As(err, &invalid) || len(invalid.Fields) != 2 {
+		t.Fatalf("Expected a ValidationError for cpus and runtime, got %v", err)
+	}
+	if mockRepo.startCalled {
+		t.Error("Expected colima not to be started")
+	}
+	if domain.GetProfileLock().IsLocked("work") {
+		t.Error("Expected the profile not to stay locked")
+	}
+}
+
+func TestCleanRequiresConfirmedPlan(t *testing.T) {
+	domain.ResetProfileLock()
+	mockRepo := &mockRepository{}
+	useCase := NewColimaUseCase(mockRepo, nil, nil)
+	ctx := context.Background()
+
+	plan, err := useCase.PlanClean(ctx, domain.CleanRequest{Profile: "work"})
+	if err != nil || plan.Token == "" {
+		t.Fatalf("Expected a plan with a token, got %+v, %v", plan, err)
+	}
+	other, err := useCase.PlanClean(ctx, domain.CleanRequest{Profile: "dev"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, token := range []string{"", other.Token} {
+		err := useCase.Clean(ctx, domain.CleanRequest{Profile: "work", Token: token})
+		var unconfirmed *domain.CleanNotConfirmedError
+		if !errors.As(err, &unconfirmed) {
+			t.Errorf("Expected CleanNotConfirmedError for token %q, got %v", token, err)
+		}
+	}
+	if len(mockRepo.cleanedProfiles) != 0 {
+		t.Fatalf("Expected nothing to be cleaned, got %v", mockRepo.cleanedProfiles)
+	}
+
+	if err := useCase.Clean(ctx, domain.CleanRequest{Profile: "work", Token: plan.Token}); err != nil {
+		t.Fatalf("Expected the confirmed clean to succeed, got %v", err)
+	}
+	if len(mockRepo.cleanedProfiles) != 1 || mockRepo.cleanedProfiles[0] != "work" {
+		t.Errorf("Expected work to be cleaned, got %v", mockRepo.cleanedProfiles)
+	}
+}
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func (p *recordingPublisher) Publish(event domain.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func TestLifecycleEvents(t *testing.T) {
+	domain.ResetProfileLock()
+
+	publisher := &recordingPublisher{}
+	useCase := NewColimaUseCase(&mockRepository{}, nil, publisher)
+
+	if err := useCase.Start(context.Background(), domain.ColimaConfig{Profile: "events"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	failing := NewColimaUseCase(&mockRepository{mockError: &domain.ProfileNotFoundError{Profile: "events"}}, nil, publisher)
+	if err := failing.Clean(context.Background(), domain.CleanRequest{Profile: "events"}); err == nil {
+		t.Fatal("Expected clean to fail")
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	expected := []domain.EventType{domain.EventStartRequested, domain.EventStarted, domain.EventFailed}
+	if len(publisher.events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), publisher.events)
+	}
+	for i, eventType := range expected {
+		if publisher.events[i].Type != eventType {
+			t.Errorf("Expected event %d to be %s, got %s", i, eventType, publisher.events[i].Type)
+		}
+		if publisher.events[i].Profile != "events" {
+			t.Errorf("Expected profile 'events', got '%s'", publisher.events[i].Profile)
+		}
+	}
+	if publisher.events[2].Operation != "clean" || publisher.events[2].Error == "" {
+		t.Errorf("Expected failed clean event with error, got %+v", publisher.events[2])
+	}
+}
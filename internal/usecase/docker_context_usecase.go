@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+type DockerContextInterface interface {
+	ListDockerContexts(ctx context.Context) ([]domain.DockerContext, error)
+	SyncDockerContext(ctx context.Context, profile string, use bool) (*domain.DockerContext, error)
+	RemoveDockerContext(ctx context.Context, profile string) error
+	PruneDockerContexts(ctx context.Context) ([]domain.DockerContext, error)
+}
+
+// DockerContextUseCase keeps the colima-<profile> Docker contexts pointing
+// at the sockets of running profiles
+type DockerContextUseCase struct {
+	repo domain.ColimaRepository
+	log  *logger.Logger
+}
+
+func NewDockerContextUseCase(repo domain.ColimaRepository) *DockerContextUseCase {
+	return &DockerContextUseCase{
+		repo: repo,
+		log:  logger.GetLogger(),
+	}
+}
+
+// ListDockerContexts returns the colima contexts, flagging orphaned ones
+func (uc *DockerContextUseCase) ListDockerContexts(ctx context.Context) ([]domain.DockerContext, error) {
+	log := uc.log.WithContext(ctx)
+	contexts, err := uc.repo.ListDockerContexts(ctx)
+	if err != nil {
+		return nil, log.LogError(err, "failed to list Docker contexts")
+	}
+	return contexts, nil
+}
+
+// SyncDockerContext creates the context of a running profile, or points it
+// at the socket colima reports, and makes it current when use is set
+func (uc *DockerContextUseCase) SyncDockerContext(ctx context.Context, profile string, use bool) (*domain.DockerContext, error) {
+	log := uc.log.WithContext(ctx)
+	log.Info("Syncing Docker context - Profile: %s, Use: %v", profile, use)
+
+	if profile == "" {
+		profile = domain.DefaultColimaConfig().Profile
+	}
+	if err := domain.ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+
+	status, err := uc.repo.Status(ctx, profile)
+	if err != nil {
+		return nil, log.LogError(err, "failed to get status for Docker context")
+	}
+	if status.Status != domain.ProfileRunning {
+		return nil, &domain.ProfileNotStartedError{Profile: profile}
+	}
+	if status.DockerSocket == "" {
+		return nil, log.LogError(&domain.DockerContextError{
+			Operation: "create",
+			Profile:   profile,
+			Reason:    fmt.Sprintf("runtime %s exposes no docker socket", status.Runtime),
+		}, "no docker socket to point the context at")
+	}
+
+	if err := uc.repo.CreateDockerContext(ctx, profile, status.DockerSocket); err != nil {
+		return nil, log.LogError(err, "failed to create Docker context")
+	}
+	if use {
+		if err := uc.repo.UseDockerContext(ctx, profile); err != nil {
+			return nil, log.LogError(err, "failed to use Docker context")
+		}
+	}
+
+	contexts, err := uc.repo.ListDockerContexts(ctx)
+	if err != nil {
+		return nil, log.LogError(err, "failed to list Docker contexts")
+	}
+	for _, c := range contexts {
+		if c.Profile == profile {
+			return &c, nil
+		}
+	}
+	return nil, log.LogError(&domain.DockerContextError{
+		Operation: "create",
+		Profile:   profile,
+		Reason:    "context is not listed after creating it",
+	}, "Docker context missing after sync")
+}
+
+// RemoveDockerContext removes the context of profile; the profile itself is
+// left alone
+func (uc *DockerContextUseCase) RemoveDockerContext(ctx context.Context, profile string) error {
+	log := uc.log.WithContext(ctx)
+	if err := domain.ValidateProfileName(profile); err != nil {
+		return err
+	}
+	if err := uc.repo.RemoveDockerContext(ctx, profile); err != nil {
+		return log.LogError(err, "failed to remove Docker context")
+	}
+	return nil
+}
+
+// PruneDockerContexts removes the orphaned contexts and returns them.
+// Contexts of profiles with an operation in progress are kept, since a
+// profile being created has no directory yet.
+func (uc *DockerContextUseCase) PruneDockerContexts(ctx context.Context) ([]domain.DockerContext, error) {
+	log := uc.log.WithContext(ctx)
+	contexts, err := uc.repo.ListDockerContexts(ctx)
+	if err != nil {
+		return nil, log.LogError(err, "failed to list Docker contexts")
+	}
+
+	removed := []domain.DockerContext{}
+	for _, c := range contexts {
+		if !c.Orphaned || domain.GetProfileLock().IsLocked(c.Profile) {
+			continue
+		}
+		if err := uc.repo.RemoveDockerContext(ctx, c.Profile); err != nil {
+			return removed, log.LogError(err, "failed to prune Docker context %s", c.Name)
+		}
+		removed = append(removed, c)
+	}
+
+	log.Info("Pruned %d orphaned Docker contexts", len(removed))
+	return removed, nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+func TestSyncDockerContext(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &mockRepository{
+		mockStatus: &domain.ColimaStatus{Status: domain.ProfileRunning, Runtime: "docker",
+			DockerSocket: "/Users/dev/.colima/work/docker.sock"},
+		mockContexts: []domain.DockerContext{{Name: "colima-work", Profile: "work", Current: true}},
+	}
+	contexts := NewDockerContextUseCase(mockRepo)
+
+	synced, err := contexts.SyncDockerContext(ctx, "work", true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if synced.Name != "colima-work" || mockRepo.usedContext != "work" {
+		t.Errorf("Expected colima-work to be created and used, got %+v", synced)
+	}
+	if socket := mockRepo.contextSockets["work"]; socket != "/Users/dev/.colima/work/docker.sock" {
+		t.Errorf("Expected the context to point at the reported socket, got %q", socket)
+	}
+
+	mockRepo.mockStatus = &domain.ColimaStatus{Status: domain.ProfileRunning, Runtime: "containerd"}
+	_, err = contexts.SyncDockerContext(ctx, "work", false)
+	var contextErr *domain.DockerContextError
+	if !errors.As(err, &contextErr) {
+		t.Errorf("Expected DockerContextError without a docker socket, got %v", err)
+	}
+
+	mockRepo.mockStatus = &domain.ColimaStatus{Status: domain.ProfileStopped}
+	_, err = contexts.SyncDockerContext(ctx, "work", false)
+	var notStarted *domain.ProfileNotStartedError
+	if !errors.As(err, &notStarted) {
+		t.Errorf("Expected ProfileNotStartedError, got %v", err)
+	}
+}
+
+func TestPruneDockerContexts(t *testing.T) {
+	domain.ResetProfileLock()
+	domain.GetProfileLock().Lock("creating")
+
+	mockRepo := &mockRepository{mockContexts: []domain.DockerContext{
+		{Name: "colima", Profile: "default"},
+		{Name: "colima-gone", Profile: "gone", Orphaned: true},
+		{Name: "colima-creating", Profile: "creating", Orphaned: true},
+	}}
+	removed, err := NewDockerContextUseCase(mockRepo).PruneDockerContexts(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Name != "colima-gone" {
+		t.Errorf("Expected only colima-gone to be pruned, got %+v", removed)
+	}
+	if len(mockRepo.removedContexts) != 1 || mockRepo.removedContexts[0] != "gone" {
+		t.Errorf("Unexpected removals: %v", mockRepo.removedContexts)
+	}
+}
+
+func TestStartSyncsDockerContext(t *testing.T) {
+	domain.ResetProfileLock()
+	mockRepo := &mockRepository{
+		startFn: func(ctx context.Context) error { return nil },
+		mockStatus: &domain.ColimaStatus{Status: domain.ProfileRunning, Runtime: "docker",
+			DockerSocket: "/Users/dev/.colima/default/docker.sock"},
+		mockContexts: []domain.DockerContext{{Name: "colima", Profile: "default"}},
+	}
+	cfg := &config.Config{Docker: config.DockerConfig{ManageContexts: true}}
+	useCase := NewColimaUseCase(mockRepo, cfg, nil)
+
+	if err := useCase.Start(context.Background(), domain.ColimaConfig{Runtime: "docker"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := mockRepo.contextSockets["default"]; !ok {
+		t.Error("Expected the context of the started profile to be created")
+	}
+	if mockRepo.usedContext != "" {
+		t.Errorf("Expected the context not to be used, got %q", mockRepo.usedContext)
+	}
+
+	// A containerd profile has no docker socket to point a context at
+	mockRepo.contextSockets = nil
+	if err := useCase.Start(context.Background(), domain.ColimaConfig{Profile: "k8s", Runtime: "containerd"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mockRepo.contextSockets) != 0 {
+		t.Errorf("Expected no context for a containerd profile, got %v", mockRepo.contextSockets)
+	}
+}
@@ -0,0 +1,342 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+// maxFinishedJobs bounds how many finished jobs are kept for inspection
+const maxFinishedJobs = 100
+
+// cleanAllKey is the profile key used for jobs that clean every profile
+const cleanAllKey = "*"
+
+type JobRunnerInterface interface {
+	SubmitStart(ctx context.Context, config domain.ColimaConfig) (*domain.Job, error)
+	SubmitStop(ctx context.Context, profile string) (*domain.Job, error)
+	SubmitClean(ctx context.Context, req domain.CleanRequest) (*domain.Job, error)
+	Get(id string) (*domain.Job, error)
+	List() []*domain.Job
+	Wait(ctx context.Context, id string) (*domain.Job, error)
+	Drain(ctx context.Context) error
+	Cancel()
+}
+
+// JobRunner executes long-running use case operations in the background
+// and keeps track of their progress
+type JobRunner struct {
+	useCase ColimaUseCaseInterface
+	log     *logger.Logger
+	// ctx is the parent of every job's context; cancel interrupts them all
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	jobs     map[string]*jobEntry
+	draining bool
+}
+
+type jobEntry struct {
+	job    domain.Job
+	key    string
+	caller domain.Caller
+	// requestID ties the job's log lines to the request that queued it
+	requestID string
+	output    *syncBuffer
+	done      chan struct{}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes and reads
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func NewJobRunner(useCase ColimaUseCaseInterface) *JobRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobRunner{
+		useCase: useCase,
+		log:     logger.GetLogger(),
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(map[string]*jobEntry),
+	}
+}
+
+// SubmitStart queues a start. The caller and request ID in ctx are passed on
+// to the job; ctx itself only scopes the submission. The request is validated
+// up front; host capacity is checked once the job has resolved its config.
+func (r *JobRunner) SubmitStart(ctx context.Context, config domain.ColimaConfig) (*domain.Job, error) {
+	profile := normalizeProfile(config.Profile)
+	config.Profile = profile
+	if err := domain.ValidateColimaConfig(config, domain.HostCapacity{}); err != nil {
+		return nil, err
+	}
+	return r.submit(ctx, "start", profile, profile, func(ctx context.Context) error {
+		return r.useCase.Start(ctx, config)
+	})
+}
+
+func (r *JobRunner) SubmitStop(ctx context.Context, profile string) (*domain.Job, error) {
+	profile = normalizeProfile(profile)
+	if err := domain.ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+	return r.submit(ctx, "stop", profile, profile, func(ctx context.Context) error {
+		return r.useCase.Stop(ctx, profile)
+	})
+}
+
+func (r *JobRunner) SubmitClean(ctx context.Context, req domain.CleanRequest) (*domain.Job, error) {
+	if err := domain.ValidateCleanRequest(req); err != nil {
+		return nil, err
+	}
+	// Reject unconfirmed cleans up front; the job checks the token again
+	// right before removing anything
+	plan, err := r.useCase.PlanClean(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := confirmClean(req.Token, plan); err != nil {
+		return nil, err
+	}
+
+	key := req.Profile
+	if key == "" {
+		key = cleanAllKey
+	}
+	return r.submit(ctx, "clean", req.Profile, key, func(ctx context.Context) error {
+		return r.useCase.Clean(ctx, req)
+	})
+}
+
+// Get returns a snapshot of the job with the given ID
+func (r *JobRunner) Get(id string) (*domain.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.jobs[id]
+	if !ok {
+		return nil, &domain.JobNotFoundError{ID: id}
+	}
+	return entry.snapshot(), nil
+}
+
+// List returns snapshots of all known jobs, newest first
+func (r *JobRunner) List() []*domain.Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := make([]*domain.Job, 0, len(r.jobs))
+	for _, entry := range r.jobs {
+		jobs = append(jobs, entry.snapshot())
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Wait blocks until the job finishes or ctx is done
+func (r *JobRunner) Wait(ctx context.Context, id string) (*domain.Job, error) {
+	r.mu.Lock()
+	entry, ok := r.jobs[id]
+	r.mu.Unlock()
+	if !ok {
+		return nil, &domain.JobNotFoundError{ID: id}
+	}
+
+	select {
+	case <-entry.done:
+		return r.Get(id)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Drain stops accepting jobs and waits until every pending job has finished
+// or ctx is done
+func (r *JobRunner) Drain(ctx context.Context) error {
+	r.mu.Lock()
+	r.draining = true
+	var pending []*jobEntry
+	for _, entry := range r.jobs {
+		if !entry.job.State.Finished() {
+			pending = append(pending, entry)
+		}
+	}
+	r.mu.Unlock()
+
+	r.log.Info("Draining %d pending jobs", len(pending))
+	for _, entry := range pending {
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Cancel cancels the context of every pending job. Jobs that fail as a
+// result finish as interrupted.
+func (r *JobRunner) Cancel() {
+	r.log.Info("Canceling pending jobs")
+	r.cancel()
+}
+
+func (r *JobRunner) submit(ctx context.Context, operation, profile, key string, fn func(ctx context.Context) error) (*domain.Job, error) {
+	log := r.log.WithContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.draining {
+		return nil, log.LogError(&domain.ShuttingDownError{}, "rejecting %s job", operation)
+	}
+
+	if r.isBusy(key) {
+		busyProfile := profile
+		if busyProfile == "" {
+			busyProfile = key
+		}
+		return nil, log.LogError(&domain.ProfileBusyError{Profile: busyProfile},
+			"rejecting %s job", operation)
+	}
+
+	entry := &jobEntry{
+		job: domain.Job{
+			ID:        newJobID(),
+			Operation: operation,
+			Profile:   profile,
+			State:     domain.JobQueued,
+			CreatedAt: time.Now(),
+		},
+		key:       key,
+		caller:    domain.CallerFrom(ctx),
+		requestID: logger.RequestID(ctx),
+		output:    &syncBuffer{},
+		done:      make(chan struct{}),
+	}
+	r.jobs[entry.job.ID] = entry
+	r.prune()
+
+	log.Info("Queued %s job %s - Profile: %s", operation, entry.job.ID, profile)
+	go r.run(entry, fn)
+
+	return entry.snapshot(), nil
+}
+
+func (r *JobRunner) run(entry *jobEntry, fn func(ctx context.Context) error) {
+	defer close(entry.done)
+
+	r.mu.Lock()
+	started := time.Now()
+	entry.job.State = domain.JobRunning
+	entry.job.StartedAt = &started
+	r.mu.Unlock()
+
+	ctx := domain.WithCommandOutput(r.ctx, entry.output)
+	ctx = domain.WithCaller(domain.WithJobID(ctx, entry.job.ID), entry.caller)
+	if entry.requestID != "" {
+		ctx = logger.WithRequestID(ctx, entry.requestID)
+	}
+	log := r.log.WithContext(ctx)
+	err := fn(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	finished := time.Now()
+	entry.job.FinishedAt = &finished
+	if err != nil {
+		entry.job.State = domain.JobFailed
+		if r.ctx.Err() != nil {
+			entry.job.State = domain.JobInterrupted
+		}
+		entry.job.Error = err.Error()
+		entry.job.ErrorDetail = domain.NewErrorDetail(err)
+		log.Error("Job %s (%s) %s: %v", entry.job.ID, entry.job.Operation, entry.job.State, err)
+		return
+	}
+	entry.job.State = domain.JobSucceeded
+	log.Info("Job %s (%s) succeeded", entry.job.ID, entry.job.Operation)
+}
+
+// isBusy reports whether a job for key is pending or the profile lock is held.
+// Callers must hold r.mu.
+func (r *JobRunner) isBusy(key string) bool {
+	for _, entry := range r.jobs {
+		if entry.job.State.Finished() {
+			continue
+		}
+		if entry.key == key || entry.key == cleanAllKey || key == cleanAllKey {
+			return true
+		}
+	}
+	if key == cleanAllKey {
+		return false
+	}
+	return domain.GetProfileLock().IsLocked(key)
+}
+
+// prune drops the oldest finished jobs beyond maxFinishedJobs.
+// Callers must hold r.mu.
+func (r *JobRunner) prune() {
+	var finished []*jobEntry
+	for _, entry := range r.jobs {
+		if entry.job.State.Finished() {
+			finished = append(finished, entry)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].job.CreatedAt.Before(finished[j].job.CreatedAt)
+	})
+	for _, entry := range finished[:len(finished)-maxFinishedJobs] {
+		delete(r.jobs, entry.job.ID)
+	}
+}
+
+// snapshot returns a copy of the job with its current output.
+// Callers must hold the runner's mutex.
+func (e *jobEntry) snapshot() *domain.Job {
+	job := e.job
+	job.Output = e.output.String()
+	return &job
+}
+
+func normalizeProfile(profile string) string {
+	if profile == "" {
+		return domain.DefaultColimaConfig().Profile
+	}
+	return profile
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+func waitForJob(t *testing.T, runner *JobRunner, id string) *domain.Job {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job, err := runner.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to wait for job %s: %v", id, err)
+	}
+	return job
+}
+
+func TestJobRunnerStart(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{}
+	runner := NewJobRunner(NewColimaUseCase(mockRepo, nil, nil))
+
+	job, err := runner.SubmitStart(context.Background(), domain.ColimaConfig{Profile: "job-profile"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if job.State != domain.JobQueued {
+		t.Errorf("Expected state %s, got %s", domain.JobQueued, job.State)
+	}
+	if job.Operation != "start" || job.Profile != "job-profile" {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+
+	job = waitForJob(t, runner, job.ID)
+	if job.State != domain.JobSucceeded {
+		t.Errorf("Expected state %s, got %s (error: %s)", domain.JobSucceeded, job.State, job.Error)
+	}
+	if job.StartedAt == nil || job.FinishedAt == nil {
+		t.Error("Expected start and finish timestamps to be set")
+	}
+
+	mockRepo.mu.Lock()
+	if !mockRepo.startCalled {
+		t.Error("Expected Start to be called")
+	}
+	mockRepo.mu.Unlock()
+}
+
+func TestJobRunnerFailure(t *testing.T) {
+	domain.ResetProfileLock()
+
+	runner := NewJobRunner(NewColimaUseCase(&mockRepository{mockError: errors.New("boom")}, nil, nil))
+
+	job, err := runner.SubmitStop(context.Background(), "job-profile")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	job = waitForJob(t, runner, job.ID)
+	if job.State != domain.JobFailed {
+		t.Errorf("Expected state %s, got %s", domain.JobFailed, job.State)
+	}
+	if job.Error != "boom" {
+		t.Errorf("Expected error 'boom', got '%s'", job.Error)
+	}
+}
+
+func TestJobRunnerBusy(t *testing.T) {
+	domain.ResetProfileLock()
+
+	runner := NewJobRunner(NewColimaUseCase(&mockRepository{}, nil, nil))
+
+	first, err := runner.SubmitStart(context.Background(), domain.ColimaConfig{Profile: "job-profile"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A second job for the same profile is rejected while the first is pending
+	if _, err := runner.SubmitStop(context.Background(), "job-profile"); err == nil {
+		t.Error("Expected ProfileBusyError, got nil")
+	} else if _, ok := err.(*domain.ProfileBusyError); !ok {
+		t.Errorf("Expected ProfileBusyError, got %T", err)
+	}
+
+	// Cleaning all profiles conflicts with any pending job
+	plan, err := runner.useCase.PlanClean(context.Background(), domain.CleanRequest{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := runner.SubmitClean(context.Background(), domain.CleanRequest{Token: plan.Token}); err == nil {
+		t.Error("Expected ProfileBusyError for clean all, got nil")
+	} else if _, ok := err.(*domain.ProfileBusyError); !ok {
+		t.Errorf("Expected ProfileBusyError for clean all, got %T", err)
+	}
+
+	waitForJob(t, runner, first.ID)
+
+	// A profile locked outside the runner is also rejected
+	domain.GetProfileLock().Lock("locked-profile")
+	defer domain.ResetProfileLock()
+	if _, err := runner.SubmitStart(context.Background(), domain.ColimaConfig{Profile: "locked-profile"}); err == nil {
+		t.Error("Expected ProfileBusyError for locked profile, got nil")
+	}
+}
+
+func TestJobRunnerNotFound(t *testing.T) {
+	runner := NewJobRunner(NewColimaUseCase(&mockRepository{}, nil, nil))
+
+	if _, err := runner.Get("missing"); err == nil {
+		t.Error("Expected JobNotFoundError, got nil")
+	} else if _, ok := err.(*domain.JobNotFoundError); !ok {
+		t.Errorf("Expected JobNotFoundError, got %T", err)
+	}
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+type KubeConfigInterface interface {
+	MergeKubeConfig(ctx context.Context, profile string) (*domain.KubeConfigMerge, error)
+	PruneKubeConfig(ctx context.Context, profile string) (*domain.KubeConfigMerge, error)
+}
+
+// KubeConfigUseCase merges the kubeconfig of a profile into a shared
+// kubeconfig file under the colima-<profile> name
+type KubeConfigUseCase struct {
+	repo  domain.ColimaRepository
+	store domain.KubeConfigStore
+	log   *logger.Logger
+}
+
+func NewKubeConfigUseCase(repo domain.ColimaRepository, store domain.KubeConfigStore) *KubeConfigUseCase {
+	return &KubeConfigUseCase{
+		repo:  repo,
+		store: store,
+		log:   logger.GetLogger(),
+	}
+}
+
+// MergeKubeConfig adds the cluster, user and context of profile to the
+// shared file, replacing what an earlier merge left there
+func (uc *KubeConfigUseCase) MergeKubeConfig(ctx context.Context, profile string) (*domain.KubeConfigMerge, error) {
+	log := uc.log.WithContext(ctx)
+	log.Info("Merging kubeconfig - Profile: %s", profile)
+
+	profile, err := kubeConfigProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfig, err := uc.repo.GetKubeConfig(ctx, profile)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = &domain.ProfileMalfunctionError{Profile: profile, Reason: "no kubeconfig; is Kubernetes enabled?"}
+	}
+	if err != nil {
+		return nil, log.LogError(err, "failed to get kubeconfig")
+	}
+	merge, err := uc.store.Merge(domain.KubeContextName(profile), []byte(kubeconfig))
+	if err != nil {
+		if _, ok := err.(*domain.KubeConfigError); !ok {
+			// The store only rejects the profile's own kubeconfig with
+			// anything but a KubeConfigError
+			err = &domain.ProfileMalfunctionError{Profile: profile, Reason: err.Error()}
+		}
+		return nil, log.LogError(err, "failed to merge kubeconfig")
+	}
+	merge.Profile = profile
+
+	log.Info("Kubeconfig merged - Profile: %s, Path: %s, Changed: %v", profile, merge.Path, merge.Changed)
+	return merge, nil
+}
+
+// PruneKubeConfig removes what MergeKubeConfig added for profile. The
+// profile need not exist any more.
+func (uc *KubeConfigUseCase) PruneKubeConfig(ctx context.Context, profile string) (*domain.KubeConfigMerge, error) {
+	log := uc.log.WithContext(ctx)
+	log.Info("Pruning kubeconfig - Profile: %s", profile)
+
+	profile, err := kubeConfigProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	merge, err := uc.store.Prune(domain.KubeContextName(profile))
+	if err != nil {
+		return nil, log.LogError(err, "failed to prune kubeconfig")
+	}
+	merge.Profile = profile
+
+	log.Info("Kubeconfig pruned - Profile: %s, Path: %s, Changed: %v", profile, merge.Path, merge.Changed)
+	return merge, nil
+}
+
+// kubeConfigProfile defaults and validates the profile of a merge or prune
+func kubeConfigProfile(profile string) (string, error) {
+	if profile == "" {
+		profile = domain.DefaultColimaConfig().Profile
+	}
+	if err := domain.ValidateProfileName(profile); err != nil {
+		return "", err
+	}
+	return profile, nil
+}
+
+// KubeConfigPruningUseCase prunes the merged kubeconfig entries of every
+// profile another use case cleans. Everything else passes through.
+type KubeConfigPruningUseCase struct {
+	next       ColimaUseCaseInterface
+	kubeconfig KubeConfigInterface
+	log        *logger.Logger
+}
+
+func NewKubeConfigPruningUseCase(next ColimaUseCaseInterface, kubeconfig KubeConfigInterface) *KubeConfigPruningUseCase {
+	return &KubeConfigPruningUseCase{
+		next:       next,
+		kubeconfig: kubeconfig,
+		log:        logger.GetLogger(),
+	}
+}
+
+func (p *KubeConfigPruningUseCase) CheckDependencies(ctx context.Context) (*domain.DependencyStatus, error) {
+	return p.next.CheckDependencies(ctx)
+}
+
+func (p *KubeConfigPruningUseCase) UpdateDependencies(ctx context.Context) error {
+	return p.next.UpdateDependencies(ctx)
+}
+
+func (p *KubeConfigPruningUseCase) Start(ctx context.Context, config domain.ColimaConfig) error {
+	return p.next.Start(ctx, config)
+}
+
+func (p *KubeConfigPruningUseCase) Stop(ctx context.Context, profile string) error {
+	return p.next.Stop(ctx, profile)
+}
+
+func (p *KubeConfigPruningUseCase) Status(ctx context.Context, profile string) (*domain.ColimaStatus, error) {
+	return p.next.Status(ctx, profile)
+}
+
+func (p *KubeConfigPruningUseCase) ListProfiles(ctx context.Context) ([]domain.ProfileInfo, error) {
+	return p.next.ListProfiles(ctx)
+}
+
+func (p *KubeConfigPruningUseCase) GetKubeConfig(ctx context.Context, profile string) (string, error) {
+	return p.next.GetKubeConfig(ctx, profile)
+}
+
+func (p *KubeConfigPruningUseCase) PlanClean(ctx context.Context, req domain.CleanRequest) (*domain.CleanPlan, error) {
+	return p.next.PlanClean(ctx, req)
+}
+
+// Clean cleans req and then prunes the kubeconfig entries of the profiles it
+// removed. A failed prune is logged; the profiles are gone either way.
+func (p *KubeConfigPruningUseCase) Clean(ctx context.Context, req domain.CleanRequest) error {
+	log := p.log.WithContext(ctx)
+
+	// Plan first: once the profiles are gone there is nothing left to list
+	var profiles []string
+	if plan, err := p.next.PlanClean(ctx, req); err != nil {
+		log.Warn("Failed to plan clean, merged kubeconfig entries are left in place: %v", err)
+	} else {
+		profiles = plan.Profiles
+	}
+
+	if err := p.next.Clean(ctx, req); err != nil {
+		return err
+	}
+
+	for _, profile := range profiles {
+		if _, err := p.kubeconfig.PruneKubeConfig(ctx, profile); err != nil {
+			log.Warn("Failed to prune kubeconfig of cleaned profile %s: %v", profile, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+// mockKubeConfigStore records merges and prunes by entry name
+type mockKubeConfigStore struct {
+	merged map[string]string
+	pruned []string
+	err    error
+}
+
+func (s *mockKubeConfigStore) Merge(name string, kubeconfig []byte) (*domain.KubeConfigMerge, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.merged == nil {
+		s.merged = map[string]string{}
+	}
+	s.merged[name] = string(kubeconfig)
+	return &domain.KubeConfigMerge{Path: "/home/dev/.kube/config", Context: name, Changed: true}, nil
+}
+
+func (s *mockKubeConfigStore) Prune(name string) (*domain.KubeConfigMerge, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.pruned = append(s.pruned, name)
+	return &domain.KubeConfigMerge{Path: "/home/dev/.kube/config", Context: name, Changed: true}, nil
+}
+
+func TestMergeKubeConfig(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &mockRepository{mockKubeConfig: "apiVersion: v1\n"}
+	store := &mockKubeConfigStore{}
+	kubeconfig := NewKubeConfigUseCase(mockRepo, store)
+
+	merge, err := kubeconfig.MergeKubeConfig(ctx, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if merge.Profile != "default" || merge.Context != "colima-default" {
+		t.Errorf("Expected the default profile merged as colima-default, got %+v", merge)
+	}
+	if store.merged["colima-default"] != "apiVersion: v1\n" {
+		t.Errorf("Expected the profile's kubeconfig to be merged, got %v", store.merged)
+	}
+
+	var validation *domain.ValidationError
+	if _, err := kubeconfig.MergeKubeConfig(ctx, "../etc"); !errors.As(err, &validation) {
+		t.Errorf("Expected ValidationError, got %v", err)
+	}
+
+	// A kubeconfig the store cannot use is a fault of the profile
+	store.err = errors.New("invalid kubeconfig: context \"colima\" not found")
+	var malfunction *domain.ProfileMalfunctionError
+	if _, err := kubeconfig.MergeKubeConfig(ctx, "work"); !errors.As(err, &malfunction) {
+		t.Errorf("Expected ProfileMalfunctionError, got %v", err)
+	}
+
+	store.err = &domain.KubeConfigError{Path: "/home/dev/.kube/config", Reason: "locked"}
+	var kubeErr *domain.KubeConfigError
+	if _, err := kubeconfig.MergeKubeConfig(ctx, "work"); !errors.As(err, &kubeErr) {
+		t.Errorf("Expected KubeConfigError, got %v", err)
+	}
+}
+
+func TestCleanPrunesKubeConfig(t *testing.T) {
+	domain.ResetProfileLock()
+	ctx := context.Background()
+	mockRepo := &mockRepository{}
+	store := &mockKubeConfigStore{}
+	useCase := NewKubeConfigPruningUseCase(NewColimaUseCase(mockRepo, nil, nil), NewKubeConfigUseCase(mockRepo, store))
+
+	req := domain.CleanRequest{Profile: "work"}
+	if err := useCase.Clean(ctx, req); err == nil {
+		t.Fatal("Expected an unconfirmed clean to fail")
+	}
+	if len(store.pruned) != 0 {
+		t.Fatalf("Expected nothing to be pruned after a failed clean, got %v", store.pruned)
+	}
+
+	plan, err := useCase.PlanClean(ctx, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	req.Token = plan.Token
+	if err := useCase.Clean(ctx, req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(store.pruned) != 1 || store.pruned[0] != "colima-work" {
+		t.Errorf("Expected colima-work to be pruned, got %v", store.pruned)
+	}
+}
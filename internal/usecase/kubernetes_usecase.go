@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+type KubernetesInterface interface {
+	KubernetesHealth(ctx context.Context, profile string) (*domain.KubernetesHealth, error)
+}
+
+// KubernetesUseCase reports whether the Kubernetes cluster of a profile is
+// ready to use, beyond colima having written its kubeconfig
+type KubernetesUseCase struct {
+	repo    domain.ColimaRepository
+	checker domain.KubernetesHealthChecker
+	log     *logger.Logger
+	now     func() time.Time
+}
+
+func NewKubernetesUseCase(repo domain.ColimaRepository, checker domain.KubernetesHealthChecker) *KubernetesUseCase {
+	return &KubernetesUseCase{
+		repo:    repo,
+		checker: checker,
+		log:     logger.GetLogger(),
+		now:     time.Now,
+	}
+}
+
+// KubernetesHealth checks the API server and nodes of a running profile. A
+// profile without Kubernetes, or whose kubeconfig colima has not written
+// yet, is reported as not ready.
+func (uc *KubernetesUseCase) KubernetesHealth(ctx context.Context, profile string) (*domain.KubernetesHealth, error) {
+	log := uc.log.WithContext(ctx)
+	log.Debug("Checking Kubernetes health - Profile: %s", profile)
+
+	if profile == "" {
+		profile = domain.DefaultColimaConfig().Profile
+	}
+	if err := domain.ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+
+	status, err := uc.repo.Status(ctx, profile)
+	if err != nil {
+		return nil, log.LogError(err, "failed to get status for Kubernetes health")
+	}
+	if status.Status != domain.ProfileRunning {
+		return nil, &domain.ProfileNotStartedError{Profile: profile}
+	}
+	if !status.Kubernetes {
+		return uc.notReady(profile, "Kubernetes is not enabled"), nil
+	}
+
+	kubeconfig, err := uc.repo.GetKubeConfig(ctx, profile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return uc.notReady(profile, "kubeconfig has not been written yet"), nil
+	}
+	if err != nil {
+		return nil, log.LogError(err, "failed to get kubeconfig")
+	}
+
+	health, err := uc.checker.Check(ctx, []byte(kubeconfig))
+	if err != nil {
+		return nil, log.LogError(&domain.ProfileMalfunctionError{Profile: profile, Reason: err.Error()},
+			"failed to check Kubernetes health")
+	}
+	health.Profile = profile
+
+	log.Debug("Kubernetes health checked - Profile: %s, Ready: %v, Reason: %s", profile, health.Ready, health.Reason)
+	return health, nil
+}
+
+func (uc *KubernetesUseCase) notReady(profile, reason string) *domain.KubernetesHealth {
+	return &domain.KubernetesHealth{
+		Profile:   profile,
+		Reason:    reason,
+		Nodes:     []domain.KubernetesNode{},
+		CheckedAt: uc.now(),
+	}
+}
+
+// WaitKubernetesReady checks the health of profile every interval until it
+// is ready, and gives up with an OperationTimeoutError carrying the last
+// result when ctx ends
+func WaitKubernetesReady(ctx context.Context, kubernetes KubernetesInterface, profile string,
+	interval time.Duration) (*domain.KubernetesHealth, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		health, err := kubernetes.KubernetesHealth(ctx, profile)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, &domain.OperationTimeoutError{Operation: "wait for kubernetes"}
+			}
+			return nil, err
+		}
+		if health.Ready {
+			return health, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return health, &domain.OperationTimeoutError{Operation: "wait for kubernetes"}
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+// mockHealthChecker reports the cluster ready once it has been checked
+// readyAfter times
+type mockHealthChecker struct {
+	checks     int
+	readyAfter int
+	err        error
+}
+
+func (c *mockHealthChecker) Check(ctx context.Context, kubeconfig []byte) (*domain.KubernetesHealth, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.checks++
+	health := &domain.KubernetesHealth{Server: "https://127.0.0.1:6443", Nodes: []domain.KubernetesNode{}}
+	if c.checks >= c.readyAfter {
+		health.Ready = true
+	} else {
+		health.Reason = "API server is not ready"
+	}
+	return health, nil
+}
+
+func TestKubernetesHealth(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &mockRepository{
+		mockStatus:     &domain.ColimaStatus{Status: domain.ProfileRunning, Kubernetes: true},
+		mockKubeConfig: "apiVersion: v1\n",
+	}
+	checker := &mockHealthChecker{}
+	kubernetes := NewKubernetesUseCase(mockRepo, checker)
+
+	health, err := kubernetes.KubernetesHealth(ctx, "work")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !health.Ready || health.Profile != "work" {
+		t.Errorf("Expected work to be ready, got %+v", health)
+	}
+
+	mockRepo.mockStatus = &domain.ColimaStatus{Status: domain.ProfileRunning}
+	health, err = kubernetes.KubernetesHealth(ctx, "work")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if health.Ready || health.Reason != "Kubernetes is not enabled" || checker.checks != 1 {
+		t.Errorf("Expected a profile without Kubernetes to be reported unready unchecked, got %+v", health)
+	}
+
+	mockRepo.mockStatus = &domain.ColimaStatus{Status: domain.ProfileStopped}
+	var notStarted *domain.ProfileNotStartedError
+	if _, err := kubernetes.KubernetesHealth(ctx, "work"); !errors.As(err, &notStarted) {
+		t.Errorf("Expected ProfileNotStartedError, got %v", err)
+	}
+
+	mockRepo.mockStatus = &domain.ColimaStatus{Status: domain.ProfileRunning, Kubernetes: true}
+	checker.err = errors.New("invalid kubeconfig: context \"colima\" not found")
+	var malfunction *domain.ProfileMalfunctionError
+	if _, err := kubernetes.KubernetesHealth(ctx, "work"); !errors.As(err, &malfunction) {
+		t.Errorf("Expected ProfileMalfunctionError for an unusable kubeconfig, got %v", err)
+	}
+}
+
+func TestWaitKubernetesReady(t *testing.T) {
+	mockRepo := &mockRepository{
+		mockStatus: &domain.ColimaStatus{Status: domain.ProfileRunning, Kubernetes: true},
+	}
+	checker := &mockHealthChecker{readyAfter: 3}
+	kubernetes := NewKubernetesUseCase(mockRepo, checker)
+
+	health, err := WaitKubernetesReady(context.Background(), kubernetes, "work", time.Millisecond)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !health.Ready || checker.checks != 3 {
+		t.Errorf("Expected the wait to end with the third check, got %+v after %d checks", health, checker.checks)
+	}
+
+	checker.checks, checker.readyAfter = 0, 1000
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	health, err = WaitKubernetesReady(ctx, kubernetes, "work", time.Millisecond)
+	var timeout *domain.OperationTimeoutError
+	if !errors.As(err, &timeout) {
+		t.Fatalf("Expected OperationTimeoutError, got %v", err)
+	}
+	if health == nil || health.Reason != "API server is not ready" {
+		t.Errorf("Expected the last result with the timeout, got %+v", health)
+	}
+}
@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/metrics"
+)
+
+const gib = 1 << 30
+
+// unknownProfile is the profile label of profiles that are neither declared
+// nor listed, so names made up by requests cannot add series without bound
+const unknownProfile = "unknown"
+
+// operationBuckets covers quick status calls up to slow first-time starts
+var operationBuckets = []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600, 1200}
+
+// InstrumentedUseCase records Prometheus metrics around another use case.
+// Only the default profile, declared profiles and those the last
+// ListProfiles returned get their own profile label.
+type InstrumentedUseCase struct {
+	next ColimaUseCaseInterface
+	cfg  *config.Config
+
+	mu     sync.Mutex
+	listed map[string]bool
+
+	operations     *metrics.CounterVec
+	durations      *metrics.HistogramVec
+	profileState   *metrics.GaugeVec
+	profileCPUs    *metrics.GaugeVec
+	profileMemory  *metrics.GaugeVec
+	profileDisk    *metrics.GaugeVec
+	dependencyInfo *metrics.GaugeVec
+}
+
+func NewInstrumentedUseCase(next ColimaUseCaseInterface, cfg *config.Config, registry *metrics.Registry) ColimaUseCaseInterface {
+	return &InstrumentedUseCase{
+		next:   next,
+		cfg:    cfg,
+		listed: make(map[string]bool),
+		operations: registry.NewCounterVec("colima_manager_operations_total",
+			"Use case operations by profile and outcome.", "operation", "profile", "outcome"),
+		durations: registry.NewHistogramVec("colima_manager_operation_duration_seconds",
+			"Duration of use case operations in seconds.", operationBuckets, "operation", "profile", "outcome"),
+		profileState: registry.NewGaugeVec("colima_manager_profile_state",
+			"Last observed profile state; 1 for the current state.", "profile", "state"),
+		profileCPUs: registry.NewGaugeVec("colima_manager_profile_cpus",
+			"CPUs configured for the profile.", "profile"),
+		profileMemory: registry.NewGaugeVec("colima_manager_profile_memory_bytes",
+			"Memory configured for the profile in bytes.", "profile"),
+		profileDisk: registry.NewGaugeVec("colima_manager_profile_disk_bytes",
+			"Disk size configured for the profile in bytes.", "profile"),
+		dependencyInfo: registry.NewGaugeVec("colima_manager_dependency_info",
+			"Installed dependency versions; always 1.", "dependency", "version"),
+	}
+}
+
+func (m *InstrumentedUseCase) CheckDependencies(ctx context.Context) (*domain.DependencyStatus, error) {
+	started := time.Now()
+	status, err := m.next.CheckDependencies(ctx)
+	m.observe("check_dependencies", "", started, err)
+	if status != nil {
+		m.recordDependencies(status)
+	}
+	return status, err
+}
+
+func (m *InstrumentedUseCase) UpdateDependencies(ctx context.Context) error {
+	started := time.Now()
+	err := m.next.UpdateDependencies(ctx)
+	m.observe("update_dependencies", "", started, err)
+	return err
+}
+
+func (m *InstrumentedUseCase) Start(ctx context.Context, config domain.ColimaConfig) error {
+	started := time.Now()
+	err := m.next.Start(ctx, config)
+	m.observe("start", m.profileLabel(config.Profile), started, err)
+	return err
+}
+
+func (m *InstrumentedUseCase) Stop(ctx context.Context, profile string) error {
+	started := time.Now()
+	err := m.next.Stop(ctx, profile)
+	m.observe("stop", m.profileLabel(profile), started, err)
+	return err
+}
+
+func (m *InstrumentedUseCase) Status(ctx context.Context, profile string) (*domain.ColimaStatus, error) {
+	started := time.Now()
+	status, err := m.next.Status(ctx, profile)
+	profile = m.profileLabel(profile)
+	m.observe("status", profile, started, err)
+	if profile != unknownProfile {
+		m.recordStatus(profile, status, err)
+	}
+	return status, err
+}
+
+func (m *InstrumentedUseCase) ListProfiles(ctx context.Context) ([]domain.ProfileInfo, error) {
+	started := time.Now()
+	profiles, err := m.next.ListProfiles(ctx)
+	m.observe("list_profiles", "", started, err)
+	if err != nil {
+		return profiles, err
+	}
+
+	listed := make(map[string]bool, len(profiles))
+	for _, p := range profiles {
+		listed[p.Name] = true
+		m.setState(p.Name, p.State)
+	}
+	// Profiles missing from the listing, e.g. cleaned ones, stop exporting
+	// their last status and resources
+	keep := func(profile string) bool { return listed[profile] }
+	for _, gauge := range []*metrics.GaugeVec{m.profileState, m.profileCPUs, m.profileMemory, m.profileDisk} {
+		gauge.DeleteUnless("profile", keep)
+	}
+	m.mu.Lock()
+	m.listed = listed
+	m.mu.Unlock()
+	return profiles, nil
+}
+
+func (m *InstrumentedUseCase) GetKubeConfig(ctx context.Context, profile string) (string, error) {
+	started := time.Now()
+	kubeconfig, err := m.next.GetKubeConfig(ctx, profile)
+	m.observe("kubeconfig", m.profileLabel(profile), started, err)
+	return kubeconfig, err
+}
+
+func (m *InstrumentedUseCase) PlanClean(ctx context.Context, req domain.CleanRequest) (*domain.CleanPlan, error) {
+	started := time.Now()
+	plan, err := m.next.PlanClean(ctx, req)
+	m.observe("plan_clean", m.cleanLabel(req), started, err)
+	return plan, err
+}
+
+func (m *InstrumentedUseCase) Clean(ctx context.Context, req domain.CleanRequest) error {
+	started := time.Now()
+	err := m.next.Clean(ctx, req)
+	profile := m.cleanLabel(req)
+	m.observe("clean", profile, started, err)
+	if err == nil && req.Profile != "" && profile != unknownProfile {
+		m.forget(profile)
+	}
+	return err
+}
+
+// profileLabel returns the profile label of profile, which is unknownProfile
+// unless profile is the default one, declared or listed
+func (m *InstrumentedUseCase) profileLabel(profile string) string {
+	profile = normalizeProfile(profile)
+	if profile == domain.DefaultColimaConfig().Profile {
+		return profile
+	}
+	if m.cfg != nil {
+		if _, ok := m.cfg.Profile(profile); ok {
+			return profile
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.listed[profile] {
+		return profile
+	}
+	return unknownProfile
+}
+
+// cleanLabel is the profile label of a clean, which is "all" for a clean of
+// every profile
+func (m *InstrumentedUseCase) cleanLabel(req domain.CleanRequest) string {
+	if req.Profile == "" {
+		return "all"
+	}
+	return m.profileLabel(req.Profile)
+}
+
+func (m *InstrumentedUseCase) observe(operation, profile string, started time.Time, err error) {
+	result := outcome(err)
+	m.operations.Inc(operation, profile, result)
+	m.durations.Observe(time.Since(started).Seconds(), operation, profile, result)
+}
+
+// recordStatus updates the profile gauges from a status call
+func (m *InstrumentedUseCase) recordStatus(profile string, status *domain.ColimaStatus, err error) {
+	var notFound *domain.ProfileNotFoundError
+	var notStarted *domain.ProfileNotStartedError
+	switch {
+	case err == nil && status != nil:
+		state := domain.ProfileStateStopped
+		if status.Status == domain.ProfileRunning {
+			state = domain.ProfileStateRunning
+		}
+		m.setState(profile, state)
+		m.profileCPUs.Set(float64(status.CPUs), profile)
+		m.profileMemory.Set(float64(status.Memory)*gib, profile)
+		m.profileDisk.Set(float64(status.DiskSize)*gib, profile)
+	case errors.As(err, &notFound):
+		m.forget(profile)
+		m.setState(profile, domain.ProfileStateAbsent)
+	case errors.As(err, &notStarted):
+		m.setState(profile, domain.ProfileStateStopped)
+	case errors.As(err, new(*domain.OperationCanceledError)):
+		// The caller went away; this says nothing about the profile
+	default:
+		m.setState(profile, domain.ProfileStateBroken)
+	}
+}
+
+func (m *InstrumentedUseCase) setState(profile string, state domain.ProfileState) {
+	m.profileState.DeleteMatching("profile", profile)
+	m.profileState.Set(1, profile, string(state))
+}
+
+func (m *InstrumentedUseCase) forget(profile string) {
+	m.profileState.DeleteMatching("profile", profile)
+	m.profileCPUs.DeleteMatching("profile", profile)
+	m.profileMemory.DeleteMatching("profile", profile)
+	m.profileDisk.DeleteMatching("profile", profile)
+}
+
+func (m *InstrumentedUseCase) recordDependencies(status *domain.DependencyStatus) {
+	versions := map[string]string{
+		"colima": status.ColimaVersion,
+		"lima":   status.LimaVersion,
+	}
+	for dependency, version := range versions {
+		m.dependencyInfo.DeleteMatching("dependency", dependency)
+		if version != "" {
+			m.dependencyInfo.Set(1, dependency, version)
+		}
+	}
+	m.dependencyInfo.DeleteMatching("dependency", "homebrew")
+	if status.Homebrew {
+		m.dependencyInfo.Set(1, "homebrew", "installed")
+	}
+}
+
+// outcome classifies err into a low-cardinality label value
+func outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	switch {
+	case errors.As(err, new(*domain.ProfileBusyError)):
+		return "busy"
+	case errors.As(err, new(*domain.ProfileNotFoundError)):
+		return "not_found"
+	case errors.As(err, new(*domain.ProfileNotStartedError)):
+		return "not_started"
+	case errors.As(err, new(*domain.ProfileUnreachableError)):
+		return "unreachable"
+	case errors.As(err, new(*domain.ProfileMalfunctionError)):
+		return "malfunction"
+	case errors.As(err, new(*domain.DependencyError)):
+		return "dependency_error"
+	case errors.As(err, new(*domain.OperationTimeoutError)):
+		return "timeout"
+	case errors.As(err, new(*domain.OperationCanceledError)):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/metrics"
+)
+
+func TestInstrumentedUseCase(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{
+		mockStatus: &domain.ColimaStatus{
+			Status:   domain.ProfileRunning,
+			CPUs:     4,
+			Memory:   8,
+			DiskSize: 60,
+		},
+	}
+	cfg := &config.Config{Profiles: map[string]config.ProfileConfig{"work": {}}}
+	registry := metrics.NewRegistry()
+	useCase := NewInstrumentedUseCase(NewColimaUseCase(mockRepo, cfg, nil), cfg, registry)
+
+	if _, err := useCase.Status(context.Background(), "work"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := useCase.Start(context.Background(), domain.ColimaConfig{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := useCase.CheckDependencies(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockRepo.mu.Lock()
+	mockRepo.mockError = &domain.ProfileNotStartedError{Profile: "work"}
+	mockRepo.mu.Unlock()
+	if _, err := useCase.Status(context.Background(), "work"); err == nil {
+		t.Fatal("Expected an error for a stopped profile")
+	}
+
+	var buf bytes.Buffer
+	if _, err := registry.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{
+		`colima_manager_operations_total{operation="status",profile="work",outcome="success"} 1`,
+		`colima_manager_operations_total{operation="status",profile="work",outcome="not_started"} 1`,
+		`colima_manager_operations_total{operation="start",profile="default",outcome="success"} 1`,
+		`colima_manager_operation_duration_seconds_count{operation="start",profile="default",outcome="success"} 1`,
+		`colima_manager_profile_state{profile="work",state="stopped"} 1`,
+		`colima_manager_profile_cpus{profile="work"} 4`,
+		`colima_manager_profile_memory_bytes{profile="work"} 8589934592`,
+		`colima_manager_dependency_info{dependency="homebrew",version="installed"} 1`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, `state="running"`) {
+		t.Errorf("Expected the running state to be replaced, got:\n%s", output)
+	}
+}
+
+func TestInstrumentedUseCaseProfileLabels(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{
+		mockStatus:   &domain.ColimaStatus{Status: domain.ProfileRunning, CPUs: 2},
+		mockProfiles: []domain.ProfileInfo{{Name: "scratch", State: domain.ProfileStateRunning, Listed: true}},
+	}
+	registry := metrics.NewRegistry()
+	useCase := NewInstrumentedUseCase(NewColimaUseCase(mockRepo, nil, nil), nil, registry)
+
+	for _, profile := range []string{"typo-1", "typo-2", "scratch"} {
+		if _, err := useCase.Status(context.Background(), profile); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if _, err := useCase.ListProfiles(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := useCase.Status(context.Background(), "scratch"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := registry.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{
+		`colima_manager_operations_total{operation="status",profile="unknown",outcome="success"} 3`,
+		`colima_manager_operations_total{operation="status",profile="scratch",outcome="success"} 1`,
+		`colima_manager_profile_cpus{profile="scratch"} 2`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, output)
+		}
+	}
+	for _, unwanted := range []string{`profile="typo-1"`, `colima_manager_profile_state{profile="unknown"`} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("Expected no series with %s, got:\n%s", unwanted, output)
+		}
+	}
+}
+
+func TestInstrumentedUseCaseForgetsUnlistedProfiles(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{
+		mockStatus: &domain.ColimaStatus{Status: domain.ProfileRunning, CPUs: 2},
+		mockProfiles: []domain.ProfileInfo{
+			{Name: "scratch", State: domain.ProfileStateRunning, Listed: true},
+			{Name: "work", State: domain.ProfileStateRunning, Listed: true},
+		},
+	}
+	registry := metrics.NewRegistry()
+	useCase := NewInstrumentedUseCase(NewColimaUseCase(mockRepo, nil, nil), nil, registry)
+
+	if _, err := useCase.ListProfiles(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, profile := range []string{"scratch", "work"} {
+		if _, err := useCase.Status(context.Background(), profile); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	mockRepo.mu.Lock()
+	mockRepo.mockProfiles = mockRepo.mockProfiles[1:]
+	mockRepo.mu.Unlock()
+	if _, err := useCase.ListProfiles(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := registry.WriteTo(&buf); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+	output := buf.String()
+
+	if !strings.Contains(output, `colima_manager_profile_cpus{profile="work"} 2`) {
+		t.Errorf("Expected the listed profile to keep its gauges, got:\n%s", output)
+	}
+	for _, unwanted := range []string{
+		`colima_manager_profile_state{profile="scratch"`,
+		`colima_manager_profile_cpus{profile="scratch"`,
+		`colima_manager_profile_memory_bytes{profile="scratch"`,
+		`colima_manager_profile_disk_bytes{profile="scratch"`,
+	} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("Expected no series with %s, got:\n%s", unwanted, output)
+		}
+	}
+}
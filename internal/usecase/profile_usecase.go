@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+type ProfileInterface interface {
+	GetProfile(ctx context.Context, name string) (config.ProfileConfig, error)
+	CreateProfile(ctx context.Context, name string, profile config.ProfileConfig) error
+	UpdateProfile(ctx context.Context, name string, profile config.ProfileConfig) error
+	DeleteProfile(ctx context.Context, name string) error
+	EffectiveProfile(ctx context.Context, name string) (*domain.EffectiveConfig, error)
+}
+
+// ProfileUseCase manages the profile definitions in the config file. It only
+// changes definitions; the profiles themselves are started, stopped and
+// removed through ColimaUseCase or the reconciler.
+type ProfileUseCase struct {
+	cfg *config.Config
+	log *logger.Logger
+}
+
+func NewProfileUseCase(cfg *config.Config) *ProfileUseCase {
+	return &ProfileUseCase{
+		cfg: cfg,
+		log: logger.GetLogger(),
+	}
+}
+
+// GetProfile returns the stored definition of name
+func (p *ProfileUseCase) GetProfile(ctx context.Context, name string) (config.ProfileConfig, error) {
+	profile, ok := p.cfg.Profile(name)
+	if !ok {
+		return config.ProfileConfig{}, &domain.ProfileNotFoundError{Profile: name}
+	}
+	return profile, nil
+}
+
+// CreateProfile stores a new definition
+func (p *ProfileUseCase) CreateProfile(ctx context.Context, name string, profile config.ProfileConfig) error {
+	log := p.log.WithContext(ctx)
+	if err := p.cfg.ValidateProfile(name, profile); err != nil {
+		return err
+	}
+
+	err := p.cfg.EditProfiles(func(profiles map[string]config.ProfileConfig) error {
+		if _, exists := profiles[name]; exists {
+			return &domain.ProfileExistsError{Profile: name}
+		}
+		profiles[name] = profile
+		return nil
+	})
+	if err != nil {
+		return log.LogError(err, "failed to create profile %s", name)
+	}
+	log.Info("Profile %s created in %s: %+v", name, p.cfg.Path(), profile)
+	return nil
+}
+
+// UpdateProfile replaces the definition of an existing profile
+func (p *ProfileUseCase) UpdateProfile(ctx context.Context, name string, profile config.ProfileConfig) error {
+	log := p.log.WithContext(ctx)
+	if err := p.cfg.ValidateProfile(name, profile); err != nil {
+		return err
+	}
+
+	err := p.cfg.EditProfiles(func(profiles map[string]config.ProfileConfig) error {
+		if _, exists := profiles[name]; !exists {
+			return &domain.ProfileNotFoundError{Profile: name}
+		}
+		profiles[name] = profile
+		return nil
+	})
+	if err != nil {
+		return log.LogError(err, "failed to update profile %s", name)
+	}
+	log.Info("Profile %s updated in %s: %+v", name, p.cfg.Path(), profile)
+	return nil
+}
+
+// DeleteProfile removes the definition of name. A running instance keeps
+// running but is no longer declared.
+func (p *ProfileUseCase) DeleteProfile(ctx context.Context, name string) error {
+	log := p.log.WithContext(ctx)
+	err := p.cfg.EditProfiles(func(profiles map[string]config.ProfileConfig) error {
+		if _, exists := profiles[name]; !exists {
+			return &domain.ProfileNotFoundError{Profile: name}
+		}
+		delete(profiles, name)
+		return nil
+	})
+	if err != nil {
+		return log.LogError(err, "failed to delete profile %s", name)
+	}
+	log.Info("Profile %s deleted from %s", name, p.cfg.Path())
+	return nil
+}
+
+// EffectiveProfile returns the configuration a start of name without
+// overrides would use. Undeclared names resolve to the defaults.
+func (p *ProfileUseCase) EffectiveProfile(ctx context.Context, name string) (*domain.EffectiveConfig, error) {
+	if err := domain.ValidateProfileName(name); err != nil {
+		return nil, err
+	}
+	effective := ResolveConfig(p.cfg, domain.ColimaConfig{Profile: name})
+	return &effective, nil
+}
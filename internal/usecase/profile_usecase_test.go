@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+func newProfileUseCase(t *testing.T) (*ProfileUseCase, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("profiles:\n  work:\n    cpus: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	detect := config.DetectHost
+	config.DetectHost = func() domain.HostCapacity { return domain.HostCapacity{CPUs: 16, Memory: 64} }
+	t.Cleanup(func() { config.DetectHost = detect })
+	cfg, err := config.LoadConfig(config.Flags{ConfigPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewProfileUseCase(cfg), path
+}
+
+func TestProfileLifecycle(t *testing.T) {
+	profiles, path := newProfileUseCase(t)
+	ctx := context.Background()
+
+	if err := profiles.CreateProfile(ctx, "k8s", config.ProfileConfig{CPUs: 4, Kubernetes: domain.Bool(true)}); err != nil {
+		t.Fatalf("CreateProfile failed: %v", err)
+	}
+	var exists *domain.ProfileExistsError
+	if err := profiles.CreateProfile(ctx, "k8s", config.ProfileConfig{}); !errors.As(err, &exists) {
+		t.Errorf("Expected ProfileExistsError, got %v", err)
+	}
+
+	if err := profiles.UpdateProfile(ctx, "work", config.ProfileConfig{CPUs: 8}); err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	var notFound *domain.ProfileNotFoundError
+	if err := profiles.UpdateProfile(ctx, "missing", config.ProfileConfig{}); !errors.As(err, &notFound) {
+		t.Errorf("Expected ProfileNotFoundError on update, got %v", err)
+	}
+
+	if err := profiles.DeleteProfile(ctx, "k8s"); err != nil {
+		t.Fatalf("DeleteProfile failed: %v", err)
+	}
+	if err := profiles.DeleteProfile(ctx, "k8s"); !errors.As(err, &notFound) {
+		t.Errorf("Expected ProfileNotFoundError on delete, got %v", err)
+	}
+
+	reloaded, err := config.LoadConfig(config.Flags{ConfigPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := reloaded.DeclaredProfiles()
+	if len(stored) != 1 || stored["work"].CPUs != 8 {
+		t.Errorf("Expected only work with 8 CPUs on disk, got %+v", stored)
+	}
+	if got, err := profiles.GetProfile(ctx, "work"); err != nil || got.CPUs != 8 {
+		t.Errorf("Expected GetProfile to return the update, got %+v (%v)", got, err)
+	}
+}
+
+func TestProfileValidation(t *testing.T) {
+	profiles, _ := newProfileUseCase(t)
+
+	tests := []struct {
+		name    string
+		profile config.ProfileConfig
+	}{
+		{"-leading-dash", config.ProfileConfig{}},
+		{"has space", config.ProfileConfig{}},
+		{"negative", config.ProfileConfig{CPUs: -1}},
+		{"vm", config.ProfileConfig{VMType: "hyperv"}},
+		{"runtime", config.ProfileConfig{Runtime: "podman"}},
+		{"state", config.ProfileConfig{DesiredState: "paused"}},
+		{"too-big", config.ProfileConfig{CPUs: 32}},
+		{"with/slash", config.ProfileConfig{}},
+	}
+	for _, tt := range tests {
+		var invalid *domain.ValidationError
+		if err := profiles.CreateProfile(context.Background(), tt.name, tt.profile); !errors.As(err, &invalid) {
+			t.Errorf("%s: expected ValidationError, got %v", tt.name, err)
+		}
+	}
+	if _, ok := profiles.cfg.Profile("vm"); ok {
+		t.Error("Expected invalid profiles not to be stored")
+	}
+}
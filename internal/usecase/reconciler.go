@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+const (
+	defaultReconcileInterval = 30 * time.Second
+	defaultMaxBackoff        = 10 * time.Minute
+)
+
+type ReconcilerInterface interface {
+	Status() *domain.ReconcileReport
+}
+
+// Reconciler drives declared profiles towards their desired_state
+type Reconciler struct {
+	useCase ColimaUseCaseInterface
+	cfg     *config.Config
+	log     *logger.Logger
+	now     func() time.Time
+
+	mu      sync.Mutex
+	lastRun *time.Time
+	states  map[string]*domain.ProfileReconcileStatus
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewReconciler(useCase ColimaUseCaseInterface, cfg *config.Config) *Reconciler {
+	return &Reconciler{
+		useCase: useCase,
+		cfg:     cfg,
+		log:     logger.GetLogger(),
+		now:     time.Now,
+		states:  make(map[string]*domain.ProfileReconcileStatus),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Run reconciles immediately and then on every interval until ctx is done or
+// Stop is called
+func (r *Reconciler) Run(ctx context.Context) {
+	interval := r.interval()
+	r.log.Info("Starting reconciler with interval %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.ReconcileOnce(ctx)
+		select {
+		case <-ctx.Done():
+			r.log.Info("Reconciler stopped")
+			return
+		case <-r.stop:
+			r.log.Info("Reconciler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileOnce performs a single pass over all managed profiles
+func (r *Reconciler) ReconcileOnce(ctx context.Context) {
+	ctx = domain.WithCaller(ctx, domain.Caller{Name: "reconciler"})
+	profiles := r.cfg.DeclaredProfiles()
+	names := make([]string, 0, len(profiles))
+	for name, profile := range profiles {
+		if profile.DesiredState != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	r.forget(names)
+
+	for _, name := range names {
+		if ctx.Err() != nil || r.stopped() {
+			return
+		}
+		r.reconcileProfile(ctx, name, profiles[name])
+	}
+
+	r.mu.Lock()
+	now := r.now()
+	r.lastRun = &now
+	r.mu.Unlock()
+}
+
+// Stop keeps Run from starting further operations. The operation in
+// progress, if any, runs on until its context is canceled.
+func (r *Reconciler) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+}
+
+func (r *Reconciler) stopped() bool {
+	select {
+	case <-r.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status returns a snapshot of the reconciler's state
+func (r *Reconciler) Status() *domain.ReconcileReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &domain.ReconcileReport{
+		Enabled:  r.cfg.Reconcile.Enabled,
+		Interval: r.interval().String(),
+		LastRun:  r.lastRun,
+		Profiles: make([]domain.ProfileReconcileStatus, 0, len(r.states)),
+	}
+	for _, state := range r.states {
+		report.Profiles = append(report.Profiles, *state)
+	}
+	sort.Slice(report.Profiles, func(i, j int) bool {
+		return report.Profiles[i].Name < report.Profiles[j].Name
+	})
+	return report
+}
+
+func (r *Reconciler) reconcileProfile(ctx context.Context, name string, profile config.ProfileConfig) {
+	state := r.state(name, profile.DesiredState)
+
+	now := r.now()
+	r.mu.Lock()
+	nextAttempt := state.NextAttempt
+	r.mu.Unlock()
+	if nextAttempt != nil && now.Before(*nextAttempt) {
+		r.log.Debug("Reconciler backing off profile %s until %s", name, nextAttempt.Format(time.RFC3339))
+		return
+	}
+
+	actual, statusErr := r.actualState(ctx, name)
+	action, err := r.converge(ctx, name, profile, actual)
+	if action == "" && err == nil {
+		// Nothing to do, but a broken profile is still worth reporting
+		err = statusErr
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state.ActualState = actual
+	state.LastReconciled = &now
+	state.Converged = action == "" && err == nil
+	if action != "" {
+		state.LastAction = action
+	}
+
+	var busy *domain.ProfileBusyError
+	switch {
+	case err == nil:
+		state.LastError = ""
+		state.Failures = 0
+		state.NextAttempt = nil
+	case errors.As(err, &busy):
+		// Another operation owns the profile; look again on the next pass
+		state.LastError = err.Error()
+	default:
+		state.LastError = err.Error()
+		state.Failures++
+		next := now.Add(r.backoff(state.Failures))
+		state.NextAttempt = &next
+		r.log.Error("Reconciling profile %s failed (attempt %d, retry at %s): %v",
+			name, state.Failures, next.Format(time.RFC3339), err)
+	}
+}
+
+// converge performs the action needed to move name from actual to its desired
+// state and returns the action taken, if any
+func (r *Reconciler) converge(ctx context.Context, name string, profile config.ProfileConfig, actual domain.ProfileState) (string, error) {
+	switch profile.DesiredState {
+	case domain.DesiredRunning:
+		if actual == domain.ProfileStateRunning {
+			return "", nil
+		}
+		r.log.Info("Reconciler starting profile %s (actual: %s)", name, actual)
+		return "start", r.useCase.Start(ctx, domain.ColimaConfig{Profile: name})
+	case domain.DesiredStopped:
+		if actual != domain.ProfileStateRunning {
+			return "", nil
+		}
+		r.log.Info("Reconciler stopping profile %s", name)
+		return "stop", r.useCase.Stop(ctx, name)
+	case domain.DesiredAbsent:
+		if actual == domain.ProfileStateAbsent {
+			return "", nil
+		}
+		r.log.Info("Reconciler deleting profile %s (actual: %s)", name, actual)
+		// The reconciler confirms its own plan; desired_state absent is the
+		// confirmation
+		req := domain.CleanRequest{Profile: name}
+		plan, err := r.useCase.PlanClean(ctx, req)
+		if err != nil {
+			return "delete", err
+		}
+		req.Token = plan.Token
+		return "delete", r.useCase.Clean(ctx, req)
+	default:
+		return "", fmt.Errorf("unknown desired_state %q", profile.DesiredState)
+	}
+}
+
+// actualState maps the profile status onto a ProfileState
+func (r *Reconciler) actualState(ctx context.Context, name string) (domain.ProfileState, error) {
+	status, err := r.useCase.Status(ctx, name)
+	if err != nil {
+		var notFound *domain.ProfileNotFoundError
+		var notStarted *domain.ProfileNotStartedError
+		switch {
+		case errors.As(err, &notFound):
+			return domain.ProfileStateAbsent, nil
+		case errors.As(err, &notStarted):
+			return domain.ProfileStateStopped, nil
+		default:
+			return domain.ProfileStateBroken, err
+		}
+	}
+	if status.Status == domain.ProfileRunning {
+		return domain.ProfileStateRunning, nil
+	}
+	return domain.ProfileStateStopped, nil
+}
+
+// state returns the tracked status for name, creating it on first use
+func (r *Reconciler) state(name, desired string) *domain.ProfileReconcileStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.states[name]
+	if !ok {
+		state = &domain.ProfileReconcileStatus{Name: name}
+		r.states[name] = state
+	}
+	state.DesiredState = desired
+	return state
+}
+
+// forget drops the status of profiles that are no longer managed, such as
+// those deleted through the profile API, so the report and their backoff do
+// not outlive them
+func (r *Reconciler) forget(managed []string) {
+	keep := make(map[string]bool, len(managed))
+	for _, name := range managed {
+		keep[name] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.states {
+		if !keep[name] {
+			delete(r.states, name)
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt after failures
+// consecutive failures, doubling from the interval up to MaxBackoff
+func (r *Reconciler) backoff(failures int) time.Duration {
+	maxBackoff := r.cfg.Reconcile.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	delay := r.interval()
+	for i := 1; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+func (r *Reconciler) interval() time.Duration {
+	if r.cfg.Reconcile.Interval > 0 {
+		return r.cfg.Reconcile.Interval
+	}
+	return defaultReconcileInterval
+}
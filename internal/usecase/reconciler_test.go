@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+func TestReconcilerConverges(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{
+		statusFn: func(profile string) (*domain.ColimaStatus, error) {
+			switch profile {
+			case "web":
+				return nil, &domain.ProfileNotStartedError{Profile: profile}
+			case "batch", "old":
+				return &domain.ColimaStatus{Status: domain.ProfileRunning, Profile: profile}, nil
+			case "ok":
+				return &domain.ColimaStatus{Status: domain.ProfileRunning, Profile: profile}, nil
+			}
+			return nil, &domain.ProfileNotFoundError{Profile: profile}
+		},
+	}
+	cfg := &config.Config{Profiles: map[string]config.ProfileConfig{
+		"web":       {CPUs: 2, Memory: 4, DesiredState: domain.DesiredRunning},
+		"batch":     {DesiredState: domain.DesiredStopped},
+		"old":       {DesiredState: domain.DesiredAbsent},
+		"ok":        {DesiredState: domain.DesiredRunning},
+		"unmanaged": {},
+	}}
+	reconciler := NewReconciler(NewColimaUseCase(mockRepo, cfg, nil), cfg)
+
+	reconciler.ReconcileOnce(context.Background())
+
+	mockRepo.mu.Lock()
+	if !mockRepo.startCalled || mockRepo.startConfig.Profile != "web" || mockRepo.startConfig.CPUs != 2 {
+		t.Errorf("Expected web to be started with its config, got %+v", mockRepo.startConfig)
+	}
+	if len(mockRepo.stoppedProfiles) != 1 || mockRepo.stoppedProfiles[0] != "batch" {
+		t.Errorf("Expected batch to be stopped, got %v", mockRepo.stoppedProfiles)
+	}
+	if len(mockRepo.cleanedProfiles) != 1 || mockRepo.cleanedProfiles[0] != "old" {
+		t.Errorf("Expected old to be deleted, got %v", mockRepo.cleanedProfiles)
+	}
+	mockRepo.mu.Unlock()
+
+	report := reconciler.Status()
+	if report.LastRun == nil {
+		t.Error("Expected last run to be recorded")
+	}
+	if len(report.Profiles) != 4 {
+		t.Fatalf("Expected 4 managed profiles, got %d", len(report.Profiles))
+	}
+
+	actions := map[string]string{}
+	for _, p := range report.Profiles {
+		actions[p.Name] = p.LastAction
+		if p.Name == "ok" && !p.Converged {
+			t.Error("Expected ok to be converged")
+		}
+	}
+	expected := map[string]string{"batch": "stop", "ok": "", "old": "delete", "web": "start"}
+	for name, action := range expected {
+		if actions[name] != action {
+			t.Errorf("Expected action %q for %s, got %q", action, name, actions[name])
+		}
+	}
+}
+
+func TestReconcilerStopsThroughUseCase(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{
+		statusFn: func(profile string) (*domain.ColimaStatus, error) {
+			return &domain.ColimaStatus{Status: domain.ProfileRunning, Profile: profile}, nil
+		},
+	}
+	cfg := &config.Config{Profiles: map[string]config.ProfileConfig{
+		"batch": {DesiredState: domain.DesiredStopped},
+	}}
+	publisher := &recordingPublisher{}
+	reconciler := NewReconciler(NewColimaUseCase(mockRepo, cfg, publisher), cfg)
+
+	// A profile held by another operation is left alone
+	domain.GetProfileLock().Lock("batch")
+	reconciler.ReconcileOnce(context.Background())
+	if len(mockRepo.stoppedProfiles) != 0 {
+		t.Errorf("Expected no stop while the profile is locked, got %v", mockRepo.stoppedProfiles)
+	}
+	domain.GetProfileLock().Unlock("batch")
+
+	reconciler.ReconcileOnce(context.Background())
+	if len(mockRepo.stoppedProfiles) != 1 {
+		t.Fatalf("Expected batch to be stopped once, got %v", mockRepo.stoppedProfiles)
+	}
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	if len(publisher.events) != 1 || publisher.events[0].Type != domain.EventStopped {
+		t.Errorf("Expected a stopped event, got %+v", publisher.events)
+	}
+}
+
+func TestReconcilerBackoff(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{mockError: errors.New("boom")}
+	mockRepo.statusFn = func(profile string) (*domain.ColimaStatus, error) {
+		return nil, &domain.ProfileNotFoundError{Profile: profile}
+	}
+	cfg := &config.Config{Profiles: map[string]config.ProfileConfig{
+		"web": {DesiredState: domain.DesiredRunning},
+	}}
+	cfg.Reconcile.Interval = time.Minute
+	cfg.Reconcile.MaxBackoff = 3 * time.Minute
+
+	reconciler := NewReconciler(NewColimaUseCase(mockRepo, cfg, nil), cfg)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	reconciler.now = func() time.Time { return now }
+
+	reconciler.ReconcileOnce(context.Background())
+	state := reconciler.Status().Profiles[0]
+	if state.Failures != 1 || state.LastError == "" {
+		t.Fatalf("Expected one recorded failure, got %+v", state)
+	}
+	if !state.NextAttempt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected next attempt after 1m, got %v", state.NextAttempt)
+	}
+
+	// Passes inside the backoff window do nothing
+	now = now.Add(30 * time.Second)
+	reconciler.ReconcileOnce(context.Background())
+	if state := reconciler.Status().Profiles[0]; state.Failures != 1 {
+		t.Errorf("Expected no attempt during backoff, got %d failures", state.Failures)
+	}
+
+	// Backoff doubles and is capped at MaxBackoff
+	for _, expected := range []time.Duration{2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		now = *reconciler.Status().Profiles[0].NextAttempt
+		reconciler.ReconcileOnce(context.Background())
+		state := reconciler.Status().Profiles[0]
+		if !state.NextAttempt.Equal(now.Add(expected)) {
+			t.Errorf("Expected backoff %v after %d failures, got %v", expected, state.Failures, state.NextAttempt.Sub(now))
+		}
+	}
+
+	// A deleted profile leaves the report along with its backoff
+	if err := cfg.EditProfiles(func(profiles map[string]config.ProfileConfig) error {
+		delete(profiles, "web")
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to delete the profile: %v", err)
+	}
+	reconciler.ReconcileOnce(context.Background())
+	if profiles := reconciler.Status().Profiles; len(profiles) != 0 {
+		t.Errorf("Expected deleted profiles to be dropped, got %+v", profiles)
+	}
+}
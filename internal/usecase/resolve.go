@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+// ResolveConfig merges a start request with the configuration. Each setting
+// comes from the first layer that sets it: the request, the named profile's
+// definition, the config file's defaults section and finally the built-in
+// defaults. An explicit false sets network_address and kubernetes like any
+// other value.
+func ResolveConfig(cfg *config.Config, req domain.ColimaConfig) domain.EffectiveConfig {
+	name := normalizeProfile(req.Profile)
+	profile, declared := cfg.Profile(name)
+	defaults := cfg.Defaults
+	builtIn := domain.DefaultColimaConfig()
+
+	effective := domain.EffectiveConfig{
+		Profile:  name,
+		Declared: declared,
+		Config:   domain.ColimaConfig{Profile: name},
+		Sources:  make(map[string]domain.ConfigSource),
+	}
+	var profileLayer *config.ProfileConfig
+	if declared {
+		profileLayer = &profile
+	}
+
+	effective.Config.CPUs = resolveInt(effective.Sources, "cpus", req.CPUs, profileLayer, defaults.CPUs, builtIn.CPUs,
+		func(p *config.ProfileConfig) int { return p.CPUs })
+	effective.Config.Memory = resolveInt(effective.Sources, "memory", req.Memory, profileLayer, defaults.Memory, builtIn.Memory,
+		func(p *config.ProfileConfig) int { return p.Memory })
+	effective.Config.DiskSize = resolveInt(effective.Sources, "disk_size", req.DiskSize, profileLayer, defaults.DiskSize, builtIn.DiskSize,
+		func(p *config.ProfileConfig) int { return p.DiskSize })
+	effective.Config.VMType = resolveString(effective.Sources, "vm_type", req.VMType, profileLayer, defaults.VMType, builtIn.VMType,
+		func(p *config.ProfileConfig) string { return p.VMType })
+	effective.Config.Runtime = resolveString(effective.Sources, "runtime", req.Runtime, profileLayer, defaults.Runtime, builtIn.Runtime,
+		func(p *config.ProfileConfig) string { return p.Runtime })
+	effective.Config.NetworkAddress = resolveBool(effective.Sources, "network_address", req.NetworkAddress, profileLayer,
+		defaults.NetworkAddress, builtIn.NetworkAddress, func(p *config.ProfileConfig) *bool { return p.NetworkAddress })
+	effective.Config.Kubernetes = resolveBool(effective.Sources, "kubernetes", req.Kubernetes, profileLayer,
+		defaults.Kubernetes, builtIn.Kubernetes, func(p *config.ProfileConfig) *bool { return p.Kubernetes })
+	return effective
+}
+
+func resolveInt(sources map[string]domain.ConfigSource, field string, req int, profile *config.ProfileConfig,
+	defaults, builtIn int, get func(*config.ProfileConfig) int) int {
+	switch {
+	case req != 0:
+		sources[field] = domain.SourceRequest
+		return req
+	case profile != nil && get(profile) != 0:
+		sources[field] = domain.SourceProfile
+		return get(profile)
+	case defaults != 0:
+		sources[field] = domain.SourceDefaults
+		return defaults
+	default:
+		sources[field] = domain.SourceBuiltIn
+		return builtIn
+	}
+}
+
+func resolveString(sources map[string]domain.ConfigSource, field string, req string, profile *config.ProfileConfig,
+	defaults, builtIn string, get func(*config.ProfileConfig) string) string {
+	switch {
+	case req != "":
+		sources[field] = domain.SourceRequest
+		return req
+	case profile != nil && get(profile) != "":
+		sources[field] = domain.SourceProfile
+		return get(profile)
+	case defaults != "":
+		sources[field] = domain.SourceDefaults
+		return defaults
+	default:
+		sources[field] = domain.SourceBuiltIn
+		return builtIn
+	}
+}
+
+func resolveBool(sources map[string]domain.ConfigSource, field string, req *bool, profile *config.ProfileConfig,
+	defaults, builtIn *bool, get func(*config.ProfileConfig) *bool) *bool {
+	switch {
+	case req != nil:
+		sources[field] = domain.SourceRequest
+		return domain.Bool(*req)
+	case profile != nil && get(profile) != nil:
+		sources[field] = domain.SourceProfile
+		return domain.Bool(*get(profile))
+	case defaults != nil:
+		sources[field] = domain.SourceDefaults
+		return domain.Bool(*defaults)
+	default:
+		sources[field] = domain.SourceBuiltIn
+		return domain.Bool(domain.Enabled(builtIn))
+	}
+}
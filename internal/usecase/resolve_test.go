@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+func TestResolveConfig(t *testing.T) {
+	cfg := &config.Config{
+		Profiles: map[string]config.ProfileConfig{
+			"work":    {CPUs: 6, VMType: "qemu", Kubernetes: domain.Bool(true)},
+			"lean":    {NetworkAddress: domain.Bool(false), Kubernetes: domain.Bool(false)},
+			"default": {Memory: 4},
+		},
+		Defaults: config.ProfileConfig{CPUs: 2, Memory: 8, NetworkAddress: domain.Bool(true)},
+	}
+	builtIn := domain.DefaultColimaConfig()
+
+	tests := []struct {
+		name     string
+		req      domain.ColimaConfig
+		declared bool
+		want     domain.ColimaConfig
+		sources  map[string]domain.ConfigSource
+	}{
+		{
+			name:     "request over profile over defaults",
+			req:      domain.ColimaConfig{Profile: "work", Memory: 16},
+			declared: true,
+			want: domain.ColimaConfig{Profile: "work", CPUs: 6, Memory: 16, DiskSize: builtIn.DiskSize,
+				VMType: "qemu", Runtime: builtIn.Runtime, NetworkAddress: domain.Bool(true), Kubernetes: domain.Bool(true)},
+			sources: map[string]domain.ConfigSource{
+				"cpus":            domain.SourceProfile,
+				"memory":          domain.SourceRequest,
+				"disk_size":       domain.SourceBuiltIn,
+				"vm_type":         domain.SourceProfile,
+				"runtime":         domain.SourceBuiltIn,
+				"network_address": domain.SourceDefaults,
+				"kubernetes":      domain.SourceProfile,
+			},
+		},
+		{
+			name:     "empty profile name is default",
+			req:      domain.ColimaConfig{NetworkAddress: domain.Bool(true)},
+			declared: true,
+			want: domain.ColimaConfig{Profile: "default", CPUs: 2, Memory: 4, DiskSize: builtIn.DiskSize,
+				VMType: builtIn.VMType, Runtime: builtIn.Runtime, NetworkAddress: domain.Bool(true), Kubernetes: domain.Bool(true)},
+			sources: map[string]domain.ConfigSource{
+				"cpus":            domain.SourceDefaults,
+				"memory":          domain.SourceProfile,
+				"disk_size":       domain.SourceBuiltIn,
+				"vm_type":         domain.SourceBuiltIn,
+				"runtime":         domain.SourceBuiltIn,
+				"network_address": domain.SourceRequest,
+				"kubernetes":      domain.SourceBuiltIn,
+			},
+		},
+		{
+			name: "undeclared profile",
+			req:  domain.ColimaConfig{Profile: "scratch"},
+			want: domain.ColimaConfig{Profile: "scratch", CPUs: 2, Memory: 8, DiskSize: builtIn.DiskSize,
+				VMType: builtIn.VMType, Runtime: builtIn.Runtime, NetworkAddress: domain.Bool(true), Kubernetes: domain.Bool(true)},
+			sources: map[string]domain.ConfigSource{
+				"cpus":            domain.SourceDefaults,
+				"memory":          domain.SourceDefaults,
+				"disk_size":       domain.SourceBuiltIn,
+				"vm_type":         domain.SourceBuiltIn,
+				"runtime":         domain.SourceBuiltIn,
+				"network_address": domain.SourceDefaults,
+				"kubernetes":      domain.SourceBuiltIn,
+			},
+		},
+		{
+			name: "request turns switches off",
+			req:  domain.ColimaConfig{Profile: "work", NetworkAddress: domain.Bool(false), Kubernetes: domain.Bool(false)},
+			want: domain.ColimaConfig{Profile: "work", CPUs: 6, Memory: 8, DiskSize: builtIn.DiskSize,
+				VMType: "qemu", Runtime: builtIn.Runtime, NetworkAddress: domain.Bool(false), Kubernetes: domain.Bool(false)},
+			declared: true,
+			sources: map[string]domain.ConfigSource{
+				"cpus":            domain.SourceProfile,
+				"memory":          domain.SourceDefaults,
+				"disk_size":       domain.SourceBuiltIn,
+				"vm_type":         domain.SourceProfile,
+				"runtime":         domain.SourceBuiltIn,
+				"network_address": domain.SourceRequest,
+				"kubernetes":      domain.SourceRequest,
+			},
+		},
+		{
+			name:     "profile turns switches off",
+			req:      domain.ColimaConfig{Profile: "lean"},
+			declared: true,
+			want: domain.ColimaConfig{Profile: "lean", CPUs: 2, Memory: 8, DiskSize: builtIn.DiskSize,
+				VMType: builtIn.VMType, Runtime: builtIn.Runtime, NetworkAddress: domain.Bool(false), Kubernetes: domain.Bool(false)},
+			sources: map[string]domain.ConfigSource{
+				"cpus":            domain.SourceDefaults,
+				"memory":          domain.SourceDefaults,
+				"disk_size":       domain.SourceBuiltIn,
+				"vm_type":         domain.SourceBuiltIn,
+				"runtime":         domain.SourceBuiltIn,
+				"network_address": domain.SourceProfile,
+				"kubernetes":      domain.SourceProfile,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveConfig(cfg, tt.req)
+			if !reflect.DeepEqual(got.Config, tt.want) {
+				t.Errorf("Expected config %+v, got %+v", tt.want, got.Config)
+			}
+			if got.Declared != tt.declared || got.Profile != tt.want.Profile {
+				t.Errorf("Expected profile %s declared=%v, got %s declared=%v", tt.want.Profile, tt.declared, got.Profile, got.Declared)
+			}
+			for field, source := range tt.sources {
+				if got.Sources[field] != source {
+					t.Errorf("Expected %s from %s, got %s", field, source, got.Sources[field])
+				}
+			}
+			if len(got.Sources) != len(tt.sources) {
+				t.Errorf("Expected %d sources, got %v", len(tt.sources), got.Sources)
+			}
+		})
+	}
+}
+
+func TestResolveConfigDefaultsTurnSwitchesOff(t *testing.T) {
+	cfg := &config.Config{
+		Defaults: config.ProfileConfig{NetworkAddress: domain.Bool(false), Kubernetes: domain.Bool(false)},
+	}
+
+	got := ResolveConfig(cfg, domain.ColimaConfig{Profile: "scratch"})
+	if domain.Enabled(got.Config.NetworkAddress) || domain.Enabled(got.Config.Kubernetes) {
+		t.Errorf("Expected the defaults to turn both switches off, got %+v", got.Config)
+	}
+	for _, field := range []string{"network_address", "kubernetes"} {
+		if got.Sources[field] != domain.SourceDefaults {
+			t.Errorf("Expected %s from defaults, got %s", field, got.Sources[field])
+		}
+	}
+	if got.Config.NetworkAddress == nil || got.Config.Kubernetes == nil {
+		t.Errorf("Expected resolved switches to be set, got %+v", got.Config)
+	}
+}
@@ -0,0 +1,350 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+)
+
+const (
+	// defaultShutdownGracePeriod is used when server.shutdown_grace_period is not set
+	defaultShutdownGracePeriod = time.Minute
+	// cancelTimeout bounds how long canceled operations get to return
+	cancelTimeout = 10 * time.Second
+	// lockPollInterval is how often we check whether profile locks were released
+	lockPollInterval = 250 * time.Millisecond
+)
+
+type ShutdownInterface interface {
+	Request(policy domain.ShutdownPolicy) (domain.ShutdownPolicy, error)
+	Interrupted() []domain.InterruptedOperation
+}
+
+// Shutdown coordinates stopping the manager: it stops accepting work, waits
+// for running operations up to the grace period, cancels what is left and
+// then stops profiles according to the shutdown policy. Stopping a profile
+// never stops the manager; only a shutdown request does.
+type Shutdown struct {
+	useCase ColimaUseCaseInterface
+	jobs    JobRunnerInterface
+	cfg     *config.Config
+	log     *logger.Logger
+	// ctx is canceled once the grace period runs out
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	policy      domain.ShutdownPolicy
+	requested   chan struct{}
+	interrupted []domain.InterruptedOperation // left behind by the previous run
+}
+
+func NewShutdown(useCase ColimaUseCaseInterface, jobs JobRunnerInterface, cfg *config.Config) *Shutdown {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Shutdown{
+		useCase:   useCase,
+		jobs:      jobs,
+		cfg:       cfg,
+		log:       logger.GetLogger(),
+		ctx:       ctx,
+		cancel:    cancel,
+		requested: make(chan struct{}),
+	}
+}
+
+// Request asks the manager to shut down with policy, or with the configured
+// policy when empty. It returns the policy that will be applied.
+func (s *Shutdown) Request(policy domain.ShutdownPolicy) (domain.ShutdownPolicy, error) {
+	if policy == "" {
+		policy = s.configuredPolicy()
+	}
+	if !policy.Valid() {
+		return "", &domain.InvalidShutdownPolicyError{Policy: string(policy)}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.requested:
+		return s.policy, &domain.ShuttingDownError{}
+	default:
+	}
+
+	s.log.Info("Shutdown requested with policy %s", policy)
+	s.policy = policy
+	close(s.requested)
+	return policy, nil
+}
+
+// Requested is closed once a shutdown has been requested
+func (s *Shutdown) Requested() <-chan struct{} {
+	return s.requested
+}
+
+// ShuttingDown reports whether a shutdown has been requested
+func (s *Shutdown) ShuttingDown() bool {
+	select {
+	case <-s.requested:
+		return true
+	default:
+		return false
+	}
+}
+
+// Context is canceled when the grace period runs out. Operations that run
+// outside the job runner, such as the reconciler's, should use it.
+func (s *Shutdown) Context() context.Context {
+	return s.ctx
+}
+
+// Run drains pending operations and applies the requested policy, or the
+// configured one if nothing was requested
+func (s *Shutdown) Run(ctx context.Context) *domain.ShutdownReport {
+	if _, err := s.Request(""); err != nil && !isShuttingDown(err) {
+		s.log.Error("Invalid configured shutdown policy, leaving profiles running: %v", err)
+	}
+
+	s.mu.Lock()
+	policy := s.policy
+	s.mu.Unlock()
+	if policy == "" {
+		policy = domain.ShutdownLeaveRunning
+	}
+
+	report := &domain.ShutdownReport{Policy: policy, Stopped: []string{}}
+	report.Interrupted = s.drain(ctx)
+	if len(report.Interrupted) > 0 {
+		if err := s.saveInterrupted(report.Interrupted); err != nil {
+			s.log.Error("Failed to record interrupted operations: %v", err)
+		}
+	}
+
+	stopCtx := domain.WithCaller(ctx, domain.Caller{Name: "shutdown"})
+	for _, name := range s.profilesToStop(ctx, policy) {
+		s.log.Info("Stopping profile %s before shutdown (%s)", name, policy)
+		if err := s.useCase.Stop(stopCtx, name); err != nil {
+			if report.Failed == nil {
+				report.Failed = make(map[string]string)
+			}
+			report.Failed[name] = err.Error()
+			continue
+		}
+		report.Stopped = append(report.Stopped, name)
+	}
+
+	s.log.Info("Shutdown complete - Policy: %s, Stopped: %v, Failed: %d, Interrupted: %d",
+		report.Policy, report.Stopped, len(report.Failed), len(report.Interrupted))
+	return report
+}
+
+// drain waits up to the grace period for jobs and locked operations to
+// finish, then cancels the rest and returns what it canceled
+func (s *Shutdown) drain(ctx context.Context) []domain.InterruptedOperation {
+	grace := s.gracePeriod()
+	graceCtx, cancel := context.WithTimeout(ctx, grace)
+	defer cancel()
+
+	err := s.jobs.Drain(graceCtx)
+	if err == nil {
+		err = waitUnlocked(graceCtx)
+	}
+	if err == nil {
+		return nil
+	}
+
+	interrupted := s.pending()
+	s.log.Error("Grace period of %s expired, canceling %d operations", grace, len(interrupted))
+	s.cancel()
+	s.jobs.Cancel()
+
+	cancelCtx, cancel := context.WithTimeout(ctx, cancelTimeout)
+	defer cancel()
+	if err := s.jobs.Drain(cancelCtx); err == nil {
+		err = waitUnlocked(cancelCtx)
+	}
+	if err != nil {
+		s.log.Error("Operations still running after being canceled: %v", err)
+	}
+	return interrupted
+}
+
+// pending describes the operations holding profile locks and the jobs that
+// have not finished, linking the two where they match
+func (s *Shutdown) pending() []domain.InterruptedOperation {
+	now := time.Now()
+	var ops []domain.InterruptedOperation
+	jobs := make(map[string]*domain.Job)
+	for _, job := range s.jobs.List() {
+		if !job.State.Finished() {
+			jobs[job.Operation+"/"+job.Profile] = job
+		}
+	}
+
+	for _, lock := range domain.GetProfileLock().Held() {
+		op := domain.InterruptedOperation{
+			Operation:     lock.Operation,
+			Profile:       lock.Profile,
+			StartedAt:     lock.Since,
+			InterruptedAt: now,
+		}
+		key := lock.Operation + "/" + lock.Profile
+		if job, ok := jobs[key]; ok {
+			op.JobID = job.ID
+			delete(jobs, key)
+		}
+		ops = append(ops, op)
+	}
+
+	for _, job := range jobs {
+		started := job.CreatedAt
+		if job.StartedAt != nil {
+			started = *job.StartedAt
+		}
+		ops = append(ops, domain.InterruptedOperation{
+			Operation:     job.Operation,
+			Profile:       job.Profile,
+			JobID:         job.ID,
+			StartedAt:     started,
+			InterruptedAt: now,
+		})
+	}
+
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Profile < ops[j].Profile })
+	return ops
+}
+
+// waitUnlocked blocks until no profile lock is held or ctx is done
+func waitUnlocked(ctx context.Context) error {
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+
+	for len(domain.GetProfileLock().Held()) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// LoadInterrupted reads the operations interrupted by the previous shutdown,
+// logs them and removes the record so they are reported once
+func (s *Shutdown) LoadInterrupted() []domain.InterruptedOperation {
+	path := s.cfg.Server.InterruptedFile
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			s.log.Error("Failed to read interrupted operations: %v", err)
+		}
+		return nil
+	}
+
+	var ops []domain.InterruptedOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		s.log.Error("Ignoring malformed interrupted operations in %s: %v", path, err)
+	}
+	for _, op := range ops {
+		s.log.Error("The last shutdown interrupted %s of profile %s (started %s, interrupted %s)",
+			op.Operation, op.Profile, op.StartedAt.Format(time.RFC3339), op.InterruptedAt.Format(time.RFC3339))
+	}
+	if err := os.Remove(path); err != nil {
+		s.log.Error("Failed to remove %s: %v", path, err)
+	}
+
+	s.mu.Lock()
+	s.interrupted = ops
+	s.mu.Unlock()
+	return ops
+}
+
+// Interrupted returns the operations the previous shutdown interrupted
+func (s *Shutdown) Interrupted() []domain.InterruptedOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]domain.InterruptedOperation{}, s.interrupted...)
+}
+
+// saveInterrupted writes ops to the interrupted file through a rename so a
+// crash never leaves half a record behind
+func (s *Shutdown) saveInterrupted(ops []domain.InterruptedOperation) error {
+	path := s.cfg.Server.InterruptedFile
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(ops, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// profilesToStop returns the running profiles that policy stops
+func (s *Shutdown) profilesToStop(ctx context.Context, policy domain.ShutdownPolicy) []string {
+	if policy == domain.ShutdownLeaveRunning {
+		return nil
+	}
+
+	profiles, err := s.useCase.ListProfiles(ctx)
+	if err != nil {
+		s.log.Error("Failed to list profiles for shutdown: %v", err)
+		return nil
+	}
+
+	var names []string
+	for _, profile := range profiles {
+		if profile.State != domain.ProfileStateRunning {
+			continue
+		}
+		if policy == domain.ShutdownStopManaged && !profile.Declared {
+			continue
+		}
+		names = append(names, profile.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isShuttingDown(err error) bool {
+	_, ok := err.(*domain.ShuttingDownError)
+	return ok
+}
+
+func (s *Shutdown) configuredPolicy() domain.ShutdownPolicy {
+	policy := domain.ShutdownPolicy(s.cfg.Server.ShutdownPolicy)
+	if policy == "" {
+		return domain.ShutdownLeaveRunning
+	}
+	return policy
+}
+
+func (s *Shutdown) gracePeriod() time.Duration {
+	if s.cfg.Server.ShutdownGracePeriod > 0 {
+		return s.cfg.Server.ShutdownGracePeriod
+	}
+	return defaultShutdownGracePeriod
+}
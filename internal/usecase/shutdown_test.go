@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+)
+
+func TestShutdownStopManaged(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{mockProfiles: []domain.ProfileInfo{
+		{Name: "web", State: domain.ProfileStateRunning, Declared: true},
+		{Name: "batch", State: domain.ProfileStateStopped, Declared: true},
+		{Name: "scratch", State: domain.ProfileStateRunning},
+	}}
+	cfg := &config.Config{}
+	cfg.Server.ShutdownPolicy = string(domain.ShutdownStopManaged)
+	useCase := NewColimaUseCase(mockRepo, cfg, nil)
+	runner := NewJobRunner(useCase)
+	shutdown := NewShutdown(useCase, runner, cfg)
+
+	job, err := runner.SubmitStart(context.Background(), domain.ColimaConfig{Profile: "web"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	policy, err := shutdown.Request("")
+	if err != nil || policy != domain.ShutdownStopManaged {
+		t.Fatalf("Expected the configured policy, got %q (%v)", policy, err)
+	}
+	select {
+	case <-shutdown.Requested():
+	default:
+		t.Fatal("Expected Requested to be closed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	report := shutdown.Run(ctx)
+
+	if job, _ = runner.Get(job.ID); !job.State.Finished() {
+		t.Errorf("Expected the pending job to be drained, got state %s", job.State)
+	}
+	if len(report.Stopped) != 1 || report.Stopped[0] != "web" {
+		t.Errorf("Expected only web to be stopped, got %v", report.Stopped)
+	}
+	if _, err := runner.SubmitStop(context.Background(), "web"); !errors.As(err, new(*domain.ShuttingDownError)) {
+		t.Errorf("Expected ShuttingDownError after drain, got %v", err)
+	}
+}
+
+func TestShutdownLeaveRunning(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{mockProfiles: []domain.ProfileInfo{
+		{Name: "web", State: domain.ProfileStateRunning, Declared: true},
+	}}
+	cfg := &config.Config{}
+	cfg.Server.ShutdownPolicy = string(domain.ShutdownStopAll)
+	useCase := NewColimaUseCase(mockRepo, cfg, nil)
+	shutdown := NewShutdown(useCase, NewJobRunner(useCase), cfg)
+
+	if _, err := shutdown.Request("reboot"); !errors.As(err, new(*domain.InvalidShutdownPolicyError)) {
+		t.Errorf("Expected InvalidShutdownPolicyError, got %v", err)
+	}
+	if _, err := shutdown.Request(domain.ShutdownLeaveRunning); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := shutdown.Request(domain.ShutdownStopAll); !errors.As(err, new(*domain.ShuttingDownError)) {
+		t.Errorf("Expected ShuttingDownError on a second request, got %v", err)
+	}
+
+	report := shutdown.Run(context.Background())
+	if report.Policy != domain.ShutdownLeaveRunning || len(report.Stopped) != 0 {
+		t.Errorf("Expected no profiles to be stopped, got %+v", report)
+	}
+	mockRepo.mu.Lock()
+	defer mockRepo.mu.Unlock()
+	if len(mockRepo.stoppedProfiles) != 0 {
+		t.Errorf("Expected no Stop calls, got %v", mockRepo.stoppedProfiles)
+	}
+}
+
+func TestShutdownInterruptsAfterGracePeriod(t *testing.T) {
+	domain.ResetProfileLock()
+
+	mockRepo := &mockRepository{startFn: func(ctx context.Context) error {
+		<-ctx.Done()
+		return &domain.OperationCanceledError{Operation: "start"}
+	}}
+	cfg := &config.Config{}
+	cfg.Server.ShutdownGracePeriod = 100 * time.Millisecond
+	cfg.Server.InterruptedFile = filepath.Join(t.TempDir(), "interrupted.json")
+	useCase := NewColimaUseCase(mockRepo, cfg, nil)
+	runner := NewJobRunner(useCase)
+	shutdown := NewShutdown(useCase, runner, cfg)
+
+	job, err := runner.SubmitStart(context.Background(), domain.ColimaConfig{Profile: "web"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for !domain.GetProfileLock().IsLocked("web") {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	report := shutdown.Run(context.Background())
+	if len(report.Interrupted) != 1 {
+		t.Fatalf("Expected one interrupted operation, got %+v", report.Interrupted)
+	}
+	op := report.Interrupted[0]
+	if op.Operation != "start" || op.Profile != "web" || op.JobID != job.ID {
+		t.Errorf("Unexpected interrupted operation: %+v", op)
+	}
+	if shutdown.Context().Err() == nil {
+		t.Error("Expected the operations context to be canceled")
+	}
+	if job, _ = runner.Get(job.ID); job.State != domain.JobInterrupted {
+		t.Errorf("Expected state %s, got %s", domain.JobInterrupted, job.State)
+	}
+
+	next := NewShutdown(useCase, NewJobRunner(useCase), cfg)
+	if loaded := next.LoadInterrupted(); len(loaded) != 1 || loaded[0].JobID != job.ID {
+		t.Errorf("Expected the interrupted start to be loaded, got %+v", loaded)
+	}
+	if len(next.Interrupted()) != 1 {
+		t.Errorf("Expected Interrupted to report the loaded operation, got %+v", next.Interrupted())
+	}
+	if _, err := os.Stat(cfg.Server.InterruptedFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the interrupted file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import "time"
+
+// Caller identifies who asked for an operation
+type Caller struct {
+	// Name is the authenticated token name, or the internal component
+	// (reconciler, shutdown, auto-start, cli) that started the operation
+	Name string `json:"name,omitempty"`
+	// Address is the remote address of an API request
+	Address string `json:"address,omitempty"`
+}
+
+// AuditRecord describes one mutating operation and how it ended
+type AuditRecord struct {
+	Time      time.Time     `json:"time"`
+	Operation string        `json:"operation"`
+	Profile   string        `json:"profile,omitempty"`
+	Config    *ColimaConfig `json:"config,omitempty"` // requested config of a start
+	Caller    Caller        `json:"caller"`
+	JobID     string        `json:"job_id,omitempty"`
+	Duration  float64       `json:"duration_seconds"`
+	Outcome   string        `json:"outcome"`
+	Error     string        `json:"error,omitempty"`
+	ErrorCode string        `json:"error_code,omitempty"`
+}
+
+// AuditFilter selects audit records; zero fields match everything
+type AuditFilter struct {
+	Profile   string
+	Operation string
+	Since     time.Time
+	// Limit keeps only the newest records when positive
+	Limit int
+}
+
+// Matches reports whether record passes the filter
+func (f AuditFilter) Matches(record AuditRecord) bool {
+	if f.Profile != "" && record.Profile != f.Profile {
+		return false
+	}
+	if f.Operation != "" && record.Operation != f.Operation {
+		return false
+	}
+	return f.Since.IsZero() || !record.Time.Before(f.Since)
+}
@@ -0,0 +1,171 @@
+// Package api holds the request and response types of the colima-manager
+// HTTP API. It depends on nothing else in the module, so programs outside it
+// can use these types with pkg/client.
+package api
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DependencyStatus represents the status of required dependencies
+type DependencyStatus struct {
+	Homebrew      bool   `json:"homebrew"`
+	HomebrewPath  string `json:"homebrew_path,omitempty"`
+	Colima        bool   `json:"colima"`
+	ColimaVersion string `json:"colima_version,omitempty"`
+	ColimaPath    string `json:"colima_path,omitempty"`
+	Lima          bool   `json:"lima"`
+	LimaVersion   string `json:"lima_version,omitempty"`
+}
+
+// Profile states as reported by colima
+const (
+	ProfileRunning = "Running"
+	ProfileStopped = "Stopped"
+)
+
+// ColimaStatus represents the status of Colima
+type ColimaStatus struct {
+	Status            string `json:"status"`
+	CPUs              int    `json:"cpus"`
+	Memory            int    `json:"memory"`    // GiB
+	DiskSize          int    `json:"disk_size"` // GiB
+	Kubernetes        bool   `json:"kubernetes"`
+	KubernetesVersion string `json:"kubernetes_version,omitempty"`
+	Profile           string `json:"profile"`
+	Arch              string `json:"arch,omitempty"`
+	Runtime           string `json:"runtime,omitempty"`
+	VMType            string `json:"vm_type,omitempty"`
+	MountType         string `json:"mount_type,omitempty"`
+	IPAddress         string `json:"ip_address,omitempty"`
+	DockerSocket      string `json:"docker_socket,omitempty"` // docker runtime only
+	ContainerdSocket  string `json:"containerd_socket,omitempty"`
+}
+
+// ProfileState summarizes the observed state of a profile
+type ProfileState string
+
+const (
+	ProfileStateRunning ProfileState = "running"
+	ProfileStateStopped ProfileState = "stopped"
+	ProfileStateBroken  ProfileState = "broken"
+	ProfileStateAbsent  ProfileState = "absent" // declared in config but never created
+)
+
+// ProfileInfo describes a profile as seen by config, the filesystem and colima
+type ProfileInfo struct {
+	Name     string       `json:"name"`
+	State    ProfileState `json:"state"`
+	Declared bool         `json:"declared"`
+	OnDisk   bool         `json:"on_disk"`
+	Listed   bool         `json:"listed"`
+	Arch     string       `json:"arch,omitempty"`
+	Runtime  string       `json:"runtime,omitempty"`
+	CPUs     int          `json:"cpus,omitempty"`
+	Memory   int          `json:"memory,omitempty"`    // GiB
+	DiskSize int          `json:"disk_size,omitempty"` // GiB
+}
+
+// CleanRequest represents the clean operation parameters
+type CleanRequest struct {
+	Profile string `json:"profile"` // empty string means clean all
+	// Preserve lists profiles a clean of all profiles leaves alone
+	Preserve []string `json:"preserve,omitempty"`
+	// PreserveConfig keeps the colima.yaml of each removed profile so it can
+	// be recreated with the same settings
+	PreserveConfig bool `json:"preserve_config,omitempty"`
+	// Token confirms the plan returned by a dry run of the same request
+	Token string `json:"token,omitempty"`
+}
+
+// CleanPath is a file or directory a clean removes or keeps
+type CleanPath struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"` // bytes allocated on disk
+}
+
+// CleanPlan lists everything a clean removes. Its token confirms exactly
+// this plan and stops matching once the profiles, paths or contexts change.
+type CleanPlan struct {
+	Profiles       []string    `json:"profiles"`
+	Directories    []CleanPath `json:"directories"`
+	DockerContexts []string    `json:"docker_contexts"`
+	KubeConfigs    []CleanPath `json:"kubeconfigs"`
+	// Preserved lists the files inside removed directories that are kept
+	Preserved []CleanPath `json:"preserved,omitempty"`
+	// TotalSize is the space freed, not counting preserved files
+	TotalSize int64  `json:"total_size"`
+	Token     string `json:"token,omitempty"`
+}
+
+// DockerContext represents a Docker context configuration
+type DockerContext struct {
+	Name    string `json:"name"`
+	Profile string `json:"profile"`
+	Socket  string `json:"socket"`
+	// Current is set for the context the docker CLI uses by default
+	Current bool `json:"current"`
+	// Orphaned is set when the profile of the context no longer exists
+	Orphaned bool `json:"orphaned"`
+	// Broken is set when the socket the context points at does not exist
+	Broken bool `json:"broken"`
+}
+
+// ColimaConfig represents the configuration for starting Colima. The
+// switches are pointers so that an explicit false can be told from unset.
+type ColimaConfig struct {
+	CPUs           int    `json:"cpus,omitempty"`
+	Memory         int    `json:"memory,omitempty"`
+	DiskSize       int    `json:"disk_size,omitempty"`
+	VMType         string `json:"vm_type,omitempty"`
+	Runtime        string `json:"runtime,omitempty"`
+	NetworkAddress *bool  `json:"network_address,omitempty"`
+	Kubernetes     *bool  `json:"kubernetes,omitempty"`
+	Profile        string `json:"profile,omitempty"`
+}
+
+// Bool returns a pointer to v, to set a switch of ColimaConfig or
+// ProfileConfig
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Enabled reports whether switch v is set and true
+func Enabled(v *bool) bool {
+	return v != nil && *v
+}
+
+// String formats c like %+v would, with the switches shown as true, false
+// or unset instead of pointer addresses
+func (c ColimaConfig) String() string {
+	return fmt.Sprintf("{CPUs:%d Memory:%d DiskSize:%d VMType:%s Runtime:%s NetworkAddress:%s Kubernetes:%s Profile:%s}",
+		c.CPUs, c.Memory, c.DiskSize, c.VMType, c.Runtime,
+		switchString(c.NetworkAddress), switchString(c.Kubernetes), c.Profile)
+}
+
+func switchString(v *bool) string {
+	if v == nil {
+		return "unset"
+	}
+	return strconv.FormatBool(*v)
+}
+
+// ConfigSource names the layer a resolved start setting came from
+type ConfigSource string
+
+const (
+	SourceRequest  ConfigSource = "request"  // the start request
+	SourceProfile  ConfigSource = "profile"  // the profile's definition in the config file
+	SourceDefaults ConfigSource = "defaults" // the defaults section of the config file
+	SourceBuiltIn  ConfigSource = "built-in" // DefaultColimaConfig
+)
+
+// EffectiveConfig is the configuration a start of Profile uses, with the
+// source of each setting keyed by its JSON field name
+type EffectiveConfig struct {
+	Profile  string                  `json:"profile"`
+	Declared bool                    `json:"declared"`
+	Config   ColimaConfig            `json:"config"`
+	Sources  map[string]ConfigSource `json:"sources"`
+}
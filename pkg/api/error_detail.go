@@ -0,0 +1,133 @@
+package api
+
+import "errors"
+
+// Error codes reported by the API alongside the error message
+const (
+	CodeProfileNotFound    = "profile_not_found"
+	CodeProfileNotStarted  = "profile_not_started"
+	CodeProfileUnreachable = "profile_unreachable"
+	CodeProfileMalfunction = "profile_malfunction"
+	CodeProfileBusy        = "profile_busy"
+	CodeDependencyError    = "dependency_error"
+	CodeDockerContextError = "docker_context_error"
+	CodeOperationTimeout   = "operation_timeout"
+	CodeOperationCanceled  = "operation_canceled"
+	CodeJobNotFound        = "job_not_found"
+	CodeShuttingDown       = "shutting_down"
+	CodeProfileExists      = "profile_exists"
+	CodeValidationFailed   = "validation_failed"
+	CodeCleanNotConfirmed  = "clean_not_confirmed"
+	CodeKubeConfigError    = "kubeconfig_error"
+)
+
+// ErrorDetail is the wire form of a domain error. It carries enough of the
+// original error's fields for clients to rebuild it with Err.
+type ErrorDetail struct {
+	Error      string `json:"error"`
+	Code       string `json:"code,omitempty"`
+	Profile    string `json:"profile,omitempty"`
+	Operation  string `json:"operation,omitempty"`
+	Dependency string `json:"dependency,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	JobID      string `json:"job_id,omitempty"`
+	Path       string `json:"path,omitempty"`
+	// Fields lists the invalid settings of a validation_failed error
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// NewErrorDetail describes err; errors that are not domain errors get no code
+func NewErrorDetail(err error) *ErrorDetail {
+	detail := &ErrorDetail{Error: err.Error()}
+
+	var (
+		notFound    *ProfileNotFoundError
+		notStarted  *ProfileNotStartedError
+		unreachable *ProfileUnreachableError
+		malfunction *ProfileMalfunctionError
+		busy        *ProfileBusyError
+		dependency  *DependencyError
+		dockerCtx   *DockerContextError
+		timeout     *OperationTimeoutError
+		canceled    *OperationCanceledError
+		jobNotFound *JobNotFoundError
+		shutdown    *ShuttingDownError
+		exists      *ProfileExistsError
+		invalid     *ValidationError
+		unconfirmed *CleanNotConfirmedError
+		kubeconfig  *KubeConfigError
+	)
+	switch {
+	case errors.As(err, &notFound):
+		detail.Code, detail.Profile = CodeProfileNotFound, notFound.Profile
+	case errors.As(err, &notStarted):
+		detail.Code, detail.Profile = CodeProfileNotStarted, notStarted.Profile
+	case errors.As(err, &unreachable):
+		detail.Code, detail.Profile, detail.Reason = CodeProfileUnreachable, unreachable.Profile, unreachable.Reason
+	case errors.As(err, &malfunction):
+		detail.Code, detail.Profile, detail.Reason = CodeProfileMalfunction, malfunction.Profile, malfunction.Reason
+	case errors.As(err, &busy):
+		detail.Code, detail.Profile = CodeProfileBusy, busy.Profile
+	case errors.As(err, &dependency):
+		detail.Code, detail.Dependency, detail.Reason = CodeDependencyError, dependency.Dependency, dependency.Reason
+	case errors.As(err, &dockerCtx):
+		detail.Code, detail.Operation = CodeDockerContextError, dockerCtx.Operation
+		detail.Profile, detail.Reason = dockerCtx.Profile, dockerCtx.Reason
+	case errors.As(err, &timeout):
+		detail.Code, detail.Operation = CodeOperationTimeout, timeout.Operation
+	case errors.As(err, &canceled):
+		detail.Code, detail.Operation = CodeOperationCanceled, canceled.Operation
+	case errors.As(err, &jobNotFound):
+		detail.Code, detail.JobID = CodeJobNotFound, jobNotFound.ID
+	case errors.As(err, &shutdown):
+		detail.Code = CodeShuttingDown
+	case errors.As(err, &exists):
+		detail.Code, detail.Profile = CodeProfileExists, exists.Profile
+	case errors.As(err, &invalid):
+		detail.Code, detail.Profile, detail.Fields = CodeValidationFailed, invalid.Profile, invalid.Fields
+	case errors.As(err, &unconfirmed):
+		detail.Code, detail.Reason = CodeCleanNotConfirmed, unconfirmed.Reason
+	case errors.As(err, &kubeconfig):
+		detail.Code, detail.Path, detail.Reason = CodeKubeConfigError, kubeconfig.Path, kubeconfig.Reason
+	}
+	return detail
+}
+
+// Err rebuilds the domain error described by d, or returns nil when d does not
+// carry a domain error code
+func (d *ErrorDetail) Err() error {
+	switch d.Code {
+	case CodeProfileNotFound:
+		return &ProfileNotFoundError{Profile: d.Profile}
+	case CodeProfileNotStarted:
+		return &ProfileNotStartedError{Profile: d.Profile}
+	case CodeProfileUnreachable:
+		return &ProfileUnreachableError{Profile: d.Profile, Reason: d.Reason}
+	case CodeProfileMalfunction:
+		return &ProfileMalfunctionError{Profile: d.Profile, Reason: d.Reason}
+	case CodeProfileBusy:
+		return &ProfileBusyError{Profile: d.Profile}
+	case CodeDependencyError:
+		return &DependencyError{Dependency: d.Dependency, Reason: d.Reason}
+	case CodeDockerContextError:
+		return &DockerContextError{Operation: d.Operation, Profile: d.Profile, Reason: d.Reason}
+	case CodeOperationTimeout:
+		return &OperationTimeoutError{Operation: d.Operation}
+	case CodeOperationCanceled:
+		return &OperationCanceledError{Operation: d.Operation}
+	case CodeJobNotFound:
+		return &JobNotFoundError{ID: d.JobID}
+	case CodeShuttingDown:
+		return &ShuttingDownError{}
+	case CodeProfileExists:
+		return &ProfileExistsError{Profile: d.Profile}
+	case CodeValidationFailed:
+		return &ValidationError{Profile: d.Profile, Fields: d.Fields}
+	case CodeCleanNotConfirmed:
+		return &CleanNotConfirmedError{Reason: d.Reason}
+	case CodeKubeConfigError:
+		return &KubeConfigError{Path: d.Path, Reason: d.Reason}
+	default:
+		return nil
+	}
+}
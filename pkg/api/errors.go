@@ -0,0 +1,152 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Custom error types. ErrorDetail carries them over the wire.
+type ProfileNotFoundError struct {
+	Profile string
+}
+
+func (e *ProfileNotFoundError) Error() string {
+	return fmt.Sprintf("profile '%s' does not exist", e.Profile)
+}
+
+type ProfileNotStartedError struct {
+	Profile string
+}
+
+func (e *ProfileNotStartedError) Error() string {
+	return fmt.Sprintf("profile '%s' is not started", e.Profile)
+}
+
+type ProfileUnreachableError struct {
+	Profile string
+	Reason  string
+}
+
+func (e *ProfileUnreachableError) Error() string {
+	return fmt.Sprintf("profile '%s' is unreachable: %s", e.Profile, e.Reason)
+}
+
+type ProfileMalfunctionError struct {
+	Profile string
+	Reason  string
+}
+
+func (e *ProfileMalfunctionError) Error() string {
+	return fmt.Sprintf("profile '%s' is malfunctioning: %s", e.Profile, e.Reason)
+}
+
+type ProfileBusyError struct {
+	Profile string
+}
+
+func (e *ProfileBusyError) Error() string {
+	return fmt.Sprintf("profile '%s' is currently busy with another operation", e.Profile)
+}
+
+type DependencyError struct {
+	Dependency string
+	Reason     string
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("%s dependency error: %s", e.Dependency, e.Reason)
+}
+
+type DockerContextError struct {
+	Operation string
+	Profile   string
+	Reason    string
+}
+
+func (e *DockerContextError) Error() string {
+	return fmt.Sprintf("docker context %s failed for profile '%s': %s", e.Operation, e.Profile, e.Reason)
+}
+
+type OperationTimeoutError struct {
+	Operation string
+}
+
+func (e *OperationTimeoutError) Error() string {
+	return fmt.Sprintf("operation '%s' timed out", e.Operation)
+}
+
+type OperationCanceledError struct {
+	Operation string
+}
+
+func (e *OperationCanceledError) Error() string {
+	return fmt.Sprintf("operation '%s' was canceled", e.Operation)
+}
+
+// ProfileExistsError is returned when creating a profile definition whose
+// name is taken
+type ProfileExistsError struct {
+	Profile string
+}
+
+func (e *ProfileExistsError) Error() string {
+	return fmt.Sprintf("profile '%s' is already defined", e.Profile)
+}
+
+// CleanNotConfirmedError is returned for a clean whose token does not
+// confirm the current plan
+type CleanNotConfirmedError struct {
+	Reason string
+}
+
+func (e *CleanNotConfirmedError) Error() string {
+	return fmt.Sprintf("clean not confirmed: %s", e.Reason)
+}
+
+type JobNotFoundError struct {
+	ID string
+}
+
+func (e *JobNotFoundError) Error() string {
+	return fmt.Sprintf("job '%s' does not exist", e.ID)
+}
+
+type ShuttingDownError struct{}
+
+func (e *ShuttingDownError) Error() string {
+	return "colima-manager is shutting down"
+}
+
+// KubeConfigError is returned when a shared kubeconfig file cannot be read,
+// locked or written
+type KubeConfigError struct {
+	Path   string
+	Reason string
+}
+
+func (e *KubeConfigError) Error() string {
+	return fmt.Sprintf("kubeconfig %s: %s", e.Path, e.Reason)
+}
+
+// FieldError describes an invalid setting by its JSON name
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the invalid settings of a profile's configuration
+type ValidationError struct {
+	Profile string
+	Fields  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	if e.Profile == "" {
+		return "invalid configuration: " + strings.Join(messages, "; ")
+	}
+	return fmt.Sprintf("invalid configuration of profile '%s': %s", e.Profile, strings.Join(messages, "; "))
+}
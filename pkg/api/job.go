@@ -0,0 +1,35 @@
+package api
+
+import "time"
+
+// JobState represents the lifecycle state of an asynchronous job
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	// JobInterrupted jobs were canceled because the manager shut down
+	JobInterrupted JobState = "interrupted"
+)
+
+// Finished reports whether the job has reached a terminal state
+func (s JobState) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobInterrupted
+}
+
+// Job represents a long-running operation executed in the background
+type Job struct {
+	ID         string     `json:"id"`
+	Operation  string     `json:"operation"`
+	Profile    string     `json:"profile,omitempty"`
+	State      JobState   `json:"state"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Output     string     `json:"output,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	// ErrorDetail describes Error in the same form as API error responses
+	ErrorDetail *ErrorDetail `json:"error_detail,omitempty"`
+}
@@ -0,0 +1,13 @@
+package api
+
+// KubeConfigMerge describes a change to a shared kubeconfig file
+type KubeConfigMerge struct {
+	Profile string `json:"profile"`
+	Path    string `json:"path"`
+	// Context names the cluster, user and context merged or pruned
+	Context string `json:"context"`
+	// Changed is false when the file already matched
+	Changed bool `json:"changed"`
+	// Backup is a copy of the file from before the change
+	Backup string `json:"backup,omitempty"`
+}
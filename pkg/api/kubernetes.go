@@ -0,0 +1,35 @@
+package api
+
+import "time"
+
+// KubernetesHealth is the readiness of a profile's Kubernetes cluster as its
+// API server reports it
+type KubernetesHealth struct {
+	Profile string `json:"profile"`
+	Server  string `json:"server,omitempty"`
+	// Ready is set when /readyz passes and every node is Ready
+	Ready bool `json:"ready"`
+	// Reason explains why the cluster is not ready
+	Reason    string             `json:"reason,omitempty"`
+	APIServer KubernetesAPICheck `json:"api_server"`
+	Nodes     []KubernetesNode   `json:"nodes"`
+	CheckedAt time.Time          `json:"checked_at"`
+}
+
+// KubernetesAPICheck is the outcome of the API server's /readyz endpoint
+type KubernetesAPICheck struct {
+	Ready bool `json:"ready"`
+	// StatusCode is zero when the server could not be reached
+	StatusCode int `json:"status_code,omitempty"`
+	// Detail is the body /readyz answered with, listing failed checks, or
+	// why it could not be reached
+	Detail string `json:"detail,omitempty"`
+}
+
+// KubernetesNode is the Ready condition of one Kubernetes node
+type KubernetesNode struct {
+	Name    string `json:"name"`
+	Ready   bool   `json:"ready"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
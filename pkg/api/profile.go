@@ -0,0 +1,26 @@
+package api
+
+import "fmt"
+
+// ProfileConfig declares a profile. Unset fields are omitted when the
+// profile is written back to the config file; an explicit false is kept.
+type ProfileConfig struct {
+	CPUs           int    `yaml:"cpus,omitempty" json:"cpus,omitempty"`
+	Memory         int    `yaml:"memory,omitempty" json:"memory,omitempty"`       // GiB
+	DiskSize       int    `yaml:"disk_size,omitempty" json:"disk_size,omitempty"` // GiB
+	VMType         string `yaml:"vm_type,omitempty" json:"vm_type,omitempty"`
+	Runtime        string `yaml:"runtime,omitempty" json:"runtime,omitempty"`
+	NetworkAddress *bool  `yaml:"network_address,omitempty" json:"network_address,omitempty"`
+	Kubernetes     *bool  `yaml:"kubernetes,omitempty" json:"kubernetes,omitempty"`
+	// DesiredState is running, stopped or absent; empty leaves the profile
+	// unmanaged by the reconciler
+	DesiredState string `yaml:"desired_state,omitempty" json:"desired_state,omitempty"`
+}
+
+// String formats p like %+v would, with the switches shown as true, false
+// or unset instead of pointer addresses
+func (p ProfileConfig) String() string {
+	return fmt.Sprintf("{CPUs:%d Memory:%d DiskSize:%d VMType:%s Runtime:%s NetworkAddress:%s Kubernetes:%s DesiredState:%s}",
+		p.CPUs, p.Memory, p.DiskSize, p.VMType, p.Runtime,
+		switchString(p.NetworkAddress), switchString(p.Kubernetes), p.DesiredState)
+}
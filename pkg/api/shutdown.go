@@ -0,0 +1,21 @@
+package api
+
+// ShutdownPolicy decides what happens to running profiles when the manager
+// shuts down
+type ShutdownPolicy string
+
+const (
+	ShutdownLeaveRunning ShutdownPolicy = "leave-running"
+	ShutdownStopManaged  ShutdownPolicy = "stop-managed" // profiles declared in config
+	ShutdownStopAll      ShutdownPolicy = "stop-all"
+)
+
+// Valid reports whether p is a known policy
+func (p ShutdownPolicy) Valid() bool {
+	switch p {
+	case ShutdownLeaveRunning, ShutdownStopManaged, ShutdownStopAll:
+		return true
+	default:
+		return false
+	}
+}
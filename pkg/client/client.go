@@ -0,0 +1,521 @@
+// Package client is a typed Go client for the colima-manager HTTP API.
+//
+// Requests and responses use the types of package api. Errors returned by the
+// API are mapped back onto its error types, so callers can use errors.As with
+// *ProfileBusyError, *ProfileNotFoundError and friends exactly as they would
+// against the use case directly.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gqadonis/colima-manager/pkg/api"
+)
+
+const (
+	defaultRequestTimeout = 30 * time.Second
+	defaultBusyRetries    = 3
+	defaultBusyDelay      = 2 * time.Second
+	defaultPollInterval   = time.Second
+)
+
+// Client talks to a colima-manager daemon over TCP or a unix socket
+type Client struct {
+	baseURL        string
+	http           *http.Client
+	token          string
+	requestTimeout time.Duration
+	busyRetries    int
+	busyDelay      time.Duration
+	pollInterval   time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient replaces the HTTP client used for requests. For unix socket
+// addresses its transport must dial the socket itself.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithToken sends token as a bearer token with every request
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRequestTimeout bounds each request to timeout on top of the caller's
+// context. Zero leaves requests bounded by the context alone.
+// UpdateDependencies is never bounded by it, since it waits for brew.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.requestTimeout = timeout
+	}
+}
+
+// WithBusyRetry retries requests rejected with profile_busy up to retries
+// times, waiting delay between attempts. Zero retries disables retrying.
+func WithBusyRetry(retries int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.busyRetries = retries
+		c.busyDelay = delay
+	}
+}
+
+// WithPollInterval sets how often job status is polled while waiting
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = interval
+	}
+}
+
+// New creates a client for address, which is an http:// or https:// base URL,
+// a tcp://host:port address, a bare host:port or a unix:///path socket
+func New(address string, opts ...Option) (*Client, error) {
+	c := &Client{
+		http:           &http.Client{},
+		requestTimeout: defaultRequestTimeout,
+		busyRetries:    defaultBusyRetries,
+		busyDelay:      defaultBusyDelay,
+		pollInterval:   defaultPollInterval,
+	}
+
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		socket := strings.TrimPrefix(address, "unix://")
+		if socket == "" {
+			return nil, fmt.Errorf("address %q has no socket path", address)
+		}
+		c.baseURL = "http://unix"
+		c.http.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}
+	case strings.HasPrefix(address, "http://"), strings.HasPrefix(address, "https://"):
+		if _, err := url.Parse(address); err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		c.baseURL = strings.TrimSuffix(address, "/")
+	default:
+		hostPort := strings.TrimPrefix(address, "tcp://")
+		if _, _, err := net.SplitHostPort(hostPort); err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", address, err)
+		}
+		c.baseURL = "http://" + hostPort
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Ping reports whether a daemon is answering at the client's address. Any
+// HTTP response counts, including authentication failures.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("daemon not reachable: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *Client) CheckDependencies(ctx context.Context) (*api.DependencyStatus, error) {
+	var status api.DependencyStatus
+	if err := c.do(ctx, http.MethodGet, "/dependencies", nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// UpdateDependencies waits for the daemon to upgrade the dependencies, which
+// can take minutes; only ctx bounds it. Giving up cancels the upgrade.
+func (c *Client) UpdateDependencies(ctx context.Context) error {
+	return c.doWithin(ctx, 0, http.MethodPost, "/dependencies/update", nil, nil, nil)
+}
+
+// Start submits a start job and waits for it to finish
+func (c *Client) Start(ctx context.Context, config api.ColimaConfig) error {
+	job, err := c.SubmitStart(ctx, config)
+	if err != nil {
+		return err
+	}
+	return c.waitSucceeded(ctx, job.ID)
+}
+
+// Stop submits a stop job and waits for it to finish
+func (c *Client) Stop(ctx context.Context, profile string) error {
+	job, err := c.SubmitStop(ctx, profile)
+	if err != nil {
+		return err
+	}
+	return c.waitSucceeded(ctx, job.ID)
+}
+
+// PlanClean asks for a dry run of req. The plan's token confirms a Clean of
+// the same request.
+func (c *Client) PlanClean(ctx context.Context, req api.CleanRequest) (*api.CleanPlan, error) {
+	var plan api.CleanPlan
+	if err := c.do(ctx, http.MethodPost, "/clean", url.Values{"dry_run": {"true"}}, req, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Clean submits a clean job and waits for it to finish. req.Token must come
+// from PlanClean.
+func (c *Client) Clean(ctx context.Context, req api.CleanRequest) error {
+	job, err := c.SubmitClean(ctx, req)
+	if err != nil {
+		return err
+	}
+	return c.waitSucceeded(ctx, job.ID)
+}
+
+func (c *Client) Status(ctx context.Context, profile string) (*api.ColimaStatus, error) {
+	var status api.ColimaStatus
+	if err := c.do(ctx, http.MethodGet, "/status", profileQuery(profile), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *Client) ListProfiles(ctx context.Context) ([]api.ProfileInfo, error) {
+	var profiles []api.ProfileInfo
+	if err := c.do(ctx, http.MethodGet, "/profiles", nil, nil, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+func (c *Client) GetKubeConfig(ctx context.Context, profile string) (string, error) {
+	var kubeconfig bytes.Buffer
+	if err := c.do(ctx, http.MethodGet, "/kubeconfig", profileQuery(profile), nil, &kubeconfig); err != nil {
+		return "", err
+	}
+	return kubeconfig.String(), nil
+}
+
+// GetProfile returns the stored definition of a profile
+func (c *Client) GetProfile(ctx context.Context, name string) (api.ProfileConfig, error) {
+	var profile api.ProfileConfig
+	err := c.do(ctx, http.MethodGet, "/profiles/"+url.PathEscape(name)+"/config", nil, nil, &profile)
+	return profile, err
+}
+
+// EffectiveProfile returns the configuration a start of the profile would use
+func (c *Client) EffectiveProfile(ctx context.Context, name string) (*api.EffectiveConfig, error) {
+	var effective api.EffectiveConfig
+	if err := c.do(ctx, http.MethodGet, "/profiles/"+url.PathEscape(name)+"/effective", nil, nil, &effective); err != nil {
+		return nil, err
+	}
+	return &effective, nil
+}
+
+// CreateProfile stores a new profile definition in the daemon's config file
+func (c *Client) CreateProfile(ctx context.Context, name string, profile api.ProfileConfig) error {
+	body := struct {
+		Name string `json:"name"`
+		api.ProfileConfig
+	}{name, profile}
+	return c.do(ctx, http.MethodPost, "/profiles", nil, body, nil)
+}
+
+// UpdateProfile replaces the definition of an existing profile
+func (c *Client) UpdateProfile(ctx context.Context, name string, profile api.ProfileConfig) error {
+	return c.do(ctx, http.MethodPut, "/profiles/"+url.PathEscape(name), nil, profile, nil)
+}
+
+// DeleteProfile removes a profile definition; the instance is left alone
+func (c *Client) DeleteProfile(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/profiles/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// ListDockerContexts returns the colima Docker contexts, flagging orphaned ones
+func (c *Client) ListDockerContexts(ctx context.Context) ([]api.DockerContext, error) {
+	var contexts []api.DockerContext
+	if err := c.do(ctx, http.MethodGet, "/docker/contexts", nil, nil, &contexts); err != nil {
+		return nil, err
+	}
+	return contexts, nil
+}
+
+// SyncDockerContext points the context of a running profile at its socket
+// and makes it current when use is set
+func (c *Client) SyncDockerContext(ctx context.Context, profile string, use bool) (*api.DockerContext, error) {
+	body := struct {
+		Profile string `json:"profile"`
+		Use     bool   `json:"use"`
+	}{profile, use}
+	var synced api.DockerContext
+	if err := c.do(ctx, http.MethodPost, "/docker/contexts", nil, body, &synced); err != nil {
+		return nil, err
+	}
+	return &synced, nil
+}
+
+// RemoveDockerContext removes the context of a profile
+func (c *Client) RemoveDockerContext(ctx context.Context, profile string) error {
+	return c.do(ctx, http.MethodDelete, "/docker/contexts", url.Values{"profile": {profile}}, nil, nil)
+}
+
+// PruneDockerContexts removes the orphaned contexts and returns them
+func (c *Client) PruneDockerContexts(ctx context.Context) ([]api.DockerContext, error) {
+	var removed []api.DockerContext
+	if err := c.do(ctx, http.MethodDelete, "/docker/contexts", url.Values{"orphaned": {"true"}}, nil, &removed); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// MergeKubeConfig merges the kubeconfig of a profile into the daemon's
+// shared kubeconfig file as colima-<profile>
+func (c *Client) MergeKubeConfig(ctx context.Context, profile string) (*api.KubeConfigMerge, error) {
+	var merge api.KubeConfigMerge
+	if err := c.do(ctx, http.MethodPost, "/kubeconfig/merge", profileQuery(profile), nil, &merge); err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+// PruneKubeConfig removes colima-<profile> from the shared kubeconfig file
+func (c *Client) PruneKubeConfig(ctx context.Context, profile string) (*api.KubeConfigMerge, error) {
+	var merge api.KubeConfigMerge
+	if err := c.do(ctx, http.MethodDelete, "/kubeconfig/merge", profileQuery(profile), nil, &merge); err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+// KubernetesHealth reports whether the Kubernetes cluster of a running
+// profile is ready
+func (c *Client) KubernetesHealth(ctx context.Context, profile string) (*api.KubernetesHealth, error) {
+	var health api.KubernetesHealth
+	path := "/profiles/" + url.PathEscape(profile) + "/kubernetes/health"
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// SubmitStart queues a start job without waiting for it
+func (c *Client) SubmitStart(ctx context.Context, config api.ColimaConfig) (*api.Job, error) {
+	return c.submit(ctx, "/start", nil, config)
+}
+
+// SubmitStop queues a stop job without waiting for it
+func (c *Client) SubmitStop(ctx context.Context, profile string) (*api.Job, error) {
+	return c.submit(ctx, "/stop", profileQuery(profile), nil)
+}
+
+// SubmitClean queues a clean job without waiting for it
+func (c *Client) SubmitClean(ctx context.Context, req api.CleanRequest) (*api.Job, error) {
+	return c.submit(ctx, "/clean", nil, req)
+}
+
+func (c *Client) GetJob(ctx context.Context, id string) (*api.Job, error) {
+	var job api.Job
+	if err := c.do(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), nil, nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (c *Client) ListJobs(ctx context.Context) ([]*api.Job, error) {
+	var jobs []*api.Job
+	if err := c.do(ctx, http.MethodGet, "/jobs", nil, nil, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// AuditLog returns the daemon's audit records matching filter, oldest first
+func (c *Client) AuditLog(ctx context.Context, filter api.AuditFilter) ([]api.AuditRecord, error) {
+	query := url.Values{}
+	if filter.Profile != "" {
+		query.Set("profile", filter.Profile)
+	}
+	if filter.Operation != "" {
+		query.Set("op", filter.Operation)
+	}
+	if !filter.Since.IsZero() {
+		query.Set("since", filter.Since.Format(time.RFC3339))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	var records []api.AuditRecord
+	if err := c.do(ctx, http.MethodGet, "/audit", query, nil, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// WaitJob polls the job until it finishes or ctx is done
+func (c *Client) WaitJob(ctx context.Context, id string) (*api.Job, error) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.GetJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.State.Finished() {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown asks the daemon to drain its jobs and exit, applying policy to
+// running profiles, or the configured policy when empty. It returns the
+// policy the daemon applies.
+func (c *Client) Shutdown(ctx context.Context, policy api.ShutdownPolicy) (api.ShutdownPolicy, error) {
+	var resp struct {
+		Policy api.ShutdownPolicy `json:"policy"`
+	}
+	body := map[string]api.ShutdownPolicy{"policy": policy}
+	if err := c.do(ctx, http.MethodPost, "/admin/shutdown", nil, body, &resp); err != nil {
+		return "", err
+	}
+	return resp.Policy, nil
+}
+
+// waitSucceeded waits for the job and returns its failure as a domain error
+func (c *Client) waitSucceeded(ctx context.Context, id string) error {
+	job, err := c.WaitJob(ctx, id)
+	if err != nil {
+		return err
+	}
+	if job.State != api.JobSucceeded {
+		return jobError(job)
+	}
+	return nil
+}
+
+func (c *Client) submit(ctx context.Context, path string, query url.Values, body interface{}) (*api.Job, error) {
+	var job api.Job
+	if err := c.do(ctx, http.MethodPost, path, query, body, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// do sends the request, retrying while the profile is busy, and decodes the
+// response into out. A *bytes.Buffer out receives the raw body.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	return c.doWithin(ctx, c.requestTimeout, method, path, query, body, out)
+}
+
+// doWithin sends a request, bounding each attempt to timeout unless it is zero
+func (c *Client) doWithin(ctx context.Context, timeout time.Duration, method, path string, query url.Values,
+	body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		err := c.once(attemptCtx, method, path, query, payload, out)
+		cancel()
+		var busy *api.ProfileBusyError
+		if !errors.As(err, &busy) || attempt >= c.busyRetries {
+			return err
+		}
+
+		timer := time.NewTimer(c.busyDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) once(ctx context.Context, method, path string, query url.Values, payload []byte, out interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(resp)
+	}
+
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		_, err = out.ReadFrom(resp.Body)
+	default:
+		err = json.NewDecoder(resp.Body).Decode(out)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func profileQuery(profile string) url.Values {
+	if profile == "" {
+		return nil
+	}
+	return url.Values{"profile": {profile}}
+}
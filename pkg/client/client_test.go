@@ -0,0 +1,407 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Client can be used wherever the use case is expected
+var (
+	_ usecase.ColimaUseCaseInterface = (*Client)(nil)
+	_ usecase.ProfileInterface       = (*Client)(nil)
+	_ usecase.DockerContextInterface = (*Client)(nil)
+	_ usecase.KubeConfigInterface    = (*Client)(nil)
+	_ usecase.KubernetesInterface    = (*Client)(nil)
+)
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestNewAddresses(t *testing.T) {
+	for address, want := range map[string]string{
+		"http://localhost:8080/":   "http://localhost:8080",
+		"https://manager.internal": "https://manager.internal",
+		"tcp://127.0.0.1:8080":     "http://127.0.0.1:8080",
+		"localhost:8080":           "http://localhost:8080",
+		"unix:///tmp/manager.sock": "http://unix",
+	} {
+		c, err := New(address)
+		require.NoError(t, err, address)
+		assert.Equal(t, want, c.baseURL, address)
+	}
+
+	for _, address := range []string{"unix://", "localhost"} {
+		_, err := New(address)
+		assert.Error(t, err, address)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			writeJSON(w, http.StatusNotFound, domain.NewErrorDetail(&domain.ProfileNotFoundError{Profile: r.URL.Query().Get("profile")}))
+		case "/kubeconfig":
+			writeJSON(w, http.StatusServiceUnavailable, domain.NewErrorDetail(&domain.ProfileUnreachableError{Profile: "work", Reason: "ssh failed"}))
+		default:
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing bearer token", "code": "unauthorized"})
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+
+	_, err = c.Status(context.Background(), "work")
+	var notFound *ProfileNotFoundError
+	require.True(t, errors.As(err, &notFound), "got %v", err)
+	assert.Equal(t, "work", notFound.Profile)
+
+	_, err = c.GetKubeConfig(context.Background(), "work")
+	var unreachable *ProfileUnreachableError
+	require.True(t, errors.As(err, &unreachable), "got %v", err)
+	assert.Equal(t, "ssh failed", unreachable.Reason)
+
+	_, err = c.ListProfiles(context.Background())
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr), "got %v", err)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	assert.Equal(t, "unauthorized", httpErr.Code)
+}
+
+func TestBusyRetry(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			writeJSON(w, http.StatusServiceUnavailable, domain.NewErrorDetail(&domain.ProfileBusyError{Profile: "default"}))
+			return
+		}
+		writeJSON(w, http.StatusAccepted, domain.Job{ID: "abc", Operation: "stop", State: domain.JobQueued})
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithBusyRetry(2, time.Millisecond))
+	require.NoError(t, err)
+	job, err := c.SubmitStop(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", job.ID)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	atomic.StoreInt32(&attempts, 0)
+	c, err = New(srv.URL, WithBusyRetry(0, time.Millisecond))
+	require.NoError(t, err)
+	_, err = c.SubmitStop(context.Background(), "")
+	var busy *ProfileBusyError
+	assert.True(t, errors.As(err, &busy), "got %v", err)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		if r.URL.Path == "/dependencies" {
+			writeJSON(w, http.StatusOK, domain.DependencyStatus{})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithRequestTimeout(20*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.CheckDependencies(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, c.UpdateDependencies(context.Background()), "dependency updates must outlast the request timeout")
+}
+
+func TestStartWaitsForJob(t *testing.T) {
+	var polls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		var config domain.ColimaConfig
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&config))
+		assert.Equal(t, "work", config.Profile)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		writeJSON(w, http.StatusAccepted, domain.Job{ID: "job1", State: domain.JobQueued})
+	})
+	mux.HandleFunc("/jobs/job1", func(w http.ResponseWriter, r *http.Request) {
+		job := domain.Job{ID: "job1", State: domain.JobRunning}
+		if atomic.AddInt32(&polls, 1) > 1 {
+			job.State = domain.JobFailed
+			job.Error = "operation 'start' timed out"
+			job.ErrorDetail = domain.NewErrorDetail(&domain.OperationTimeoutError{Operation: "start"})
+		}
+		writeJSON(w, http.StatusOK, job)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithToken("secret"), WithPollInterval(time.Millisecond))
+	require.NoError(t, err)
+
+	err = c.Start(context.Background(), domain.ColimaConfig{Profile: "work"})
+	var timeout *OperationTimeoutError
+	require.True(t, errors.As(err, &timeout), "got %v", err)
+	assert.Equal(t, "start", timeout.Operation)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&polls))
+}
+
+func TestUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "manager.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, domain.ColimaStatus{Status: domain.ProfileRunning, Profile: "default"})
+	}))
+	srv.Listener = listener
+	srv.Start()
+	defer srv.Close()
+
+	c, err := New("unix://" + socket)
+	require.NoError(t, err)
+	status, err := c.Status(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProfileRunning, status.Status)
+}
+
+func TestPing(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	assert.NoError(t, c.Ping(context.Background()))
+
+	srv.Close()
+	assert.Error(t, c.Ping(context.Background()))
+}
+
+func TestShutdown(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["policy"] == "" {
+			writeJSON(w, http.StatusConflict, domain.NewErrorDetail(&domain.ShuttingDownError{}))
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"state": "shutting_down", "policy": req["policy"]})
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+
+	policy, err := c.Shutdown(context.Background(), domain.ShutdownStopManaged)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ShutdownStopManaged, policy)
+
+	_, err = c.Shutdown(context.Background(), "")
+	var shuttingDown *ShuttingDownError
+	assert.True(t, errors.As(err, &shuttingDown), "got %v", err)
+}
+
+func TestPlanClean(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req domain.CleanRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Query().Get("dry_run") != "true" {
+			writeJSON(w, http.StatusPreconditionRequired,
+				domain.NewErrorDetail(&domain.CleanNotConfirmedError{Reason: "a token from a dry run is required"}))
+			return
+		}
+		writeJSON(w, http.StatusOK, domain.CleanPlan{Profiles: []string{req.Profile}, TotalSize: 1024, Token: "abc"})
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+
+	plan, err := c.PlanClean(context.Background(), domain.CleanRequest{Profile: "work"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work"}, plan.Profiles)
+	assert.Equal(t, "abc", plan.Token)
+
+	_, err = c.SubmitClean(context.Background(), domain.CleanRequest{Profile: "work"})
+	var unconfirmed *CleanNotConfirmedError
+	assert.True(t, errors.As(err, &unconfirmed), "got %v", err)
+}
+
+func TestDockerContexts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /docker/contexts":
+			writeJSON(w, http.StatusOK, []domain.DockerContext{{Name: "colima-gone", Profile: "gone", Orphaned: true}})
+		case "POST /docker/contexts":
+			var req struct {
+				Profile string `json:"profile"`
+				Use     bool   `json:"use"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			writeJSON(w, http.StatusOK, domain.DockerContext{Name: "colima-" + req.Profile, Profile: req.Profile, Current: req.Use})
+		case "DELETE /docker/contexts":
+			if r.URL.Query().Get("orphaned") == "true" {
+				writeJSON(w, http.StatusOK, []domain.DockerContext{{Name: "colima-gone", Profile: "gone", Orphaned: true}})
+				return
+			}
+			assert.Equal(t, "work", r.URL.Query().Get("profile"))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	contexts, err := c.ListDockerContexts(ctx)
+	require.NoError(t, err)
+	require.Len(t, contexts, 1)
+	assert.True(t, contexts[0].Orphaned)
+
+	synced, err := c.SyncDockerContext(ctx, "work", true)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DockerContext{Name: "colima-work", Profile: "work", Current: true}, *synced)
+
+	require.NoError(t, c.RemoveDockerContext(ctx, "work"))
+
+	removed, err := c.PruneDockerContexts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, contexts, removed)
+}
+
+func TestKubeConfigMerge(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/kubeconfig/merge", r.URL.Path)
+		profile := r.URL.Query().Get("profile")
+		if profile == "locked" {
+			writeJSON(w, http.StatusConflict, domain.NewErrorDetail(&domain.KubeConfigError{
+				Path: "/home/dev/.kube/config", Reason: "locked by another process"}))
+			return
+		}
+		writeJSON(w, http.StatusOK, domain.KubeConfigMerge{Profile: profile, Path: "/home/dev/.kube/config",
+			Context: domain.KubeContextName(profile), Changed: r.Method == http.MethodPost})
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	merge, err := c.MergeKubeConfig(ctx, "work")
+	require.NoError(t, err)
+	assert.Equal(t, "colima-work", merge.Context)
+	assert.True(t, merge.Changed)
+
+	pruned, err := c.PruneKubeConfig(ctx, "work")
+	require.NoError(t, err)
+	assert.False(t, pruned.Changed)
+
+	_, err = c.MergeKubeConfig(ctx, "locked")
+	var kubeErr *KubeConfigError
+	require.True(t, errors.As(err, &kubeErr), "got %v", err)
+	assert.Equal(t, "/home/dev/.kube/config", kubeErr.Path)
+}
+
+func TestKubernetesHealth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/profiles/k8s/kubernetes/health", r.URL.Path)
+		writeJSON(w, http.StatusOK, domain.KubernetesHealth{Profile: "k8s", Reason: "nodes not ready: colima",
+			APIServer: domain.KubernetesAPICheck{Ready: true, StatusCode: http.StatusOK, Detail: "ok"},
+			Nodes:     []domain.KubernetesNode{{Name: "colima", Reason: "KubeletNotReady"}}})
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+
+	health, err := c.KubernetesHealth(context.Background(), "k8s")
+	require.NoError(t, err)
+	assert.False(t, health.Ready)
+	assert.True(t, health.APIServer.Ready)
+	require.Len(t, health.Nodes, 1)
+	assert.Equal(t, "KubeletNotReady", health.Nodes[0].Reason)
+}
+
+func TestAuditLog(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/audit", r.URL.Path)
+		assert.Equal(t, "web", r.URL.Query().Get("profile"))
+		assert.Equal(t, "start", r.URL.Query().Get("op"))
+		assert.Equal(t, "2024-05-01T12:00:00Z", r.URL.Query().Get("since"))
+		writeJSON(w, http.StatusOK, []domain.AuditRecord{{Operation: "start", Profile: "web", Outcome: "success"}})
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+
+	records, err := c.AuditLog(context.Background(), domain.AuditFilter{Profile: "web", Operation: "start", Since: since})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "success", records[0].Outcome)
+}
+
+func TestProfiles(t *testing.T) {
+	var created map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /profiles":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			writeJSON(w, http.StatusCreated, created)
+		case "GET /profiles/work/config":
+			writeJSON(w, http.StatusOK, config.ProfileConfig{CPUs: 4, Kubernetes: domain.Bool(true)})
+		case "GET /profiles/work/effective":
+			writeJSON(w, http.StatusOK, domain.EffectiveConfig{
+				Profile:  "work",
+				Declared: true,
+				Config:   domain.ColimaConfig{Profile: "work", CPUs: 4},
+				Sources:  map[string]domain.ConfigSource{"cpus": domain.SourceProfile},
+			})
+		case "DELETE /profiles/gone":
+			writeJSON(w, http.StatusNotFound, domain.NewErrorDetail(&domain.ProfileNotFoundError{Profile: "gone"}))
+		default:
+			writeJSON(w, http.StatusConflict, domain.NewErrorDetail(&domain.ProfileExistsError{Profile: "work"}))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, c.CreateProfile(ctx, "dev", config.ProfileConfig{CPUs: 2}))
+	assert.Equal(t, "dev", created["name"])
+	assert.Equal(t, float64(2), created["cpus"])
+
+	profile, err := c.GetProfile(ctx, "work")
+	require.NoError(t, err)
+	assert.Equal(t, config.ProfileConfig{CPUs: 4, Kubernetes: domain.Bool(true)}, profile)
+
+	effective, err := c.EffectiveProfile(ctx, "work")
+	require.NoError(t, err)
+	assert.Equal(t, 4, effective.Config.CPUs)
+	assert.Equal(t, domain.SourceProfile, effective.Sources["cpus"])
+
+	var notFound *ProfileNotFoundError
+	assert.True(t, errors.As(c.DeleteProfile(ctx, "gone"), &notFound))
+
+	var exists *ProfileExistsError
+	assert.True(t, errors.As(c.UpdateProfile(ctx, "work", config.ProfileConfig{}), &exists))
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gqadonis/colima-manager/pkg/api"
+)
+
+// Error types returned by Client, re-exported from package api
+type (
+	ProfileNotFoundError    = api.ProfileNotFoundError
+	ProfileNotStartedError  = api.ProfileNotStartedError
+	ProfileUnreachableError = api.ProfileUnreachableError
+	ProfileMalfunctionError = api.ProfileMalfunctionError
+	ProfileBusyError        = api.ProfileBusyError
+	DependencyError         = api.DependencyError
+	DockerContextError      = api.DockerContextError
+	OperationTimeoutError   = api.OperationTimeoutError
+	OperationCanceledError  = api.OperationCanceledError
+	JobNotFoundError        = api.JobNotFoundError
+	ShuttingDownError       = api.ShuttingDownError
+	ProfileExistsError      = api.ProfileExistsError
+	ValidationError         = api.ValidationError
+	FieldError              = api.FieldError
+	CleanNotConfirmedError  = api.CleanNotConfirmedError
+	KubeConfigError         = api.KubeConfigError
+)
+
+// HTTPError is returned for error responses that carry no domain error code,
+// such as authentication failures
+type HTTPError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// responseError converts an error response into a domain error when the body
+// carries a known code, and into an *HTTPError otherwise
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var detail api.ErrorDetail
+	if err := json.Unmarshal(data, &detail); err != nil {
+		return &HTTPError{StatusCode: resp.StatusCode, Message: string(data)}
+	}
+	if err := detail.Err(); err != nil {
+		return err
+	}
+	return &HTTPError{StatusCode: resp.StatusCode, Code: detail.Code, Message: detail.Error}
+}
+
+// jobError returns the failure of a finished job
+func jobError(job *api.Job) error {
+	if job.ErrorDetail != nil {
+		if err := job.ErrorDetail.Err(); err != nil {
+			return err
+		}
+	}
+	if job.Error != "" {
+		return errors.New(job.Error)
+	}
+	return fmt.Errorf("job %s failed", job.ID)
+}
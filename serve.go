@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gqadonis/colima-manager/internal/app"
+	"github.com/gqadonis/colima-manager/internal/config"
+	"github.com/gqadonis/colima-manager/internal/domain"
+	"github.com/gqadonis/colima-manager/internal/infrastructure/kubernetes"
+	"github.com/gqadonis/colima-manager/internal/interface/http/handler"
+	"github.com/gqadonis/colima-manager/internal/interface/http/middleware"
+	"github.com/gqadonis/colima-manager/internal/interface/http/server"
+	"github.com/gqadonis/colima-manager/internal/pkg/daemon"
+	"github.com/gqadonis/colima-manager/internal/pkg/events"
+	"github.com/gqadonis/colima-manager/internal/pkg/logger"
+	"github.com/gqadonis/colima-manager/internal/pkg/metrics"
+	"github.com/gqadonis/colima-manager/internal/usecase"
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	// detachTimeout bounds how long we wait for a detached daemon to lock its PID file
+	detachTimeout = 10 * time.Second
+	// serverShutdownTimeout bounds how long open connections delay exit
+	serverShutdownTimeout = 10 * time.Second
+	// kubernetesReadyTimeout bounds how long auto-start waits for the
+	// default profile's Kubernetes to become ready
+	kubernetesReadyTimeout = 5 * time.Minute
+)
+
+// serve runs the HTTP daemon until it receives SIGINT or SIGTERM
+func serve(cfg *config.Config) {
+	log := logger.GetLogger()
+
+	// In daemon mode, re-execute ourselves in a new session and let the
+	// child do the work
+	if cfg.Server.Daemon && !daemon.IsDetached() {
+		if pid, err := daemon.Running(cfg.Server.PIDFile); err == nil {
+			log.Fatal("colima-manager is already running (PID %d)", pid)
+		}
+		pid, err := daemon.Detach(os.Args[1:], cfg.Server.PIDFile, detachTimeout)
+		if err != nil {
+			log.Fatal("Failed to start daemon: %v", err)
+		}
+		log.Info("Started in daemon mode with PID %d", pid)
+		return
+	}
+
+	log.Info("Starting Colima Manager")
+
+	// Lock the PID file before doing anything else so two daemons never run
+	pidFile, err := daemon.Acquire(cfg.Server.PIDFile)
+	if err != nil {
+		log.Fatal("Failed to acquire PID file: %v", err)
+	}
+	if stale := pidFile.StalePID(); stale != 0 {
+		log.Info("Replaced stale PID file left by process %d", stale)
+	}
+	log.Info("PID file locked at: %s", pidFile.Path())
+
+	// Initialize repository and use cases
+	log.Info("Initializing Colima use case...")
+	eventBus := events.NewBus()
+	registry := metrics.NewRegistry()
+	useCases, err := app.NewUseCases(cfg, eventBus, registry)
+	if err != nil {
+		log.Fatal("Failed to initialize use case: %v", err)
+	}
+	repo, useCase, auditLog := useCases.Repo, useCases.Colima, useCases.Audit
+	kubeConfigUseCase := useCases.KubeConfig
+	kubernetesUseCase := usecase.NewKubernetesUseCase(repo, kubernetes.NewChecker(cfg.Timeouts.KubernetesHealth))
+	if cfg.KubeConfig.PruneOnClean {
+		log.Info("Pruning merged kubeconfig entries of cleaned profiles from: %s", useCases.KubeConfigFile.Path())
+	}
+	if auditLog != nil {
+		log.Info("Auditing operations to: %s", useCases.AuditStore.Path())
+	}
+	log.Info("Colima use case initialized successfully")
+
+	jobRunner := usecase.NewJobRunner(useCase)
+	shutdown := usecase.NewShutdown(useCase, jobRunner, cfg)
+	shutdown.LoadInterrupted()
+
+	// If auto flag is set, start the default profile before starting the API server
+	if cfg.Server.Auto.Enabled {
+		log.Info("Auto flag detected, preparing to start default profile")
+		defaultProfile := cfg.Server.Auto.Default
+		if defaultProfile == "" {
+			defaultProfile = "default"
+			log.Info("No default profile specified, using 'default'")
+		}
+
+		// Resolve the profile the way the start will
+		effective := usecase.ResolveConfig(cfg, domain.ColimaConfig{Profile: defaultProfile})
+		if !effective.Declared {
+			log.Info("No configuration found for profile '%s', using defaults", defaultProfile)
+		}
+		log.Info("Profile configuration: %+v (sources: %v)", effective.Config, effective.Sources)
+
+		// Start the profile
+		log.Info("Starting Colima profile '%s'...", defaultProfile)
+		autoCtx := domain.WithCaller(context.Background(), domain.Caller{Name: "auto-start"})
+		if err := useCase.Start(autoCtx, domain.ColimaConfig{Profile: defaultProfile}); err != nil {
+			log.Fatal("Failed to start profile '%s': %v", defaultProfile, err)
+		}
+
+		// Wait for profile to be fully ready
+		log.Info("Waiting for profile '%s' to be fully ready...", defaultProfile)
+		for {
+			status, err := useCase.Status(context.Background(), defaultProfile)
+			if err != nil {
+				log.Error("Error checking profile status: %v", err)
+				time.Sleep(2 * time.Second)
+				continue
+			}
+			if status.Status == domain.ProfileRunning {
+				log.Info("Profile '%s' is now running with: CPUs=%d, Memory=%d, DiskSize=%d, Kubernetes=%v",
+					defaultProfile, status.CPUs, status.Memory, status.DiskSize, status.Kubernetes)
+				break
+			}
+			log.Info("Profile '%s' status: %s, waiting...", defaultProfile, status.Status)
+			time.Sleep(2 * time.Second)
+		}
+
+		// If Kubernetes is enabled, wait until its API server and nodes are
+		// ready; a readable kubeconfig alone does not mean they are
+		if domain.Enabled(effective.Config.Kubernetes) {
+			log.Info("Waiting for Kubernetes of profile '%s' to be ready...", defaultProfile)
+			waitCtx, cancel := context.WithTimeout(context.Background(), kubernetesReadyTimeout)
+			health, err := usecase.WaitKubernetesReady(waitCtx, kubernetesUseCase, defaultProfile, 2*time.Second)
+			cancel()
+			if err != nil {
+				if health != nil {
+					log.Fatal("Kubernetes of profile '%s' is not ready: %v (%s)", defaultProfile, err, health.Reason)
+				}
+				log.Fatal("Kubernetes of profile '%s' is not ready: %v", defaultProfile, err)
+			}
+			log.Info("Kubernetes is ready - Server: %s, Nodes: %d", health.Server, len(health.Nodes))
+		}
+
+		log.Info("Profile '%s' is fully ready", defaultProfile)
+	}
+
+	// Initialize Echo instance
+	log.Info("Initializing HTTP server...")
+	e := echo.New()
+	// No proxy is trusted, so request logs show the peer rather than headers
+	// a client chose
+	e.IPExtractor = echo.ExtractIPDirect()
+
+	// Middleware
+	e.Use(echoMiddleware.Logger())
+	e.Use(echoMiddleware.Recover())
+	e.Use(middleware.RequestID())
+	e.Use(middleware.RequestLogger(log))
+	e.Use(middleware.RejectWhileShuttingDown(shutdown.ShuttingDown))
+
+	// Initialize reconciler and handlers
+	colimaHandler := handler.NewColimaHandler(useCase, jobRunner)
+
+	reconciler := usecase.NewReconciler(useCase, cfg)
+	reconcileHandler := handler.NewReconcileHandler(reconciler)
+	adminHandler := handler.NewAdminHandler(shutdown)
+	eventHandler := handler.NewEventHandler(eventBus)
+	profileHandler := handler.NewProfileHandler(usecase.NewProfileUseCase(cfg))
+	contextHandler := handler.NewDockerContextHandler(usecase.NewDockerContextUseCase(repo))
+	kubeConfigHandler := handler.NewKubeConfigHandler(kubeConfigUseCase)
+	kubernetesHandler := handler.NewKubernetesHandler(kubernetesUseCase)
+	if cfg.Reconcile.Enabled {
+		go reconciler.Run(shutdown.Context())
+	}
+
+	// Authentication
+	auth, err := middleware.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		log.Fatal("Failed to initialize authentication: %v", err)
+	}
+	listen := []string(cfg.Server.Listen)
+	if len(listen) == 0 {
+		listen = []string{fmt.Sprintf("tcp://%s:%d", cfg.Server.Host, cfg.Server.Port)}
+	}
+	if !auth.Enabled() {
+		for _, raw := range listen {
+			if addr, err := server.ParseAddress(raw); err == nil && !addr.Loopback() {
+				log.Error("Authentication is disabled while listening on %s; anyone who can reach the server can clean profiles", addr)
+			}
+		}
+	}
+	read := auth.Require(middleware.ScopeRead)
+	operate := auth.Require(middleware.ScopeOperate)
+	destroy := auth.Require(middleware.ScopeDestroy)
+
+	// Routes
+	e.GET("/dependencies", colimaHandler.CheckDependencies, read)
+	e.POST("/dependencies/update", colimaHandler.UpdateDependencies, destroy)
+	e.GET("/status", colimaHandler.Status, read)
+	e.GET("/profiles", colimaHandler.ListProfiles, read)
+	e.POST("/profiles", profileHandler.Create, operate)
+	e.GET("/profiles/:name/config", profileHandler.Get, read)
+	e.GET("/profiles/:name/effective", profileHandler.Effective, read)
+	e.GET("/profiles/:name/kubernetes/health", kubernetesHandler.Health, read)
+	e.PUT("/profiles/:name", profileHandler.Update, operate)
+	e.DELETE("/profiles/:name", profileHandler.Delete, destroy)
+	e.POST("/start", colimaHandler.Start, operate)
+	e.POST("/stop", colimaHandler.Stop, operate)
+	e.GET("/kubeconfig", colimaHandler.GetKubeConfig, operate)
+	e.POST("/kubeconfig/merge", kubeConfigHandler.Merge, operate)
+	e.DELETE("/kubeconfig/merge", kubeConfigHandler.Prune, operate)
+	e.POST("/clean", colimaHandler.Clean, destroy)
+	e.GET("/docker/contexts", contextHandler.List, read)
+	e.POST("/docker/contexts", contextHandler.Sync, operate)
+	e.DELETE("/docker/contexts", contextHandler.Delete, destroy)
+	e.GET("/jobs", colimaHandler.ListJobs, read)
+	e.GET("/jobs/:id", colimaHandler.GetJob, read)
+	e.GET("/reconcile/status", reconcileHandler.Status, read)
+	e.GET("/events", eventHandler.Stream, read)
+	e.POST("/admin/shutdown", adminHandler.Shutdown, destroy)
+	e.GET("/admin/interrupted", adminHandler.Interrupted, read)
+	e.GET("/metrics", echo.WrapHandler(registry.Handler()), read)
+	if auditLog != nil {
+		e.GET("/audit", handler.NewAuditHandler(auditLog).List, read)
+	}
+
+	// Start server
+	socketMode, err := server.ParseSocketMode(cfg.Server.SocketMode)
+	if err != nil {
+		log.Fatal("Invalid server configuration: %v", err)
+	}
+	srv, err := server.New(e, server.Config{
+		Listen:      listen,
+		SocketMode:  socketMode,
+		SocketGroup: cfg.Server.SocketGroup,
+	})
+	if err != nil {
+		log.Fatal("Failed to start HTTP server: %v", err)
+	}
+	go func() {
+		if err := srv.Serve(); err != nil {
+			log.Error("HTTP server error: %v", err)
+		}
+		log.Info("Shutting down the server")
+	}()
+
+	// Wait for an interrupt signal or POST /admin/shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-quit:
+		log.Info("Received %s", sig)
+	case <-shutdown.Requested():
+	}
+
+	// Cleanup; the API stays up while operations drain so clients can
+	// follow them, but mutating requests are rejected from here on
+	log.Info("Cleaning up...")
+	reconciler.Stop()
+	shutdown.Run(context.Background())
+
+	// Shutdown; end the event streams first, since Shutdown waits for them
+	eventBus.Close()
+	log.Info("Shutting down HTTP server...")
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error("Error during server shutdown: %v", err)
+	}
+	log.Info("Server shutdown complete")
+
+	if err := pidFile.Release(); err != nil {
+		log.Error("Failed to release PID file: %v", err)
+	}
+	log.Info("PID file released")
+}